@@ -1,24 +1,90 @@
 #!/bin/bash
 
+# OPENAI_API_KEY and BACKEND_HEADER are read by konterfai from the environment, so the secrets do not show up in the
+# arguments of the process.
 /usr/local/bin/konterfai \
     --address="${ADDRESS:-0.0.0.0}" \
     --port="${PORT:-8080}" \
+    --proxy-upstream="${PROXY_UPSTREAM}" \
+    --bot-user-agents-file="${BOT_USER_AGENTS_FILE}" \
+    --forward-auth-path="${FORWARD_AUTH_PATH}" \
+    --trusted-proxies="${TRUSTED_PROXIES}" \
+    --classifier-threshold="${CLASSIFIER_THRESHOLD:-0.5}" \
+    --classifier-networks-file="${CLASSIFIER_NETWORKS_FILE}" \
+    --classifier-honeypot-file="${CLASSIFIER_HONEYPOT_FILE}" \
+    --classifier-rate-limit="${CLASSIFIER_RATE_LIMIT:-600}" \
+    --policy-file="${POLICY_FILE}" \
+    --policy-reload-interval="${POLICY_RELOAD_INTERVAL:-5s}" \
     --hallucinator-url=${HALLUCINATOR_URL:-"https://localhost:8080"} \
     --statistics-port=${STATISTICS_PORT:-8081} \
     --generate-interval="${GENERATE_INTERVAL:-2s}" \
+    --generation-workers="${GENERATION_WORKERS:-1}" \
     --hallucination-cache-size="${HALLUCINATION_CACHE_SIZE:-10}" \
+    --hallucination-cache-dir="${HALLUCINATION_CACHE_DIR}" \
+    --world-file="${WORLD_FILE}" \
+    --world-seed="${WORLD_SEED:-0}" \
+    --world-size="${WORLD_SIZE:-25}" \
     --hallucination-prompt-word-count="${HALLUCINATION_PROMPT_WORD_COUNT:-5}" \
+    --dictionary-dir="${DICTIONARY_DIR}" \
     --hallucination-word-count="${HALLUCINATION_WORD_COUNT:-500}" \
     --hallucination-request-count="${HALLUCINATION_REQUEST_COUNT:-5}" \
+    --remix-probability="${REMIX_PROBABILITY:-0}" \
+    --remix-splice-probability="${REMIX_SPLICE_PROBABILITY:-0.2}" \
+    --remix-synonym-probability="${REMIX_SYNONYM_PROBABILITY:-0.3}" \
+    --mutation-seed="${MUTATION_SEED:-0}" \
+    --mutation-year-offset="${MUTATION_YEAR_OFFSET:-0}" \
+    --mutation-file="${MUTATION_FILE}" \
+    --hallucination-minimal-length="${HALLUCINATION_MINIMAL_LENGTH:-500}" \
+    --refusal-patterns-file="${REFUSAL_PATTERNS_FILE}" \
+    --denylist-file="${DENYLIST_FILE}" \
+    --repetition-min-unique-ratio="${REPETITION_MIN_UNIQUE_RATIO:-0.3}" \
+    --duplicate-threshold="${DUPLICATE_THRESHOLD:-0.8}" \
+    --duplicate-history="${DUPLICATE_HISTORY:-100}" \
+    --validate-language="${VALIDATE_LANGUAGE:-false}" \
     --hallucinator-link-percentage="${HALLUCINATOR_LINK_PERCENTAGE:-10}" \
     --hallucinator-link-max-subdirectory-depth="${HALLUCINATOR_LINK_MAX_SUBDIRECTORY_DEPTH:-5}" \
     --hallucinator-link-has-variables-probability="${HALLUCINATOR_LINK_HAS_VARIABLES_PROBABILITY:-0.5}" \
     --hallucinator-link-max-variables="${HALLUCINATOR_LINK_MAX_VARIABLES:-5}" \
+    --backend=${BACKEND:-ollama} \
     --ollama-address=${OLLAMA_ADDRESS:-"http://localhost:11434"} \
     --ollama-model=${OLLAMA_MODEL} \
+    --ollama-pull-model=${OLLAMA_PULL_MODEL:-false} \
+    --ollama-keep-alive=${OLLAMA_KEEP_ALIVE} \
+    --ollama-balancing=${OLLAMA_BALANCING:-least-outstanding} \
+    --ollama-eject-after=${OLLAMA_EJECT_AFTER:-3} \
+    --ollama-health-interval=${OLLAMA_HEALTH_INTERVAL:-10s} \
+    --openai-address=${OPENAI_ADDRESS:-"http://localhost:8000"} \
+    --openai-model=${OPENAI_MODEL} \
+    --openai-stream=${OPENAI_STREAM:-false} \
+    --backend-failure-threshold=${BACKEND_FAILURE_THRESHOLD:-5} \
+    --backend-open-timeout=${BACKEND_OPEN_TIMEOUT:-30s} \
+    --backend-max-backoff=${BACKEND_MAX_BACKOFF:-5m} \
+    --markov-corpus-dir=${MARKOV_CORPUS_DIR} \
+    --markov-order=${MARKOV_ORDER:-2} \
+    --markov-use-dictionaries=${MARKOV_USE_DICTIONARIES:-true} \
     --ollama-request-timeout=${OLLAMA_REQUEST_TIMEOUT:-60s} \
+    --backend-ca-file=${BACKEND_CA_FILE} \
+    --backend-client-cert=${BACKEND_CLIENT_CERT} \
+    --backend-client-key=${BACKEND_CLIENT_KEY} \
+    --backend-proxy=${BACKEND_PROXY} \
     --ai-temperature=${AI_TEMPERATURE:-30.0} \
     --ai-seed=${AI_SEED:-0} \
+    --ai-temperature-range=${AI_TEMPERATURE_RANGE} \
+    --ai-top-p=${AI_TOP_P} \
+    --ai-top-k=${AI_TOP_K} \
+    --ai-min-p=${AI_MIN_P} \
+    --ai-repeat-penalty=${AI_REPEAT_PENALTY} \
+    --ai-num-predict=${AI_NUM_PREDICT} \
+    --ai-num-ctx=${AI_NUM_CTX} \
+    ${AI_STOP:+--ai-stop="${AI_STOP}"} \
+    --ai-system-prompt="${AI_SYSTEM_PROMPT}" \
     --webserver-200-probability=${WEBSERVER_200_PROBABILITY:-0.95} \
     --webserver-error-cache-size=${WEBSERVER_ERROR_CACHE_SIZE:-1000} \
+    --stream-on-empty-cache=${STREAM_ON_EMPTY_CACHE:-false} \
+    --stream-max-concurrent=${STREAM_MAX_CONCURRENT:-2} \
+    --tarpit-bytes-per-second=${TARPIT_BYTES_PER_SECOND:-0} \
+    --tarpit-chunk-size=${TARPIT_CHUNK_SIZE:-64} \
+    --tarpit-jitter=${TARPIT_JITTER:-0.5} \
+    --tarpit-max-connections=${TARPIT_MAX_CONNECTIONS:-100} \
+    --tarpit-max-connections-per-client=${TARPIT_MAX_CONNECTIONS_PER_CLIENT:-4} \
     --random-uncertainty=${RANDOM_UNCERTAINTY:-0.1}
@@ -0,0 +1,85 @@
+package classifier
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"codeberg.org/konterfai/konterfai/pkg/dictionaries"
+)
+
+// DefaultBotUserAgents are the regular expressions of the user agents of AI crawlers, they are matched
+// case-insensitively. They are the same as in the example configuration of nginx.
+var DefaultBotUserAgents = []string{
+	"Amazonbot",
+	"Applebot-Extended",
+	"Bytespider",
+	"CCBot",
+	"ChatGPT-User",
+	"Claude-Web",
+	"ClaudeBot",
+	"FacebookBot",
+	"GPTBot",
+	"Google-Extended",
+	"ImagesiftBot",
+	"Omgili",
+	"Omgilibot",
+	"PerplexityBot",
+	"YouBot",
+	"anthropic-ai",
+}
+
+// BotMatcher classifies requests as AI crawlers by their user agent.
+// It is the user agent signal of the Classifier.
+type BotMatcher struct {
+	patterns []*regexp.Regexp
+}
+
+// NewBotMatcher creates a new BotMatcher, the patterns are compiled once and matched case-insensitively.
+func NewBotMatcher(patterns []string) (*BotMatcher, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bot user agent pattern %q (%w)", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	return &BotMatcher{patterns: compiled}, nil
+}
+
+// LoadBotUserAgents returns the default bot user agents and the patterns of the file, one regular expression per
+// line. An empty path only returns the default bot user agents.
+func LoadBotUserAgents(path string) ([]string, error) {
+	patterns := slices.Clone(DefaultBotUserAgents)
+	if path != "" {
+		lines, err := dictionaries.ReadLines(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read bot user agents (%w)", err)
+		}
+		patterns = append(patterns, lines...)
+	}
+
+	return patterns, nil
+}
+
+// Match returns the first pattern that matches the user agent, or an empty string if none matches.
+// A nil BotMatcher matches nothing.
+func (m *BotMatcher) Match(userAgent string) string {
+	if m == nil {
+		return ""
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(userAgent) {
+			return re.String()[len("(?i)"):]
+		}
+	}
+
+	return ""
+}
+
+// IsBot returns true if the user agent matches one of the bot user agents.
+func (m *BotMatcher) IsBot(userAgent string) bool {
+	return m.Match(userAgent) != ""
+}
@@ -0,0 +1,316 @@
+package classifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"codeberg.org/konterfai/konterfai/pkg/dictionaries"
+	"codeberg.org/konterfai/konterfai/pkg/helpers/robots"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var tracer = otel.Tracer("codeberg.org/konterfai/konterfai/pkg/classifier")
+
+const (
+	// DefaultThreshold is the score from which a request is classified as a crawler.
+	DefaultThreshold = 0.5
+
+	// DefaultRateLimit is the number of requests per RateWindow from which a client is too fast for a human.
+	// A browser loads the assets of every page as well, so it sends dozens of requests per page.
+	DefaultRateLimit = 600
+
+	// RateWindow is the window in which the requests of a client are counted.
+	RateWindow = time.Minute
+
+	// HoneypotMemory is how long a client that requested a honeypot path is remembered.
+	HoneypotMemory = 24 * time.Hour
+
+	// maxClients is the number of clients from which the clients without recent requests are forgotten.
+	maxClients = 10000
+)
+
+// The signals that contribute to the score of a request.
+const (
+	SignalUserAgent      = "user-agent"
+	SignalNetwork        = "network"
+	SignalHoneypot       = "honeypot"
+	SignalRobotsTxt      = "robots-txt"
+	SignalRate           = "rate"
+	SignalAcceptLanguage = "accept-language"
+	SignalAcceptEncoding = "accept-encoding"
+)
+
+// signalWeights are the contributions of the signals to the score, the score is capped at 1.
+// A strong signal alone reaches the DefaultThreshold. A violation of the robots.txt together with the rate reaches it
+// as well, a crawler that ignores the robots.txt it requested rarely keeps to the pace of a human. The missing headers
+// are common for browsers behind privacy extensions, they only tip the balance.
+var signalWeights = map[string]float64{
+	SignalUserAgent:      1,
+	SignalNetwork:        1,
+	SignalHoneypot:       1,
+	SignalRobotsTxt:      0.3,
+	SignalRate:           0.2,
+	SignalAcceptLanguage: 0.05,
+	SignalAcceptEncoding: 0.05,
+}
+
+// Request is a request to classify, either received by konterfAI or described by a reverse proxy.
+type Request struct {
+	// Client is the IP address of the client, without port.
+	Client string
+	// Path is the path of the request.
+	Path string
+	// Header are the headers of the request.
+	Header http.Header
+}
+
+// NewRequest returns the Request of a request received by konterfAI.
+func NewRequest(r *http.Request) Request {
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		client = r.RemoteAddr
+	}
+
+	return Request{Client: client, Path: r.URL.Path, Header: r.Header}
+}
+
+// Classification is the result of the classification of a request.
+type Classification struct {
+	// Score is the sum of the weights of the signals, from 0 (human) to 1 (crawler).
+	Score float64
+	// Signals are the signals that were found in the request.
+	Signals []string
+	// Crawler is true if the score reaches the threshold of the classifier.
+	Crawler bool
+}
+
+// Classifier scores requests by multiple signals of AI crawlers: known user agents, known networks, requests for
+// honeypot paths, violations of the robots.txt, the request rate and missing headers that every browser sends.
+type Classifier struct {
+	Threshold     float64
+	RateLimit     int
+	Bots          *BotMatcher
+	Networks      []netip.Prefix
+	HoneypotPaths []string
+	Statistics    *statistics.Statistics
+	requests      map[string][]time.Time
+	trapped       map[string]time.Time
+	lock          sync.Mutex
+}
+
+// NewClassifier creates a new Classifier. A rateLimit of 0 disables the rate signal.
+func NewClassifier(bots *BotMatcher, networks []netip.Prefix, honeypotPaths []string, threshold float64,
+	rateLimit int, statistics *statistics.Statistics,
+) *Classifier {
+	return &Classifier{
+		Threshold:     threshold,
+		RateLimit:     rateLimit,
+		Bots:          bots,
+		Networks:      networks,
+		HoneypotPaths: honeypotPaths,
+		Statistics:    statistics,
+		requests:      map[string][]time.Time{},
+		trapped:       map[string]time.Time{},
+	}
+}
+
+// LoadNetworks reads the networks of AI crawlers from a file, one CIDR or IP address per line.
+// An empty path returns no networks.
+func LoadNetworks(filePath string) ([]netip.Prefix, error) {
+	if filePath == "" {
+		return nil, nil
+	}
+	lines, err := dictionaries.ReadLines(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read networks (%w)", err)
+	}
+	networks := make([]netip.Prefix, 0, len(lines))
+	for _, line := range lines {
+		network, err := ParseNetwork(line)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+// ParseNetwork parses a network in CIDR notation or a single IP address.
+func ParseNetwork(network string) (netip.Prefix, error) {
+	if !strings.Contains(network, "/") {
+		addr, err := netip.ParseAddr(network)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid network %q (%w)", network, err)
+		}
+		addr = addr.Unmap()
+
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(network)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid network %q (%w)", network, err)
+	}
+
+	return prefix.Masked(), nil
+}
+
+// LoadHoneypotPaths reads the honeypot paths from a file, one glob pattern of path.Match per line.
+// An empty path returns no honeypot paths.
+func LoadHoneypotPaths(filePath string) ([]string, error) {
+	if filePath == "" {
+		return nil, nil
+	}
+	lines, err := dictionaries.ReadLines(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read honeypot paths (%w)", err)
+	}
+	for _, line := range lines {
+		if _, err := path.Match(line, ""); err != nil {
+			return nil, fmt.Errorf("invalid honeypot path %q (%w)", line, err)
+		}
+	}
+
+	return lines, nil
+}
+
+// Classify scores the request, every call counts towards the request rate of the client.
+// A nil Classifier classifies every request as human.
+func (c *Classifier) Classify(ctx context.Context, r Request) Classification {
+	ctx, span := tracer.Start(ctx, "Classifier.Classify")
+	defer span.End()
+
+	if c == nil {
+		return Classification{}
+	}
+	now := time.Now()
+	signals := []string{}
+	if c.Bots.IsBot(r.Header.Get("User-Agent")) {
+		signals = append(signals, SignalUserAgent)
+	}
+	if c.inNetworks(r.Client) {
+		signals = append(signals, SignalNetwork)
+	}
+	if c.isTrapped(r.Client, r.Path, now) {
+		signals = append(signals, SignalHoneypot)
+	}
+	if c.violatesRobotsTxt(ctx, r) {
+		signals = append(signals, SignalRobotsTxt)
+	}
+	if c.exceedsRate(r.Client, now) {
+		signals = append(signals, SignalRate)
+	}
+	if r.Header.Get("Accept-Language") == "" {
+		signals = append(signals, SignalAcceptLanguage)
+	}
+	if r.Header.Get("Accept-Encoding") == "" {
+		signals = append(signals, SignalAcceptEncoding)
+	}
+	score := 0.0
+	for _, signal := range signals {
+		score += signalWeights[signal]
+		statistics.ClassifierSignalsTotal.WithLabelValues(signal).Inc()
+	}
+	classification := Classification{Score: min(score, 1), Signals: signals, Crawler: score >= c.Threshold}
+	span.SetAttributes(
+		attribute.Float64("konterfai.score", classification.Score),
+		attribute.StringSlice("konterfai.signals", classification.Signals),
+	)
+
+	return classification
+}
+
+// inNetworks returns true if the client is in one of the networks.
+func (c *Classifier) inNetworks(client string) bool {
+	addr, err := netip.ParseAddr(client)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	return slices.ContainsFunc(c.Networks, func(network netip.Prefix) bool {
+		return network.Contains(addr)
+	})
+}
+
+// violatesRobotsTxt returns true if the client requests a path that is disallowed by the robots.txt it requested
+// before with the same user agent.
+func (c *Classifier) violatesRobotsTxt(ctx context.Context, r Request) bool {
+	if c.Statistics == nil || !robots.IsDisallowed(r.Path) {
+		return false
+	}
+
+	return c.Statistics.HasRequestedRobotsTxt(ctx, r.Client, r.Header.Get("User-Agent"))
+}
+
+// isTrapped returns true if the client requests a honeypot path or did so within the HoneypotMemory.
+func (c *Classifier) isTrapped(client, requestPath string, now time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if slices.ContainsFunc(c.HoneypotPaths, func(pattern string) bool {
+		matched, _ := path.Match(pattern, requestPath)
+
+		return matched
+	}) {
+		c.trapped[client] = now
+	}
+	trappedAt, ok := c.trapped[client]
+	if ok && now.Sub(trappedAt) > HoneypotMemory {
+		delete(c.trapped, client)
+
+		return false
+	}
+
+	return ok
+}
+
+// exceedsRate records the request of the client and returns true if the client sent more than RateLimit requests
+// within the RateWindow.
+func (c *Classifier) exceedsRate(client string, now time.Time) bool {
+	if c.RateLimit <= 0 {
+		return false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.requests) >= maxClients {
+		for known, requests := range c.requests {
+			if now.Sub(requests[len(requests)-1]) > RateWindow {
+				delete(c.requests, known)
+			}
+		}
+	}
+	requests := slices.DeleteFunc(c.requests[client], func(t time.Time) bool {
+		return now.Sub(t) > RateWindow
+	})
+	requests = append(requests, now)
+	c.requests[client] = requests
+
+	return len(requests) > c.RateLimit
+}
+
+// contextKey is the key of the Classification in a context.
+type contextKey struct{}
+
+// NewContext returns a copy of the context that carries the classification.
+func NewContext(ctx context.Context, classification Classification) context.Context {
+	return context.WithValue(ctx, contextKey{}, classification)
+}
+
+// FromContext returns the classification of the context, or the classification of a human if there is none.
+func FromContext(ctx context.Context) Classification {
+	classification, _ := ctx.Value(contextKey{}).(Classification)
+
+	return classification
+}
@@ -0,0 +1,13 @@
+package classifier_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestClassifier(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Classifier Suite")
+}
@@ -0,0 +1,223 @@
+package classifier_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+
+	"codeberg.org/konterfai/konterfai/pkg/classifier"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Classifier", func() {
+	const firefox = "Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0"
+
+	var (
+		ctx  context.Context
+		bots *classifier.BotMatcher
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		bots, err = classifier.NewBotMatcher(classifier.DefaultBotUserAgents)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	browser := func(client, path string) classifier.Request {
+		return classifier.Request{Client: client, Path: path, Header: http.Header{
+			"User-Agent":      {firefox},
+			"Accept-Language": {"en-GB,en;q=0.5"},
+			"Accept-Encoding": {"gzip, deflate, br"},
+		}}
+	}
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(GinkgoT().TempDir(), name)
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+
+		return path
+	}
+
+	Context("BotMatcher", func() {
+		It("matches the user agents case-insensitively", func() {
+			Expect(bots.Match("Mozilla/5.0 (compatible; gptbot/1.1)")).To(Equal("GPTBot"))
+			Expect(bots.IsBot(firefox)).To(BeFalse())
+		})
+
+		It("rejects invalid patterns", func() {
+			_, err := classifier.NewBotMatcher([]string{"("})
+			Expect(err).To(MatchError(ContainSubstring("invalid bot user agent pattern")))
+		})
+
+		It("loads additional patterns", func() {
+			patterns, err := classifier.LoadBotUserAgents(writeFile("bots.txt", "# scrapers\nfirefox/13\\d\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(patterns).To(HaveLen(len(classifier.DefaultBotUserAgents) + 1))
+		})
+	})
+
+	Context("LoadNetworks", func() {
+		It("loads networks and single addresses", func() {
+			networks, err := classifier.LoadNetworks(writeFile("networks.txt", "20.15.240.64/28\n2001:db8::1\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networks).To(Equal([]netip.Prefix{
+				netip.MustParsePrefix("20.15.240.64/28"), netip.MustParsePrefix("2001:db8::1/128"),
+			}))
+		})
+
+		It("rejects invalid networks", func() {
+			_, err := classifier.LoadNetworks(writeFile("networks.txt", "20.15.240.64/33\n"))
+			Expect(err).To(MatchError(ContainSubstring("invalid network")))
+		})
+	})
+
+	Context("LoadHoneypotPaths", func() {
+		It("rejects invalid patterns", func() {
+			_, err := classifier.LoadHoneypotPaths(writeFile("honeypot.txt", "/wp-admin/[\n"))
+			Expect(err).To(MatchError(ContainSubstring("invalid honeypot path")))
+		})
+	})
+
+	Context("Classify", func() {
+		It("classifies browsers as humans", func() {
+			c := classifier.NewClassifier(bots, nil, nil, classifier.DefaultThreshold, classifier.DefaultRateLimit, nil)
+			classification := c.Classify(ctx, browser("198.51.100.1", "/"))
+			Expect(classification.Score).To(BeZero())
+			Expect(classification.Crawler).To(BeFalse())
+		})
+
+		It("classifies known user agents as crawlers", func() {
+			c := classifier.NewClassifier(bots, nil, nil, classifier.DefaultThreshold, 0, nil)
+			r := browser("198.51.100.1", "/")
+			r.Header.Set("User-Agent", "CCBot/2.0 (https://commoncrawl.org/faq/)")
+			classification := c.Classify(ctx, r)
+			Expect(classification.Signals).To(Equal([]string{classifier.SignalUserAgent}))
+			Expect(classification.Crawler).To(BeTrue())
+		})
+
+		It("classifies clients of known networks as crawlers", func() {
+			networks := []netip.Prefix{netip.MustParsePrefix("20.15.240.64/28")}
+			c := classifier.NewClassifier(bots, networks, nil, classifier.DefaultThreshold, 0, nil)
+			Expect(c.Classify(ctx, browser("20.15.240.70", "/")).Signals).To(Equal([]string{classifier.SignalNetwork}))
+			Expect(c.Classify(ctx, browser("20.15.240.80", "/")).Crawler).To(BeFalse())
+		})
+
+		It("adds up the missing headers", func() {
+			c := classifier.NewClassifier(bots, nil, nil, 0.1, 0, nil)
+			r := browser("198.51.100.1", "/")
+			r.Header.Del("Accept-Language")
+			Expect(c.Classify(ctx, r).Crawler).To(BeFalse())
+			r.Header.Del("Accept-Encoding")
+			classification := c.Classify(ctx, r)
+			Expect(classification.Score).To(BeNumerically("~", 0.1))
+			Expect(classification.Crawler).To(BeTrue())
+		})
+
+		It("does not classify by the rate and the missing headers alone", func() {
+			c := classifier.NewClassifier(bots, nil, nil, classifier.DefaultThreshold, 1, nil)
+			r := classifier.Request{Client: "198.51.100.1", Path: "/", Header: http.Header{"User-Agent": {firefox}}}
+			c.Classify(ctx, r)
+			classification := c.Classify(ctx, r)
+			Expect(classification.Signals).To(ConsistOf(classifier.SignalRate,
+				classifier.SignalAcceptLanguage, classifier.SignalAcceptEncoding))
+			Expect(classification.Score).To(BeNumerically("<", classifier.DefaultThreshold))
+			Expect(classification.Crawler).To(BeFalse())
+			r.Header.Set("User-Agent", "CCBot/2.0 (https://commoncrawl.org/faq/)")
+			Expect(c.Classify(ctx, r).Crawler).To(BeTrue())
+		})
+
+		It("does not classify by a violation of the robots.txt and the missing headers alone", func() {
+			st := &statistics.Statistics{}
+			st.AppendRequest(ctx, statistics.Request{IPAddress: "198.51.100.1:4711", UserAgent: firefox, IsRobotsTxt: true})
+			c := classifier.NewClassifier(bots, nil, nil, classifier.DefaultThreshold, 0, st)
+			r := classifier.Request{Client: "198.51.100.1", Path: "/", Header: http.Header{"User-Agent": {firefox}}}
+			classification := c.Classify(ctx, r)
+			Expect(classification.Signals).To(ConsistOf(classifier.SignalRobotsTxt,
+				classifier.SignalAcceptLanguage, classifier.SignalAcceptEncoding))
+			Expect(classification.Crawler).To(BeFalse())
+		})
+
+		It("classifies clients that violate the robots.txt at the pace of a crawler as crawlers", func() {
+			st := &statistics.Statistics{}
+			st.AppendRequest(ctx, statistics.Request{IPAddress: "198.51.100.1:4711", UserAgent: firefox, IsRobotsTxt: true})
+			c := classifier.NewClassifier(bots, nil, nil, classifier.DefaultThreshold, 2, st)
+			Expect(c.Classify(ctx, browser("198.51.100.1", "/robots.txt")).Crawler).To(BeFalse())
+			Expect(c.Classify(ctx, browser("198.51.100.1", "/")).Crawler).To(BeFalse())
+			classification := c.Classify(ctx, browser("198.51.100.1", "/news/"))
+			Expect(classification.Signals).To(ConsistOf(classifier.SignalRobotsTxt, classifier.SignalRate))
+			Expect(classification.Crawler).To(BeTrue())
+			Expect(c.Classify(ctx, browser("198.51.100.2", "/news/")).Crawler).To(BeFalse())
+		})
+
+		It("classifies browsers that load many assets as humans", func() {
+			c := classifier.NewClassifier(bots, nil, nil, classifier.DefaultThreshold, classifier.DefaultRateLimit, nil)
+			for range 200 {
+				Expect(c.Classify(ctx, browser("198.51.100.1", "/assets/app.js")).Crawler).To(BeFalse())
+			}
+		})
+
+		It("remembers clients that requested a honeypot path", func() {
+			c := classifier.NewClassifier(bots, nil, []string{"/wp-admin/*"}, classifier.DefaultThreshold, 0, nil)
+			Expect(c.Classify(ctx, browser("198.51.100.1", "/wp-admin/setup.php")).Crawler).To(BeTrue())
+			Expect(c.Classify(ctx, browser("198.51.100.1", "/")).Signals).To(Equal([]string{classifier.SignalHoneypot}))
+			Expect(c.Classify(ctx, browser("198.51.100.2", "/")).Crawler).To(BeFalse())
+		})
+
+		It("notices clients that request too fast", func() {
+			c := classifier.NewClassifier(bots, nil, nil, classifier.DefaultThreshold, 2, nil)
+			Expect(c.Classify(ctx, browser("198.51.100.1", "/")).Crawler).To(BeFalse())
+			Expect(c.Classify(ctx, browser("198.51.100.1", "/")).Crawler).To(BeFalse())
+			Expect(c.Classify(ctx, browser("198.51.100.1", "/")).Signals).To(Equal([]string{classifier.SignalRate}))
+			Expect(c.Classify(ctx, browser("198.51.100.2", "/")).Crawler).To(BeFalse())
+		})
+
+		It("notices clients that request disallowed paths after requesting the robots.txt", func() {
+			st := &statistics.Statistics{}
+			st.AppendRequest(ctx, statistics.Request{IPAddress: "198.51.100.1:4711", UserAgent: firefox, IsRobotsTxt: true})
+			c := classifier.NewClassifier(bots, nil, nil, classifier.DefaultThreshold, 0, st)
+			Expect(c.Classify(ctx, browser("198.51.100.1", "/robots.txt")).Signals).To(BeEmpty())
+			Expect(c.Classify(ctx, browser("198.51.100.1", "/")).Signals).To(Equal([]string{classifier.SignalRobotsTxt}))
+			r := browser("198.51.100.1", "/")
+			r.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:131.0) Gecko/20100101 Firefox/131.0")
+			Expect(c.Classify(ctx, r).Signals).To(BeEmpty())
+		})
+
+		It("notices IPv6 clients that request disallowed paths after requesting the robots.txt", func() {
+			st := &statistics.Statistics{}
+			st.AppendRequest(ctx, statistics.Request{IPAddress: "[2001:db8::1]:4711", UserAgent: firefox, IsRobotsTxt: true})
+			c := classifier.NewClassifier(bots, nil, nil, classifier.DefaultThreshold, 0, st)
+			Expect(c.Classify(ctx, browser("2001:db8::1", "/")).Signals).To(Equal([]string{classifier.SignalRobotsTxt}))
+			Expect(c.Classify(ctx, browser("2001:db8::2", "/")).Signals).To(BeEmpty())
+			Expect(c.Classify(ctx, browser("2001:db8:1::1", "/")).Signals).To(BeEmpty())
+		})
+
+		It("classifies every request as human without a classifier", func() {
+			var c *classifier.Classifier
+			Expect(c.Classify(ctx, browser("198.51.100.1", "/"))).To(Equal(classifier.Classification{}))
+		})
+	})
+
+	Context("NewRequest", func() {
+		It("strips the port of the client", func() {
+			r := httptest.NewRequest(http.MethodGet, "/blog?id=1", nil)
+			r.RemoteAddr = "[2001:db8::1]:4711"
+			Expect(classifier.NewRequest(r)).To(And(
+				HaveField("Client", "2001:db8::1"), HaveField("Path", "/blog"),
+			))
+		})
+	})
+
+	Context("context", func() {
+		It("carries the classification", func() {
+			classification := classifier.Classification{Score: 1, Crawler: true}
+			Expect(classifier.FromContext(classifier.NewContext(ctx, classification))).To(Equal(classification))
+			Expect(classifier.FromContext(ctx).Crawler).To(BeFalse())
+		})
+	})
+})
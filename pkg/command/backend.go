@@ -0,0 +1,218 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"codeberg.org/konterfai/konterfai/pkg/classifier"
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/mutator"
+	"codeberg.org/konterfai/konterfai/pkg/policy"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	"codeberg.org/konterfai/konterfai/pkg/webserver"
+	"github.com/urfave/cli/v2"
+)
+
+// newBackend creates the generation backend selected by the --backend flag.
+// The first of the models is the default model of the backend.
+func newBackend(ctx context.Context, c *cli.Context, models []hallucinator.WeightedModel) (hallucinator.Backend, error) {
+	defaultModel := ""
+	if len(models) > 0 {
+		defaultModel = models[0].Name
+	}
+	switch strings.ToLower(c.String("backend")) {
+	case "ollama":
+		addresses := strings.Split(c.String("ollama-address"), ",")
+		backend := hallucinator.NewOllamaBackend(strings.TrimSpace(addresses[0]), defaultModel)
+		endpoints, err := hallucinator.NewEndpointPool(backend.Name(), addresses, c.String("ollama-balancing"),
+			c.Int("ollama-eject-after"))
+		if err != nil {
+			return nil, err
+		}
+		backend.Endpoints = endpoints
+		backend.Models = hallucinator.ModelNames(models)
+		backend.PullModel = c.Bool("ollama-pull-model")
+		backend.KeepAlive = c.String("ollama-keep-alive")
+
+		return backend, nil
+	case "openai":
+		return hallucinator.NewOpenAIBackend(c.String("openai-address"), defaultModel,
+			c.String("openai-api-key"), c.Bool("openai-stream")), nil
+	case "markov":
+		return hallucinator.NewMarkovBackend(ctx, c.Int("markov-order"), c.String("markov-corpus-dir"),
+			c.Bool("markov-use-dictionaries"))
+	default:
+		return nil, fmt.Errorf("unknown backend %q", c.String("backend"))
+	}
+}
+
+// newWeightedModels parses the weighted model list of the backend selected by the --backend flag.
+// Backends without models, like the markov backend, return an empty list.
+func newWeightedModels(c *cli.Context) ([]hallucinator.WeightedModel, error) {
+	switch strings.ToLower(c.String("backend")) {
+	case "ollama":
+		return hallucinator.ParseWeightedModels(c.String("ollama-model"))
+	case "openai":
+		return hallucinator.ParseWeightedModels(c.String("openai-model"))
+	default:
+		return []hallucinator.WeightedModel{}, nil
+	}
+}
+
+// newGenerationOptionRanges parses the generation option flags.
+func newGenerationOptionRanges(c *cli.Context) (hallucinator.GenerationOptionRanges, error) {
+	ranges := hallucinator.GenerationOptionRanges{Stop: c.StringSlice("ai-stop")}
+	for flag, target := range map[string]*hallucinator.OptionRange{
+		"ai-temperature-range": &ranges.Temperature,
+		"ai-top-p":             &ranges.TopP,
+		"ai-top-k":             &ranges.TopK,
+		"ai-min-p":             &ranges.MinP,
+		"ai-repeat-penalty":    &ranges.RepeatPenalty,
+		"ai-num-predict":       &ranges.NumPredict,
+		"ai-num-ctx":           &ranges.NumCtx,
+	} {
+		optionRange, err := hallucinator.ParseOptionRange(c.String(flag))
+		if err != nil {
+			return hallucinator.GenerationOptionRanges{}, fmt.Errorf("--%s: %w", flag, err)
+		}
+		*target = optionRange
+	}
+
+	return ranges, nil
+}
+
+// newHTTPClientConfig creates the configuration of the http clients for the generation backend.
+func newHTTPClientConfig(c *cli.Context) (hallucinator.HTTPClientConfig, error) {
+	headers, err := hallucinator.ParseHeaders(c.StringSlice("backend-header"))
+	if err != nil {
+		return hallucinator.HTTPClientConfig{}, err
+	}
+
+	return hallucinator.HTTPClientConfig{
+		Headers:  headers,
+		CAFile:   c.String("backend-ca-file"),
+		CertFile: c.String("backend-client-cert"),
+		KeyFile:  c.String("backend-client-key"),
+		ProxyURL: c.String("backend-proxy"),
+	}, nil
+}
+
+// redactURL replaces the password of the url, invalid urls are returned unchanged.
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return parsed.Redacted()
+}
+
+// newValidators creates the validator chain for the generated hallucinations from the cli flags.
+func newValidators(c *cli.Context) (hallucinator.ValidatorChain, error) {
+	refusals, err := hallucinator.LoadRefusalValidator(c.String("refusal-patterns-file"))
+	if err != nil {
+		return nil, err
+	}
+	validators := hallucinator.ValidatorChain{
+		refusals,
+		&hallucinator.MinLengthValidator{MinimalLength: c.Int("hallucination-minimal-length")},
+		&hallucinator.RepetitionValidator{MinUniqueRatio: c.Float64("repetition-min-unique-ratio")},
+	}
+	if path := c.String("denylist-file"); path != "" {
+		denylist, err := hallucinator.LoadDenylistValidator(path)
+		if err != nil {
+			return nil, err
+		}
+		validators = append(validators, denylist)
+	}
+	if c.Bool("validate-language") {
+		validators = append(validators,
+			&hallucinator.LanguageValidator{MinScriptRatio: hallucinator.DefaultMinScriptRatio})
+	}
+
+	return validators, nil
+}
+
+// newProxy creates the proxy to the upstream application from the cli flags, it returns nil without upstream.
+func newProxy(c *cli.Context) (*webserver.Proxy, error) {
+	upstream := c.String("proxy-upstream")
+	if upstream == "" {
+		return nil, nil //nolint: nilnil
+	}
+
+	return webserver.NewProxy(upstream)
+}
+
+// newTrustedProxies parses the comma separated networks of the --trusted-proxies flag.
+func newTrustedProxies(c *cli.Context) ([]netip.Prefix, error) {
+	networks := []netip.Prefix{}
+	for _, network := range strings.Split(c.String("trusted-proxies"), ",") {
+		if network = strings.TrimSpace(network); network == "" {
+			continue
+		}
+		prefix, err := classifier.ParseNetwork(network)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, prefix)
+	}
+
+	return networks, nil
+}
+
+// newClassifier creates the classifier of the requests from the cli flags.
+func newClassifier(c *cli.Context, st *statistics.Statistics) (*classifier.Classifier, error) {
+	botUserAgents, err := classifier.LoadBotUserAgents(c.String("bot-user-agents-file"))
+	if err != nil {
+		return nil, err
+	}
+	bots, err := classifier.NewBotMatcher(botUserAgents)
+	if err != nil {
+		return nil, err
+	}
+	networks, err := classifier.LoadNetworks(c.String("classifier-networks-file"))
+	if err != nil {
+		return nil, err
+	}
+	honeypotPaths, err := classifier.LoadHoneypotPaths(c.String("classifier-honeypot-file"))
+	if err != nil {
+		return nil, err
+	}
+
+	return classifier.NewClassifier(bots, networks, honeypotPaths, c.Float64("classifier-threshold"),
+		c.Int("classifier-rate-limit"), st), nil
+}
+
+// newPolicy creates the policy of the requests from the cli flags, it returns nil without rules file.
+func newPolicy(ctx context.Context, c *cli.Context, logger *slog.Logger,
+	st *statistics.Statistics,
+) (*policy.Policy, error) {
+	rulesPath := c.String("policy-file")
+	if rulesPath == "" {
+		return nil, nil //nolint: nilnil
+	}
+
+	return policy.NewPolicy(ctx, logger, rulesPath, st)
+}
+
+// newMutator creates the mutator of the served hallucinations from the cli flags, it returns nil if the mutations
+// are disabled.
+func newMutator(c *cli.Context) (*mutator.Mutator, error) {
+	seed := c.Int64("mutation-seed")
+	if seed == 0 {
+		return nil, nil //nolint: nilnil
+	}
+	substitutions := map[string]string{}
+	if path := c.String("mutation-file"); path != "" {
+		var err error
+		if substitutions, err = mutator.LoadSubstitutions(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return mutator.NewMutator(seed, c.Int("mutation-year-offset"), substitutions), nil
+}
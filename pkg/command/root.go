@@ -26,6 +26,65 @@ func Initialize() error { //nolint: funlen
 				DefaultText: "8080",
 			},
 
+			&cli.StringFlag{
+				Name: "proxy-upstream",
+				Usage: "The url of the application konterfAI runs in front of. Requests that are not from AI crawlers" +
+					" are proxied to it, so no further reverse proxy is needed. If empty, all requests are served.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name: "bot-user-agents-file",
+				Usage: "A file with additional regular expressions, one per line, of the user agents of AI crawlers." +
+					" They are matched case-insensitively by the proxy and the forward-auth endpoint.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name: "forward-auth-path",
+				Usage: "The path of the decision endpoint for traefik ForwardAuth and nginx auth_request." +
+					" If empty, the endpoint is disabled.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name: "trusted-proxies",
+				Usage: "Comma separated networks, CIDR or IP address, of the reverse proxies in front of konterfAI." +
+					" Their requests are handled as requests of the client in X-Forwarded-For or X-Real-IP.",
+				Value: "",
+			},
+			&cli.Float64Flag{
+				Name:        "classifier-threshold",
+				Usage:       "The score, from 0 to 1, from which a request is classified as an AI crawler.",
+				Value:       0.5,
+				DefaultText: "0.5",
+			},
+			&cli.StringFlag{
+				Name:  "classifier-networks-file",
+				Usage: "A file with the networks of AI crawlers, one CIDR or IP address per line.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name: "classifier-honeypot-file",
+				Usage: "A file with honeypot paths, one glob pattern per line. Clients that request one of them are" +
+					" classified as AI crawlers for a day.",
+				Value: "",
+			},
+			&cli.IntFlag{
+				Name:        "classifier-rate-limit",
+				Usage:       "The number of requests per minute from which a client is too fast for a human. 0 disables it.",
+				Value:       600,
+				DefaultText: "600",
+			},
+			&cli.StringFlag{
+				Name: "policy-file",
+				Usage: "A YAML or JSON file with rules that map requests to actions. The file is reloaded when it" +
+					" changes. If empty, all requests get the same treatment.",
+				Value: "",
+			},
+			&cli.DurationFlag{
+				Name:        "policy-reload-interval",
+				Usage:       "The interval in which the policy file is checked for changes.",
+				Value:       5 * time.Second,
+				DefaultText: "5s",
+			},
 			&cli.IntFlag{
 				Name:        "statistics-port",
 				Usage:       "The port to listen on for statistics.",
@@ -46,6 +105,13 @@ func Initialize() error { //nolint: funlen
 				Value:       5 * time.Second,
 				DefaultText: "5",
 			},
+			&cli.IntFlag{
+				Name: "generation-workers",
+				Usage: "The number of workers generating hallucinations in parallel." +
+					" Use higher numbers if your backend can handle concurrent requests (multiple cores or GPUs).",
+				Value:       1,
+				DefaultText: "1",
+			},
 			&cli.IntFlag{
 				Name: "hallucination-cache-size",
 				Usage: "The number of hallucinations to cache." +
@@ -54,6 +120,30 @@ func Initialize() error { //nolint: funlen
 				Value:       10,
 				DefaultText: "10",
 			},
+			&cli.StringFlag{
+				Name: "hallucination-cache-dir",
+				Usage: "The directory the hallucinations cache is persisted to, it is loaded at startup and saved on shutdown." +
+					" If empty, the cache is kept in memory only.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name: "world-file",
+				Usage: "The file of the fictional world (people, organisations, places and events) the hallucinations" +
+					" share. It is generated once if it does not exist. If empty, there is no world.",
+				Value: "",
+			},
+			&cli.Int64Flag{
+				Name:        "world-seed",
+				Usage:       "The seed the world is generated from. Use 0 for a random seed.",
+				Value:       0,
+				DefaultText: "0",
+			},
+			&cli.IntFlag{
+				Name:        "world-size",
+				Usage:       "The number of people, organisations, places and events each of a generated world, at least 1.",
+				Value:       25,
+				DefaultText: "25",
+			},
 			&cli.IntFlag{
 				Name: "hallucination-prompt-word-count",
 				Usage: "The number of words (nouns, verbs, ..) to use for hallucination prompts." +
@@ -62,6 +152,12 @@ func Initialize() error { //nolint: funlen
 				Value:       5,
 				DefaultText: "5",
 			},
+			&cli.StringFlag{
+				Name: "dictionary-dir",
+				Usage: "A directory with YAML/JSON/plain text dictionary packs that extend or replace the built-in" +
+					" prompts and word lists.",
+				Value: "",
+			},
 			&cli.IntFlag{
 				Name: "hallucination-word-count",
 				Usage: " The number of words that is expected from the resulting hallucination" +
@@ -76,6 +172,43 @@ func Initialize() error { //nolint: funlen
 				Value:       5,
 				DefaultText: "5",
 			},
+			&cli.Float64Flag{
+				Name: "remix-probability",
+				Usage: "The probability (0 to 1) that a served hallucination is remixed with sentences of other cached" +
+					" hallucinations, synonyms and reordered paragraphs. Use 0 to serve the cached hallucinations verbatim.",
+				Value:       0,
+				DefaultText: "0",
+			},
+			&cli.Float64Flag{
+				Name:        "remix-splice-probability",
+				Usage:       "The probability (0 to 1) that a sentence of a remixed hallucination is replaced with another one.",
+				Value:       0.2,
+				DefaultText: "0.2",
+			},
+			&cli.Float64Flag{
+				Name:        "remix-synonym-probability",
+				Usage:       "The probability (0 to 1) that a word of a remixed hallucination is swapped for a synonym.",
+				Value:       0.3,
+				DefaultText: "0.3",
+			},
+			&cli.Int64Flag{
+				Name: "mutation-seed",
+				Usage: "The seed of the wrong facts (swapped capitals and companies, shifted years) that are applied" +
+					" consistently to all served hallucinations. Keep it per deployment, use 0 to disable the mutations.",
+				Value:       0,
+				DefaultText: "0",
+			},
+			&cli.IntFlag{
+				Name:        "mutation-year-offset",
+				Usage:       "The number of years all years are shifted by. Use 0 to derive the offset from the mutation seed.",
+				Value:       0,
+				DefaultText: "0",
+			},
+			&cli.StringFlag{
+				Name:  "mutation-file",
+				Usage: "A file with additional substitutions, one \"original = replacement\" per line.",
+				Value: "",
+			},
 			&cli.IntFlag{
 				Name: "hallucination-minimal-length",
 				Usage: "The minimal length of a hallucination in characters." +
@@ -83,6 +216,44 @@ func Initialize() error { //nolint: funlen
 				Value:       500,
 				DefaultText: "500",
 			},
+			&cli.StringFlag{
+				Name: "refusal-patterns-file",
+				Usage: "A file with additional regular expressions, one per line, that detect refusals of the model." +
+					" They are matched case-insensitively.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  "denylist-file",
+				Usage: "A file with phrases, one per line, that must not appear in a hallucination.",
+				Value: "",
+			},
+			&cli.Float64Flag{
+				Name: "repetition-min-unique-ratio",
+				Usage: "The minimal share of distinct three-word phrases in a hallucination, lower values are" +
+					" rejected as degenerate loops. Use 0 to disable this check.",
+				Value:       0.3,
+				DefaultText: "0.3",
+			},
+			&cli.Float64Flag{
+				Name: "duplicate-threshold",
+				Usage: "The similarity (0 to 1) from which a hallucination is rejected as near-duplicate of a cached" +
+					" or a recent hallucination. Use 0 to disable the near-duplicate detection.",
+				Value:       0.8,
+				DefaultText: "0.8",
+			},
+			&cli.IntFlag{
+				Name:        "duplicate-history",
+				Usage:       "The number of recent hallucinations the near-duplicate detection compares against.",
+				Value:       100,
+				DefaultText: "100",
+			},
+			&cli.BoolFlag{
+				Name: "validate-language",
+				Usage: "Reject hallucinations that are not written in the language of the prompt. Do not enable" +
+					" this with the markov backend, it does not follow the prompt.",
+				Value:       false,
+				DefaultText: "false",
+			},
 			&cli.IntFlag{
 				Name:        "hallucinator-link-percentage",
 				Usage:       "The percentage of links to add to the hallucination measured by total words.",
@@ -107,29 +278,154 @@ func Initialize() error { //nolint: funlen
 				Value:       5,
 				DefaultText: "5",
 			},
+			&cli.StringFlag{
+				Name:        "backend",
+				Usage:       "The backend to use for generating hallucinations. Possible values are: ollama, openai, markov.",
+				Value:       "ollama",
+				DefaultText: "ollama",
+			},
 			&cli.StringFlag{
 				Name:        "ollama-address",
-				Usage:       "The address of the ollama service.",
+				Usage:       "The address of the ollama service, a comma separated list spreads the requests across several instances.",
 				Value:       "http://localhost:11434",
 				DefaultText: "http://localhost:11434",
 			},
 			&cli.StringFlag{
 				Name: "ollama-model",
 				Usage: "The model to use for hallucinations. Must be an active model in the ollama instance." +
-					" The smaller the model, the faster the hallucination generation will be and the less CPU-/GPU-time will be used.",
+					" The smaller the model, the faster the hallucination generation will be and the less CPU-/GPU-time will be used." +
+					" A weighted list of models (e.g. qwen2:0.5b=3,tinyllama=1) picks a model per generation.",
 				Value:       "qwen2:0.5b",
 				DefaultText: "qwen2:0.5b",
 			},
+			&cli.BoolFlag{
+				Name:        "ollama-pull-model",
+				Usage:       "Pull the model at startup if it is not present in the ollama instance.",
+				Value:       false,
+				DefaultText: "false",
+			},
+			&cli.StringFlag{
+				Name: "ollama-keep-alive",
+				Usage: "How long ollama keeps the model loaded (e.g. 10m, 24h or -1 for forever)." +
+					" If set, the model is loaded at startup. If empty, the ollama default is used.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:        "ollama-balancing",
+				Usage:       "How requests are spread across several ollama instances (round-robin or least-outstanding).",
+				Value:       "least-outstanding",
+				DefaultText: "least-outstanding",
+			},
+			&cli.IntFlag{
+				Name: "ollama-eject-after",
+				Usage: "The number of consecutive failures after which an ollama instance is ejected until a health" +
+					" probe succeeds again, 0 never ejects instances.",
+				Value:       3,
+				DefaultText: "3",
+			},
+			&cli.DurationFlag{
+				Name:        "ollama-health-interval",
+				Usage:       "The interval of the health probes of the ollama instances.",
+				Value:       10 * time.Second,
+				DefaultText: "10s",
+			},
+			&cli.StringFlag{
+				Name: "openai-address",
+				Usage: "The address of the OpenAI-compatible service (llama.cpp server, vLLM, LocalAI, ...)," +
+					" without the /v1 suffix.",
+				Value:       "http://localhost:8000",
+				DefaultText: "http://localhost:8000",
+			},
+			&cli.StringFlag{
+				Name:  "openai-model",
+				Usage: "The model to use for hallucinations with the openai backend. Accepts a weighted list like --ollama-model.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:    "openai-api-key",
+				Usage:   "The API key for the OpenAI-compatible service. If empty, no Authorization header is sent.",
+				Value:   "",
+				EnvVars: []string{"OPENAI_API_KEY"},
+			},
+			&cli.BoolFlag{
+				Name:        "openai-stream",
+				Usage:       "Request the hallucination as a stream of server-sent events from the OpenAI-compatible service.",
+				Value:       false,
+				DefaultText: "false",
+			},
+			&cli.IntFlag{
+				Name: "backend-failure-threshold",
+				Usage: "The number of consecutive backend failures that open the circuit breaker." +
+					" While open, the backend is not called. Use 0 to disable the circuit breaker.",
+				Value:       5,
+				DefaultText: "5",
+			},
+			&cli.DurationFlag{
+				Name: "backend-open-timeout",
+				Usage: "The time the circuit breaker stays open before a single probe request is sent to the backend." +
+					" It doubles (with jitter) every time the probe fails.",
+				Value:       30 * time.Second,
+				DefaultText: "30s",
+			},
+			&cli.DurationFlag{
+				Name:        "backend-max-backoff",
+				Usage:       "The maximum time between retries of failed generations and circuit breaker probes.",
+				Value:       5 * time.Minute,
+				DefaultText: "5m",
+			},
+			&cli.StringFlag{
+				Name:  "markov-corpus-dir",
+				Usage: "The directory with text files the markov backend is trained on at startup.",
+				Value: "",
+			},
+			&cli.IntFlag{
+				Name:        "markov-order",
+				Usage:       "The number of preceding words the markov backend uses to choose the next word.",
+				Value:       2,
+				DefaultText: "2",
+			},
+			&cli.BoolFlag{
+				Name:        "markov-use-dictionaries",
+				Usage:       "Train the markov backend on sentences built from the embedded dictionaries.",
+				Value:       true,
+				DefaultText: "true",
+			},
 			&cli.DurationFlag{
 				Name:        "ollama-request-timeout",
-				Usage:       "The timeout for the ollama service.",
+				Usage:       "The timeout for requests to the generation backend.",
 				Value:       60 * time.Second,
 				DefaultText: "60s",
 			},
+			&cli.StringSliceFlag{
+				Name: "backend-header",
+				Usage: "A header (\"Name: value\") sent with every request to the generation backend," +
+					" e.g. \"Authorization: Bearer <token>\", can be given multiple times.",
+				EnvVars: []string{"BACKEND_HEADER"},
+			},
+			&cli.StringFlag{
+				Name:  "backend-ca-file",
+				Usage: "A PEM bundle of additional CAs to trust for the generation backend.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  "backend-client-cert",
+				Usage: "The PEM client certificate for mTLS with the generation backend.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  "backend-client-key",
+				Usage: "The PEM key of the client certificate for mTLS with the generation backend.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  "backend-proxy",
+				Usage: "The proxy for requests to the generation backend. If empty, HTTP_PROXY/HTTPS_PROXY are used.",
+				Value: "",
+			},
 			&cli.Float64Flag{
 				Name: "ai-temperature",
 				Usage: "The temperature for the AI. Use a high number for more randomness." +
-					" and a low number for more coherence.",
+					" and a low number for more coherence. The openai backend clamps it to 2.",
 				Value:       30.0,
 				DefaultText: "30.0",
 			},
@@ -139,6 +435,50 @@ func Initialize() error { //nolint: funlen
 				Value:       0,
 				DefaultText: "0",
 			},
+			&cli.StringFlag{
+				Name:  "ai-temperature-range",
+				Usage: "The range (min:max) the temperature is picked from for every generation, overrides --ai-temperature.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  "ai-top-p",
+				Usage: "The top_p value (or min:max range) for the AI, unset uses the backend default.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  "ai-top-k",
+				Usage: "The top_k value (or min:max range) for the AI, unset uses the backend default.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  "ai-min-p",
+				Usage: "The min_p value (or min:max range) for the AI, unset uses the backend default.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  "ai-repeat-penalty",
+				Usage: "The repeat penalty (or min:max range) for the AI, unset uses the backend default.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  "ai-num-predict",
+				Usage: "The maximum number of tokens (or min:max range) to generate, unset uses the backend default.",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  "ai-num-ctx",
+				Usage: "The context window size (or min:max range) for the AI, unset uses the backend default (ollama only).",
+				Value: "",
+			},
+			&cli.StringSliceFlag{
+				Name:  "ai-stop",
+				Usage: "A stop sequence for the AI, can be given multiple times.",
+			},
+			&cli.StringFlag{
+				Name:  "ai-system-prompt",
+				Usage: "The system message sent to the AI before the prompt.",
+				Value: "",
+			},
 			&cli.Float64Flag{
 				Name:        "webserver-200-probability",
 				Usage:       "The probability of returning a 200 status code for a request.",
@@ -152,6 +492,51 @@ func Initialize() error { //nolint: funlen
 				Value:       1000,
 				DefaultText: "1000",
 			},
+			&cli.BoolFlag{
+				Name: "stream-on-empty-cache",
+				Usage: "Stream a freshly generated hallucination to the client while it is generated," +
+					" if the hallucinations cache is empty. The slow generation doubles as a tarpit.",
+				Value:       false,
+				DefaultText: "false",
+			},
+			&cli.IntFlag{
+				Name:        "stream-max-concurrent",
+				Usage:       "The maximum number of concurrently streamed hallucinations, further requests get the cached page.",
+				Value:       2,
+				DefaultText: "2",
+			},
+			&cli.IntFlag{
+				Name: "tarpit-bytes-per-second",
+				Usage: "Drip-feed the hallucinations with this rate in bytes per second to tie up the workers of" +
+					" crawlers. Use 0 to disable the tarpit.",
+				Value:       0,
+				DefaultText: "0",
+			},
+			&cli.IntFlag{
+				Name:        "tarpit-chunk-size",
+				Usage:       "The number of bytes the tarpit writes at once.",
+				Value:       64,
+				DefaultText: "64",
+			},
+			&cli.Float64Flag{
+				Name:        "tarpit-jitter",
+				Usage:       "The share (0 to 1) by which the delay between two chunks of the tarpit varies randomly.",
+				Value:       0.5,
+				DefaultText: "0.5",
+			},
+			&cli.IntFlag{
+				Name:        "tarpit-max-connections",
+				Usage:       "The maximum number of tarpitted connections, further requests get the page at once.",
+				Value:       100,
+				DefaultText: "100",
+			},
+			&cli.IntFlag{
+				Name: "tarpit-max-connections-per-client",
+				Usage: "The maximum number of tarpitted connections of one client ip address, further requests get" +
+					" the page at once.",
+				Value:       4,
+				DefaultText: "4",
+			},
 			&cli.Float64Flag{
 				Name:  "random-uncertainty",
 				Usage: "The uncertainty for the random generator (0.1 = 10%). Use a high number for more randomness.",
@@ -5,12 +5,18 @@ import (
 	"fmt"
 	"log/slog"
 	"net/url"
+	"os"
 	"strings"
+	"syscall"
+	"time"
 
+	"codeberg.org/konterfai/konterfai/pkg/dictionaries"
 	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/policy"
 	"codeberg.org/konterfai/konterfai/pkg/statistics"
 	"codeberg.org/konterfai/konterfai/pkg/statisticsserver"
 	"codeberg.org/konterfai/konterfai/pkg/webserver"
+	"codeberg.org/konterfai/konterfai/pkg/world"
 	"github.com/oklog/run"
 	"github.com/urfave/cli/v2"
 )
@@ -36,15 +42,144 @@ func Run(c *cli.Context, logger *slog.Logger) error { //nolint: funlen
 
 		return err
 	}
+	// The dictionaries must be loaded before the backend, the markov backend is trained on them.
+	if dir := c.String("dictionary-dir"); dir != "" {
+		packs, err := dictionaries.LoadDirectory(dir)
+		if err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("could not load dictionaries (%v)", err))
+
+			return err
+		}
+		logger.InfoContext(ctx, fmt.Sprintf("loaded %d dictionary packs from %s", packs, dir))
+	}
+	models, err := newWeightedModels(c)
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not parse models (%v)", err))
+
+		return err
+	}
+	backend, err := newBackend(ctx, c, models)
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not create backend (%v)", err))
+
+		return err
+	}
 	hal := hallucinator.NewHallucinator(ctx, logger, c.Duration("generate-interval"),
 		c.Int("hallucination-cache-size"), c.Int("hallucination-prompt-word-count"),
 		c.Int("hallucination-request-count"), c.Int("hallucination-minimal-length"),
 		c.Int("hallucination-word-count"), c.Int("hallucinator-link-percentage"),
 		c.Int("hallucinator-link-max-subdirectory-depth"),
 		c.Float64("hallucinator-link-has-variables-probability"), c.Int("hallucinator-link-max-variables"),
-		*hcURL, c.String("ollama-address"), c.String("ollama-model"),
-		c.Duration("ollama-request-timeout"), c.Float64("ai-temperature"), c.Int("ai-seed"), st)
+		*hcURL, backend, c.Duration("ollama-request-timeout"), c.Float64("ai-temperature"), c.Int("ai-seed"), st)
+	optionRanges, err := newGenerationOptionRanges(c)
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not parse generation options (%v)", err))
+
+		return err
+	}
+	httpClientConfig, err := newHTTPClientConfig(c)
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not parse backend headers (%v)", err))
+
+		return err
+	}
+	if hal.HTTPClient, err = hallucinator.NewHTTPClient(httpClientConfig, c.Duration("ollama-request-timeout")); err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not create backend http client (%v)", err))
+
+		return err
+	}
+	// Pulling a model can take much longer than a generation, the bootstrap is only bounded by the context.
+	if hal.BootstrapClient, err = hallucinator.NewHTTPClient(httpClientConfig, 0); err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not create backend http client (%v)", err))
+
+		return err
+	}
+	if hal.Validators, err = newValidators(c); err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not create validators (%v)", err))
+
+		return err
+	}
+	if threshold := c.Float64("duplicate-threshold"); threshold > 0 {
+		hal.Duplicates = hallucinator.NewDuplicateDetector(threshold, c.Int("duplicate-history"))
+	}
+	if probability := c.Float64("remix-probability"); probability > 0 {
+		hal.Remix = hallucinator.NewRemixer(probability, c.Float64("remix-splice-probability"),
+			c.Float64("remix-synonym-probability"))
+	}
+	if hal.Mutator, err = newMutator(c); err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not create mutator (%v)", err))
+
+		return err
+	}
+	if path := c.String("world-file"); path != "" {
+		if size := c.Int("world-size"); size < 1 {
+			err := fmt.Errorf("--world-size must be at least 1, got %d", size)
+			logger.ErrorContext(ctx, fmt.Sprintf("could not load world (%v)", err))
+
+			return err
+		}
+		seed := c.Int64("world-seed")
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		w, generated, err := world.LoadOrGenerate(ctx, path, seed, c.Int("world-size"))
+		if err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("could not load world (%v)", err))
+
+			return err
+		}
+		if generated {
+			logger.InfoContext(ctx, fmt.Sprintf("generated a world with %d entities to %s", len(w.Entities), path))
+		} else {
+			logger.InfoContext(ctx, fmt.Sprintf("loaded a world with %d entities from %s", len(w.Entities), path))
+		}
+		hal.World = w
+	}
+	hal.Models = models
+	hal.OptionRanges = optionRanges
+	hal.SystemPrompt = c.String("ai-system-prompt")
+	hal.GenerationWorkers = c.Int("generation-workers")
+	hal.HealthCheckInterval = c.Duration("ollama-health-interval")
+	hal.CircuitBreaker = hallucinator.NewCircuitBreaker(c.Int("backend-failure-threshold"),
+		c.Duration("backend-open-timeout"), c.Duration("backend-max-backoff"))
+	if dir := c.String("hallucination-cache-dir"); dir != "" {
+		hal.CacheStore = hallucinator.NewCacheStore(dir)
+	}
+	proxy, err := newProxy(c)
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not create proxy (%v)", err))
+
+		return err
+	}
+	requestClassifier, err := newClassifier(c, st)
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not create classifier (%v)", err))
+
+		return err
+	}
+	trustedProxies, err := newTrustedProxies(c)
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not parse trusted proxies (%v)", err))
+
+		return err
+	}
+	requestPolicy, err := newPolicy(ctx, c, logger, st)
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not create policy (%v)", err))
+
+		return err
+	}
+	if requestPolicy != nil {
+		logger.InfoContext(ctx, fmt.Sprintf("loaded %d policy rules from %s", len(requestPolicy.Rules()),
+			requestPolicy.Path))
+		go requestPolicy.Watch(ctx, c.Duration("policy-reload-interval"))
+		if requestPolicy.HasAction(policy.ActionTarpit) && c.Int("tarpit-bytes-per-second") <= 0 {
+			logger.WarnContext(ctx, "the policy has tarpit rules but the tarpit is disabled, their requests are"+
+				" served at full speed (set --tarpit-bytes-per-second to enable it)")
+		}
+	}
 	gr := run.Group{}
+	gr.Add(run.SignalHandler(ctx, os.Interrupt, syscall.SIGTERM))
 	gr.Add(func() error {
 		select {
 		case <-ctx.Done():
@@ -60,6 +195,17 @@ func Run(c *cli.Context, logger *slog.Logger) error { //nolint: funlen
 		ws := webserver.NewWebServer(ctx, logger, c.String("address"), c.Int("port"), hal, st, *hcURL,
 			c.Float64("webserver-200-probability"), c.Float64("random-uncertainty"),
 			c.Int("webserver-error-cache-size"))
+		ws.StreamOnEmptyCache = c.Bool("stream-on-empty-cache")
+		ws.StreamMaxConcurrent = c.Int("stream-max-concurrent")
+		ws.Proxy = proxy
+		ws.Classifier = requestClassifier
+		ws.Policy = requestPolicy
+		ws.ForwardAuthPath = c.String("forward-auth-path")
+		ws.TrustedProxies = trustedProxies
+		if rate := c.Int("tarpit-bytes-per-second"); rate > 0 {
+			ws.Tarpit = webserver.NewTarpit(rate, c.Int("tarpit-chunk-size"), c.Float64("tarpit-jitter"),
+				c.Int("tarpit-max-connections"), c.Int("tarpit-max-connections-per-client"))
+		}
 		select {
 		case <-ctx.Done():
 			return nil
@@ -101,16 +247,79 @@ func generateHeader(c *cli.Context, withHeadline bool) string {
 	header += strings.Join([]string{
 		fmt.Sprintln("\t- Address: \t\t\t\t", c.String("address")),
 		fmt.Sprintln("\t- Port: \t\t\t\t", c.Int("port")),
+		fmt.Sprintln("\t- Proxy Upstream: \t\t\t", redactURL(c.String("proxy-upstream"))),
+		fmt.Sprintln("\t- Bot User Agents File: \t\t", c.String("bot-user-agents-file")),
+		fmt.Sprintln("\t- Forward Auth Path: \t\t\t", c.String("forward-auth-path")),
+		fmt.Sprintln("\t- Trusted Proxies: \t\t\t", c.String("trusted-proxies")),
+		fmt.Sprintln("\t- Classifier Threshold: \t\t", c.Float64("classifier-threshold")),
+		fmt.Sprintln("\t- Classifier Networks File: \t\t", c.String("classifier-networks-file")),
+		fmt.Sprintln("\t- Classifier Honeypot File: \t\t", c.String("classifier-honeypot-file")),
+		fmt.Sprintln("\t- Classifier Rate Limit: \t\t", c.Int("classifier-rate-limit")),
+		fmt.Sprintln("\t- Policy File: \t\t\t", c.String("policy-file")),
+		fmt.Sprintln("\t- Policy Reload Interval: \t\t", c.Duration("policy-reload-interval")),
 		fmt.Sprintln("\t- Statistics Port: \t\t\t", c.Int("statistics-port")),
 		fmt.Sprintln("\t- Generate Interval: \t\t\t", c.Duration("generate-interval")),
+		fmt.Sprintln("\t- Generation Workers: \t\t\t", c.Int("generation-workers")),
 		fmt.Sprintln("\t- Hallucination Cache Size: \t\t", c.Int("hallucination-cache-size")),
 		fmt.Sprintln("\t- Hallucination Prompt Word Count: \t", c.Int("hallucination-prompt-word-count")),
+		fmt.Sprintln("\t- Dictionary Directory: \t\t", c.String("dictionary-dir")),
 		fmt.Sprintln("\t- Hallucination Word Count: \t\t", c.Int("hallucination-word-count")),
 		fmt.Sprintln("\t- Hallucination Request Count:  \t", c.Int("hallucination-request-count")),
+		fmt.Sprintln("\t- Remix Probability: \t\t\t", c.Float64("remix-probability")),
+		fmt.Sprintln("\t- Remix Splice Probability: \t\t", c.Float64("remix-splice-probability")),
+		fmt.Sprintln("\t- Remix Synonym Probability: \t\t", c.Float64("remix-synonym-probability")),
+		fmt.Sprintln("\t- Mutation Seed: \t\t\t", c.Int64("mutation-seed")),
+		fmt.Sprintln("\t- Mutation Year Offset: \t\t", c.Int("mutation-year-offset")),
+		fmt.Sprintln("\t- Mutation File: \t\t\t", c.String("mutation-file")),
+		fmt.Sprintln("\t- Hallucination Minimal Length: \t", c.Int("hallucination-minimal-length")),
+		fmt.Sprintln("\t- Refusal Patterns File: \t\t", c.String("refusal-patterns-file")),
+		fmt.Sprintln("\t- Denylist File: \t\t\t", c.String("denylist-file")),
+		fmt.Sprintln("\t- Repetition Min Unique Ratio: \t", c.Float64("repetition-min-unique-ratio")),
+		fmt.Sprintln("\t- Duplicate Threshold: \t\t", c.Float64("duplicate-threshold")),
+		fmt.Sprintln("\t- Duplicate History: \t\t\t", c.Int("duplicate-history")),
+		fmt.Sprintln("\t- Validate Language: \t\t\t", c.Bool("validate-language")),
+		fmt.Sprintln("\t- Hallucination Cache Directory: \t", c.String("hallucination-cache-dir")),
+		fmt.Sprintln("\t- World File: \t\t\t\t", c.String("world-file")),
+		fmt.Sprintln("\t- World Seed: \t\t\t\t", c.Int64("world-seed")),
+		fmt.Sprintln("\t- World Size: \t\t\t\t", c.Int("world-size")),
+		fmt.Sprintln("\t- Backend: \t\t\t\t", c.String("backend")),
 		fmt.Sprintln("\t- Ollama Address: \t\t\t", c.String("ollama-address")),
 		fmt.Sprintln("\t- Ollama Model: \t\t\t", c.String("ollama-model")),
+		fmt.Sprintln("\t- Ollama Pull Model: \t\t\t", c.Bool("ollama-pull-model")),
+		fmt.Sprintln("\t- Ollama Keep Alive: \t\t\t", c.String("ollama-keep-alive")),
+		fmt.Sprintln("\t- Ollama Balancing: \t\t\t", c.String("ollama-balancing")),
+		fmt.Sprintln("\t- Ollama Eject After: \t\t\t", c.Int("ollama-eject-after")),
+		fmt.Sprintln("\t- Ollama Health Interval: \t\t", c.Duration("ollama-health-interval")),
+		fmt.Sprintln("\t- OpenAI Address: \t\t\t", c.String("openai-address")),
+		fmt.Sprintln("\t- OpenAI Model: \t\t\t", c.String("openai-model")),
+		fmt.Sprintln("\t- Backend Failure Threshold: \t\t", c.Int("backend-failure-threshold")),
+		fmt.Sprintln("\t- Backend Open Timeout: \t\t", c.Duration("backend-open-timeout")),
+		fmt.Sprintln("\t- Backend Max Backoff: \t\t", c.Duration("backend-max-backoff")),
+		// The headers usually carry credentials, only their number is shown.
+		fmt.Sprintln("\t- Backend Headers: \t\t\t", len(c.StringSlice("backend-header"))),
+		fmt.Sprintln("\t- Backend CA File: \t\t\t", c.String("backend-ca-file")),
+		fmt.Sprintln("\t- Backend Client Certificate: \t", c.String("backend-client-cert")),
+		fmt.Sprintln("\t- Backend Proxy: \t\t\t", redactURL(c.String("backend-proxy"))),
+		fmt.Sprintln("\t- Stream On Empty Cache: \t\t", c.Bool("stream-on-empty-cache")),
+		fmt.Sprintln("\t- Stream Max Concurrent: \t\t", c.Int("stream-max-concurrent")),
+		fmt.Sprintln("\t- Tarpit Bytes Per Second: \t\t", c.Int("tarpit-bytes-per-second")),
+		fmt.Sprintln("\t- Tarpit Chunk Size: \t\t\t", c.Int("tarpit-chunk-size")),
+		fmt.Sprintln("\t- Tarpit Jitter: \t\t\t", c.Float64("tarpit-jitter")),
+		fmt.Sprintln("\t- Tarpit Max Connections: \t\t", c.Int("tarpit-max-connections")),
+		fmt.Sprintln("\t- Tarpit Max Connections Per Client: \t", c.Int("tarpit-max-connections-per-client")),
+		fmt.Sprintln("\t- Markov Corpus Directory: \t\t", c.String("markov-corpus-dir")),
+		fmt.Sprintln("\t- Markov Order: \t\t\t", c.Int("markov-order")),
 		fmt.Sprintln("\t- AI Temperature: \t\t\t", c.Float64("ai-temperature")),
 		fmt.Sprintln("\t- AI Seed: \t\t\t\t", c.Int("ai-seed")),
+		fmt.Sprintln("\t- AI Temperature Range: \t\t", c.String("ai-temperature-range")),
+		fmt.Sprintln("\t- AI Top P: \t\t\t\t", c.String("ai-top-p")),
+		fmt.Sprintln("\t- AI Top K: \t\t\t\t", c.String("ai-top-k")),
+		fmt.Sprintln("\t- AI Min P: \t\t\t\t", c.String("ai-min-p")),
+		fmt.Sprintln("\t- AI Repeat Penalty: \t\t\t", c.String("ai-repeat-penalty")),
+		fmt.Sprintln("\t- AI Num Predict: \t\t\t", c.String("ai-num-predict")),
+		fmt.Sprintln("\t- AI Num Ctx: \t\t\t\t", c.String("ai-num-ctx")),
+		fmt.Sprintln("\t- AI Stop: \t\t\t\t", c.StringSlice("ai-stop")),
+		fmt.Sprintln("\t- AI System Prompt: \t\t\t", c.String("ai-system-prompt")),
 		fmt.Sprintln("\t- Hallucinator URL: \t\t\t", c.String("hallucinator-url")),
 		fmt.Sprintln("\t- Log Level: \t\t\t\t", c.String("log-level")),
 		fmt.Sprintln("\t- Log Format: \t\t\t\t", c.String("log-format")),
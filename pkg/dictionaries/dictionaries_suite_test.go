@@ -0,0 +1,13 @@
+package dictionaries_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDictionaries(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dictionaries Suite")
+}
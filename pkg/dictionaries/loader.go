@@ -0,0 +1,354 @@
+package dictionaries
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// PackModeExtend appends the entries of a pack to the lists.
+	PackModeExtend = "extend"
+	// PackModeReplace replaces the lists with the entries of a pack.
+	PackModeReplace = "replace"
+)
+
+// Pack is a dictionary pack loaded from a YAML or JSON file. Lists that are not set in the pack are left unchanged.
+type Pack struct {
+	Mode               string     `json:"mode"               yaml:"mode"`
+	Prompts            []string   `json:"prompts"            yaml:"prompts"`
+	ArticleTypes       []string   `json:"articleTypes"       yaml:"articleTypes"`
+	Languages          []string   `json:"languages"          yaml:"languages"`
+	Nouns              []string   `json:"nouns"              yaml:"nouns"`
+	Verbs              []string   `json:"verbs"              yaml:"verbs"`
+	Cities             []string   `json:"cities"             yaml:"cities"`
+	MetaKeywordsGroups [][]string `json:"metaKeywordsGroups" yaml:"metaKeywordsGroups"`
+	NewsPaperNames     []string   `json:"newsPaperNames"     yaml:"newsPaperNames"`
+	SynonymGroups      [][]string `json:"synonymGroups"      yaml:"synonymGroups"`
+	// Vocabularies are the localized word lists per language, e.g. "German".
+	Vocabularies map[string]Vocabulary `json:"vocabularies" yaml:"vocabularies"`
+}
+
+// promptVerbs are the formatting verbs every prompt must contain, in this order:
+// the article type, the words of the topic, the word count and the language.
+var promptVerbs = []rune{'s', 's', 'd', 's'}
+
+// LoadDirectory loads all dictionary packs of the directory in lexical order and applies them to the lists.
+//
+// YAML (.yaml, .yml) and JSON (.json) files are packs that can set several lists and a mode (extend or replace).
+// Plain text files (.txt) are named after the list they extend, e.g. nouns.txt, or replace, e.g. nouns.replace.txt.
+// They contain one entry per line, lines starting with # are comments. In metakeywordsgroups.txt and
+// synonymgroups.txt every line is a comma separated group. The lists are only changed if all packs are valid.
+func LoadDirectory(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("could not read dictionary directory (%w)", err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	lists := currentPack()
+	loaded := 0
+	for _, name := range names {
+		pack, err := readPack(filepath.Join(dir, name))
+		if err != nil {
+			return 0, fmt.Errorf("could not load dictionary pack %s (%w)", name, err)
+		}
+		if pack == nil {
+			continue
+		}
+		if err := pack.validate(); err != nil {
+			return 0, fmt.Errorf("invalid dictionary pack %s (%w)", name, err)
+		}
+		lists.apply(pack)
+		loaded++
+	}
+	if err := lists.validateNotEmpty(); err != nil {
+		return 0, err
+	}
+	lists.assign()
+
+	return loaded, nil
+}
+
+// readPack reads a pack file, it returns nil for files that are not packs.
+func readPack(path string) (*Pack, error) {
+	name := filepath.Base(path)
+	extension := strings.ToLower(filepath.Ext(name))
+	switch extension {
+	case ".yaml", ".yml", ".json":
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		pack := &Pack{}
+		if extension == ".json" {
+			decoder := json.NewDecoder(bytes.NewReader(content))
+			decoder.DisallowUnknownFields()
+			err = decoder.Decode(pack)
+		} else {
+			decoder := yaml.NewDecoder(bytes.NewReader(content))
+			decoder.KnownFields(true)
+			err = decoder.Decode(pack)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if pack.Mode == "" {
+			pack.Mode = PackModeExtend
+		}
+
+		return pack, nil
+	case ".txt":
+		return readTextPack(path)
+	default:
+		return nil, nil
+	}
+}
+
+// readTextPack reads a plain text file that extends or replaces the list it is named after.
+func readTextPack(path string) (*Pack, error) {
+	list := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	pack := &Pack{Mode: PackModeExtend}
+	if trimmed, ok := strings.CutSuffix(list, "."+PackModeReplace); ok {
+		list = trimmed
+		pack.Mode = PackModeReplace
+	}
+	lines, err := ReadLines(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(list)) {
+	case "prompts":
+		pack.Prompts = lines
+	case "articletypes":
+		pack.ArticleTypes = lines
+	case "languages":
+		pack.Languages = lines
+	case "nouns":
+		pack.Nouns = lines
+	case "verbs":
+		pack.Verbs = lines
+	case "cities":
+		pack.Cities = lines
+	case "metakeywordsgroups":
+		pack.MetaKeywordsGroups = splitGroups(lines)
+	case "newspapernames":
+		pack.NewsPaperNames = lines
+	case "synonymgroups":
+		pack.SynonymGroups = splitGroups(lines)
+	default:
+		return nil, fmt.Errorf("unknown dictionary %q", list)
+	}
+
+	return pack, nil
+}
+
+// splitGroups splits every line into a group of comma separated entries.
+func splitGroups(lines []string) [][]string {
+	groups := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		group := []string{}
+		for _, entry := range strings.Split(line, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				group = append(group, entry)
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// ReadLines reads the non-empty lines of a file, lines starting with # are comments and skipped.
+func ReadLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() //nolint: errcheck
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// validate checks the mode and the prompts of the pack.
+func (p *Pack) validate() error {
+	if p.Mode != PackModeExtend && p.Mode != PackModeReplace {
+		return fmt.Errorf("unknown mode %q", p.Mode)
+	}
+	for _, prompt := range p.Prompts {
+		if err := ValidatePrompt(prompt); err != nil {
+			return err
+		}
+	}
+	for _, group := range p.MetaKeywordsGroups {
+		if len(group) == 0 {
+			return errors.New("meta keywords group is empty")
+		}
+	}
+	for _, group := range p.SynonymGroups {
+		if len(group) < 2 {
+			return fmt.Errorf("synonym group %q must contain at least two words", group)
+		}
+		for _, word := range group {
+			if strings.ContainsFunc(word, unicode.IsSpace) {
+				return fmt.Errorf("synonym %q must be a single word", word)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidatePrompt checks that the prompt contains exactly the verbs %s, %s, %d and %s in this order.
+func ValidatePrompt(prompt string) error {
+	verbs := []rune{}
+	runes := []rune(prompt)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '%' {
+			continue
+		}
+		if i+1 == len(runes) {
+			return fmt.Errorf("prompt %q ends with a single %%", prompt)
+		}
+		i++
+		if runes[i] != '%' {
+			verbs = append(verbs, runes[i])
+		}
+	}
+	if !slices.Equal(verbs, promptVerbs) {
+		return fmt.Errorf("prompt %q must contain the verbs %%s, %%s, %%d and %%s in this order", prompt)
+	}
+
+	return nil
+}
+
+// currentPack returns a copy of the current lists.
+func currentPack() *Pack {
+	return &Pack{
+		Prompts:            slices.Clone(Prompts),
+		ArticleTypes:       slices.Clone(ArticleTypes),
+		Languages:          slices.Clone(Languages),
+		Nouns:              slices.Clone(Nouns),
+		Verbs:              slices.Clone(Verbs),
+		Cities:             slices.Clone(Cities),
+		MetaKeywordsGroups: slices.Clone(MetaKeywordsGroups),
+		NewsPaperNames:     slices.Clone(NewsPaperNames),
+		SynonymGroups:      slices.Clone(SynonymGroups),
+		Vocabularies:       cloneVocabularies(Vocabularies),
+	}
+}
+
+// cloneVocabularies returns a deep copy of the vocabularies.
+func cloneVocabularies(vocabularies map[string]Vocabulary) map[string]Vocabulary {
+	clone := make(map[string]Vocabulary, len(vocabularies))
+	for language, vocabulary := range vocabularies {
+		clone[language] = Vocabulary{
+			Nouns:            slices.Clone(vocabulary.Nouns),
+			Verbs:            slices.Clone(vocabulary.Verbs),
+			HeadlineStarters: slices.Clone(vocabulary.HeadlineStarters),
+		}
+	}
+
+	return clone
+}
+
+// apply extends or replaces the lists with the lists that are set in the pack.
+func (p *Pack) apply(pack *Pack) {
+	merge := func(list, entries []string) []string {
+		switch {
+		case entries == nil:
+			return list
+		case pack.Mode == PackModeReplace:
+			return slices.Clone(entries)
+		default:
+			return append(list, entries...)
+		}
+	}
+	p.Prompts = merge(p.Prompts, pack.Prompts)
+	p.ArticleTypes = merge(p.ArticleTypes, pack.ArticleTypes)
+	p.Languages = merge(p.Languages, pack.Languages)
+	p.Nouns = merge(p.Nouns, pack.Nouns)
+	p.Verbs = merge(p.Verbs, pack.Verbs)
+	p.Cities = merge(p.Cities, pack.Cities)
+	p.NewsPaperNames = merge(p.NewsPaperNames, pack.NewsPaperNames)
+	for language, vocabulary := range pack.Vocabularies {
+		current := p.Vocabularies[language]
+		p.Vocabularies[language] = Vocabulary{
+			Nouns:            merge(current.Nouns, vocabulary.Nouns),
+			Verbs:            merge(current.Verbs, vocabulary.Verbs),
+			HeadlineStarters: merge(current.HeadlineStarters, vocabulary.HeadlineStarters),
+		}
+	}
+	switch {
+	case pack.MetaKeywordsGroups == nil:
+	case pack.Mode == PackModeReplace:
+		p.MetaKeywordsGroups = slices.Clone(pack.MetaKeywordsGroups)
+	default:
+		p.MetaKeywordsGroups = append(p.MetaKeywordsGroups, pack.MetaKeywordsGroups...)
+	}
+	switch {
+	case pack.SynonymGroups == nil:
+	case pack.Mode == PackModeReplace:
+		p.SynonymGroups = slices.Clone(pack.SynonymGroups)
+	default:
+		p.SynonymGroups = append(p.SynonymGroups, pack.SynonymGroups...)
+	}
+}
+
+// validateNotEmpty checks that no list has been replaced with an empty list.
+func (p *Pack) validateNotEmpty() error {
+	for name, length := range map[string]int{
+		"prompts":            len(p.Prompts),
+		"articleTypes":       len(p.ArticleTypes),
+		"languages":          len(p.Languages),
+		"nouns":              len(p.Nouns),
+		"verbs":              len(p.Verbs),
+		"cities":             len(p.Cities),
+		"metaKeywordsGroups": len(p.MetaKeywordsGroups),
+		"newsPaperNames":     len(p.NewsPaperNames),
+	} {
+		if length == 0 {
+			return fmt.Errorf("dictionary %s must not be empty", name)
+		}
+	}
+
+	return nil
+}
+
+// assign replaces the lists of the package with the lists of the pack.
+func (p *Pack) assign() {
+	Prompts = p.Prompts
+	ArticleTypes = p.ArticleTypes
+	Languages = p.Languages
+	Nouns = p.Nouns
+	Verbs = p.Verbs
+	Cities = p.Cities
+	MetaKeywordsGroups = p.MetaKeywordsGroups
+	NewsPaperNames = p.NewsPaperNames
+	SynonymGroups = p.SynonymGroups
+	Vocabularies = p.Vocabularies
+}
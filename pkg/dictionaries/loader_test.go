@@ -0,0 +1,144 @@
+package dictionaries_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+
+	"codeberg.org/konterfai/konterfai/pkg/dictionaries"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadDirectory", func() {
+	var (
+		dir      string
+		prompts  []string
+		nouns    []string
+		cities   []string
+		groups   [][]string
+		synonyms [][]string
+		vocabs   map[string]dictionaries.Vocabulary
+	)
+
+	// writeFile writes a file to the dictionary directory.
+	writeFile := func(name, content string) {
+		Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		prompts = slices.Clone(dictionaries.Prompts)
+		nouns = slices.Clone(dictionaries.Nouns)
+		cities = slices.Clone(dictionaries.Cities)
+		groups = slices.Clone(dictionaries.MetaKeywordsGroups)
+		synonyms = slices.Clone(dictionaries.SynonymGroups)
+		vocabs = dictionaries.Vocabularies
+	})
+
+	AfterEach(func() {
+		dictionaries.Prompts = prompts
+		dictionaries.Nouns = nouns
+		dictionaries.Cities = cities
+		dictionaries.MetaKeywordsGroups = groups
+		dictionaries.SynonymGroups = synonyms
+		dictionaries.Vocabularies = vocabs
+	})
+
+	It("extends the lists with a yaml pack", func() {
+		writeFile("coffee.yaml", "nouns:\n  - espresso machine\nmetaKeywordsGroups:\n  - [coffee, barista]\n")
+		loaded, err := dictionaries.LoadDirectory(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(1))
+		Expect(dictionaries.Nouns).To(HaveLen(len(nouns) + 1))
+		Expect(dictionaries.Nouns).To(ContainElement("espresso machine"))
+		Expect(dictionaries.MetaKeywordsGroups).To(ContainElement([]string{"coffee", "barista"}))
+		Expect(dictionaries.Cities).To(Equal(cities))
+	})
+
+	It("extends the vocabularies with a yaml pack", func() {
+		writeFile("esperanto.yaml", "vocabularies:\n  Esperanto:\n    nouns: [domo, urbo]\n  German:\n    verbs: [brüht]\n")
+		_, err := dictionaries.LoadDirectory(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dictionaries.Vocabularies["Esperanto"].Nouns).To(Equal([]string{"domo", "urbo"}))
+		Expect(dictionaries.VocabularyFor("Esperanto").Verbs).To(Equal(dictionaries.Verbs))
+		Expect(dictionaries.Vocabularies["German"].Verbs).To(ContainElement("brüht"))
+		Expect(dictionaries.Vocabularies["German"].Nouns).To(Equal(vocabs["German"].Nouns))
+		Expect(vocabs["German"].Verbs).NotTo(ContainElement("brüht"))
+	})
+
+	It("replaces the lists with a json pack", func() {
+		writeFile("cities.json", `{"mode": "replace", "cities": ["Trieste", "Naples"]}`)
+		_, err := dictionaries.LoadDirectory(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dictionaries.Cities).To(Equal([]string{"Trieste", "Naples"}))
+	})
+
+	It("loads plain text packs in lexical order", func() {
+		writeFile("nouns.replace.txt", "# coffee nouns\nespresso\n\ngrinder\n")
+		writeFile("zz-extra.yaml", "nouns: [portafilter]\n")
+		writeFile("metaKeywordsGroups.txt", "coffee, barista\n")
+		writeFile("README.md", "not a pack")
+		loaded, err := dictionaries.LoadDirectory(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(3))
+		Expect(dictionaries.Nouns).To(Equal([]string{"espresso", "grinder", "portafilter"}))
+		Expect(dictionaries.MetaKeywordsGroups[len(dictionaries.MetaKeywordsGroups)-1]).To(
+			Equal([]string{"coffee", "barista"}))
+	})
+
+	It("loads synonym groups and rejects groups with a single word or phrases", func() {
+		writeFile("synonymGroups.replace.txt", "espresso, ristretto\n")
+		_, err := dictionaries.LoadDirectory(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dictionaries.SynonymGroups).To(Equal([][]string{{"espresso", "ristretto"}}))
+		writeFile("synonymGroups.replace.txt", "espresso\n")
+		_, err = dictionaries.LoadDirectory(dir)
+		Expect(err).To(MatchError(ContainSubstring("must contain at least two words")))
+		writeFile("synonymGroups.replace.txt", "espresso, short black\n")
+		_, err = dictionaries.LoadDirectory(dir)
+		Expect(err).To(MatchError(ContainSubstring(`synonym "short black" must be a single word`)))
+	})
+
+	It("rejects prompts without the required verbs and leaves the lists unchanged", func() {
+		writeFile("a.yaml", "nouns: [espresso]\n")
+		writeFile("b.yaml", "prompts:\n  - \"write me a %s about %s in %s\"\n")
+		_, err := dictionaries.LoadDirectory(dir)
+		Expect(err).To(MatchError(ContainSubstring("must contain the verbs %s, %s, %d and %s in this order")))
+		Expect(dictionaries.Nouns).To(Equal(nouns))
+		Expect(dictionaries.Prompts).To(Equal(prompts))
+	})
+
+	It("rejects unknown lists, unknown fields and empty lists", func() {
+		writeFile("animals.txt", "cat\n")
+		_, err := dictionaries.LoadDirectory(dir)
+		Expect(err).To(MatchError(ContainSubstring(`unknown dictionary "animals"`)))
+		Expect(os.Remove(filepath.Join(dir, "animals.txt"))).To(Succeed())
+
+		writeFile("pack.yaml", "animals: [cat]\n")
+		_, err = dictionaries.LoadDirectory(dir)
+		Expect(err).To(HaveOccurred())
+		Expect(os.Remove(filepath.Join(dir, "pack.yaml"))).To(Succeed())
+
+		writeFile("cities.replace.txt", "# nothing\n")
+		_, err = dictionaries.LoadDirectory(dir)
+		Expect(err).To(MatchError("dictionary cities must not be empty"))
+	})
+
+	Context("ValidatePrompt", func() {
+		It("accepts the built-in prompts", func() {
+			for _, prompt := range dictionaries.Prompts {
+				Expect(dictionaries.ValidatePrompt(prompt)).To(Succeed())
+			}
+		})
+
+		It("accepts literal percent signs", func() {
+			Expect(dictionaries.ValidatePrompt("a 100%% true %s about %s, %d words, in %s")).To(Succeed())
+		})
+
+		It("rejects verbs in the wrong order", func() {
+			Expect(dictionaries.ValidatePrompt("%s %d %s %s")).NotTo(Succeed())
+			Expect(dictionaries.ValidatePrompt("%s %s %d %s %")).NotTo(Succeed())
+		})
+	})
+})
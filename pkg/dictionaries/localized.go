@@ -0,0 +1,604 @@
+package dictionaries
+
+// Language is a language hallucinations are written in, with the metadata of pages in that language.
+// Scripts are the names of the unicode scripts the language is written in, see unicode.Scripts.
+type Language struct {
+	Name     string
+	Code     string
+	Charsets []string
+	Scripts  []string
+}
+
+// Vocabulary is the word lists of a language, used for headlines, topics and links.
+type Vocabulary struct {
+	Nouns            []string `json:"nouns"            yaml:"nouns"`
+	Verbs            []string `json:"verbs"            yaml:"verbs"`
+	HeadlineStarters []string `json:"headlineStarters" yaml:"headlineStarters"`
+}
+
+// LanguageMetadata maps the Languages used in prompts to their language code, their scripts and the charsets that
+// can encode them.
+var LanguageMetadata = map[string]Language{
+	"Arabic": {
+		Name: "Arabic", Code: "ar", Scripts: []string{"Arabic"},
+		Charsets: []string{"UTF-8", "ISO-8859-6", "windows-1256"},
+	},
+	"Bengali": {
+		Name: "Bengali", Code: "bn", Scripts: []string{"Bengali"},
+		Charsets: []string{"UTF-8"},
+	},
+	"Bulgarian": {
+		Name: "Bulgarian", Code: "bg", Scripts: []string{"Cyrillic"},
+		Charsets: []string{"UTF-8", "ISO-8859-5", "windows-1251"},
+	},
+	"Czech": {
+		Name: "Czech", Code: "cs", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-2", "windows-1250"},
+	},
+	"Dutch": {
+		Name: "Dutch", Code: "nl", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-1", "ISO-8859-15", "windows-1252"},
+	},
+	"English": {
+		Name: "English", Code: "en", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-1", "windows-1252"},
+	},
+	"French": {
+		Name: "French", Code: "fr", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-1", "ISO-8859-15", "windows-1252"},
+	},
+	"German": {
+		Name: "German", Code: "de", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-1", "ISO-8859-15", "windows-1252"},
+	},
+	"Greek": {
+		Name: "Greek", Code: "el", Scripts: []string{"Greek"},
+		Charsets: []string{"UTF-8", "ISO-8859-7", "windows-1253"},
+	},
+	"Hindi": {
+		Name: "Hindi", Code: "hi", Scripts: []string{"Devanagari"},
+		Charsets: []string{"UTF-8"},
+	},
+	"Hungarian": {
+		Name: "Hungarian", Code: "hu", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-2", "windows-1250"},
+	},
+	"Icelandic": {
+		Name: "Icelandic", Code: "is", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-1", "windows-1252"},
+	},
+	"Italian": {
+		Name: "Italian", Code: "it", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-1", "ISO-8859-15", "windows-1252"},
+	},
+	"Japanese": {
+		Name: "Japanese", Code: "ja", Scripts: []string{"Hiragana", "Katakana", "Han"},
+		Charsets: []string{"UTF-8", "Shift_JIS", "ISO-2022-JP"},
+	},
+	"Javanese": {
+		Name: "Javanese", Code: "jv", Scripts: []string{"Latin", "Javanese"},
+		Charsets: []string{"UTF-8"},
+	},
+	"Korean": {
+		Name: "Korean", Code: "ko", Scripts: []string{"Hangul", "Han"},
+		Charsets: []string{"UTF-8", "EUC-KR"},
+	},
+	"Mandarin": {
+		Name: "Mandarin", Code: "zh", Scripts: []string{"Han"},
+		Charsets: []string{"UTF-8", "GB2312"},
+	},
+	"Marathi": {
+		Name: "Marathi", Code: "mr", Scripts: []string{"Devanagari"},
+		Charsets: []string{"UTF-8"},
+	},
+	"Persian": {
+		Name: "Persian", Code: "fa", Scripts: []string{"Arabic"},
+		Charsets: []string{"UTF-8", "windows-1256"},
+	},
+	"Polish": {
+		Name: "Polish", Code: "pl", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-2", "windows-1250"},
+	},
+	"Portuguese": {
+		Name: "Portuguese", Code: "pt", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-1", "ISO-8859-15", "windows-1252"},
+	},
+	"Punjabi": {
+		Name: "Punjabi", Code: "pa", Scripts: []string{"Gurmukhi", "Arabic"},
+		Charsets: []string{"UTF-8"},
+	},
+	"Romanian": {
+		Name: "Romanian", Code: "ro", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-16", "windows-1250"},
+	},
+	"Russian": {
+		Name: "Russian", Code: "ru", Scripts: []string{"Cyrillic"},
+		Charsets: []string{"UTF-8", "ISO-8859-5", "windows-1251"},
+	},
+	"Spanish": {
+		Name: "Spanish", Code: "es", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-1", "ISO-8859-15", "windows-1252"},
+	},
+	"Swedish": {
+		Name: "Swedish", Code: "sv", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-1", "ISO-8859-15", "windows-1252"},
+	},
+	"Telugu": {
+		Name: "Telugu", Code: "te", Scripts: []string{"Telugu"},
+		Charsets: []string{"UTF-8"},
+	},
+	"Thai": {
+		Name: "Thai", Code: "th", Scripts: []string{"Thai"},
+		Charsets: []string{"UTF-8", "ISO-8859-11"},
+	},
+	"Turkish": {
+		Name: "Turkish", Code: "tr", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "ISO-8859-9", "windows-1254"},
+	},
+	"Ukrainian": {
+		Name: "Ukrainian", Code: "uk", Scripts: []string{"Cyrillic"},
+		Charsets: []string{"UTF-8", "windows-1251"},
+	},
+	"Vietnamese": {
+		Name: "Vietnamese", Code: "vi", Scripts: []string{"Latin"},
+		Charsets: []string{"UTF-8", "windows-1258"},
+	},
+}
+
+// Vocabularies are the localized word lists per language, every language of Languages but English has one.
+// English and the languages of dictionary packs without vocabulary use the default lists.
+var Vocabularies = map[string]Vocabulary{
+	"German": {
+		Nouns: []string{
+			"Bahnhof", "Bürgermeister", "Fahrrad", "Gemeinderat", "Haushalt", "Kindergarten", "Kirche", "Landwirt",
+			"Marktplatz", "Nachbar", "Rathaus", "Schule", "Sportverein", "Stadtpark", "Straßenbahn", "Verein",
+			"Wald", "Wetter", "Zeitung", "Brücke", "Bäckerei", "Feuerwehr", "Gericht", "Hafen", "Museum",
+		},
+		Verbs: []string{
+			"baut", "bespricht", "eröffnet", "erklärt", "feiert", "findet", "fordert", "kritisiert", "plant",
+			"rettet", "schließt", "sucht", "unterstützt", "verbietet", "verliert",
+		},
+		HeadlineStarters: []string{
+			"Eilmeldung", "Exklusiv", "Aktuell", "Neu", "Bericht", "Lokales", "Regional", "Unglaublich",
+		},
+	},
+	"French": {
+		Nouns: []string{
+			"boulangerie", "conseil", "école", "fromage", "gare", "hôpital", "jardin", "maire", "marché",
+			"musée", "pont", "port", "quartier", "théâtre", "tramway", "vélo", "village", "voisin", "forêt",
+			"église", "journal", "mairie", "pompier", "tribunal", "usine",
+		},
+		Verbs: []string{
+			"annonce", "construit", "critique", "découvre", "explique", "ferme", "fête", "inaugure", "interdit",
+			"perd", "prépare", "sauve", "soutient", "cherche", "propose",
+		},
+		HeadlineStarters: []string{
+			"Dernière minute", "Exclusif", "Actualité", "Nouveau", "Rapport", "Local", "Régional", "Incroyable",
+		},
+	},
+	"Spanish": {
+		Nouns: []string{
+			"alcalde", "ayuntamiento", "barrio", "biblioteca", "bicicleta", "colegio", "estación", "fiesta",
+			"hospital", "iglesia", "jardín", "mercado", "museo", "panadería", "parque", "periódico", "playa",
+			"plaza", "puente", "puerto", "tranvía", "vecino", "bosque", "bombero", "tribunal",
+		},
+		Verbs: []string{
+			"anuncia", "busca", "celebra", "cierra", "construye", "critica", "descubre", "explica", "inaugura",
+			"pierde", "planea", "prohíbe", "propone", "rescata", "apoya",
+		},
+		HeadlineStarters: []string{
+			"Última hora", "Exclusiva", "Actualidad", "Nuevo", "Informe", "Local", "Regional", "Increíble",
+		},
+	},
+	"Italian": {
+		Nouns: []string{
+			"biblioteca", "bicicletta", "chiesa", "comune", "mercato", "museo", "ospedale", "panetteria",
+			"parco", "piazza", "ponte", "porto", "quartiere", "scuola", "sindaco", "stazione", "teatro",
+			"tram", "vicino", "villaggio", "bosco", "giornale", "pompiere", "tribunale", "fabbrica",
+		},
+		Verbs: []string{
+			"annuncia", "cerca", "celebra", "chiude", "costruisce", "critica", "scopre", "spiega", "inaugura",
+			"perde", "prepara", "vieta", "propone", "salva", "sostiene",
+		},
+		HeadlineStarters: []string{
+			"Ultima ora", "Esclusiva", "Attualità", "Nuovo", "Rapporto", "Locale", "Regionale", "Incredibile",
+		},
+	},
+	"Dutch": {
+		Nouns: []string{
+			"bakkerij", "bibliotheek", "brug", "burgemeester", "buurman", "dorp", "fiets", "gemeente", "haven",
+			"kerk", "krant", "markt", "museum", "park", "plein", "school", "station", "stadhuis", "tram",
+			"wijk", "bos", "brandweer", "rechtbank", "fabriek", "ziekenhuis",
+		},
+		Verbs: []string{
+			"bouwt", "bespreekt", "opent", "verklaart", "viert", "vindt", "eist", "bekritiseert", "plant",
+			"redt", "sluit", "zoekt", "steunt", "verbiedt", "verliest",
+		},
+		HeadlineStarters: []string{
+			"Breaking", "Exclusief", "Actueel", "Nieuw", "Verslag", "Lokaal", "Regionaal", "Ongelooflijk",
+		},
+	},
+	"Portuguese": {
+		Nouns: []string{
+			"bairro", "biblioteca", "bicicleta", "câmara", "escola", "estação", "festa", "hospital", "igreja",
+			"jardim", "mercado", "museu", "padaria", "parque", "ponte", "porto", "praça", "prefeito",
+			"vizinho", "aldeia", "floresta", "jornal", "bombeiro", "tribunal", "fábrica",
+		},
+		Verbs: []string{
+			"anuncia", "procura", "celebra", "fecha", "constrói", "critica", "descobre", "explica", "inaugura",
+			"perde", "prepara", "proíbe", "propõe", "salva", "apoia",
+		},
+		HeadlineStarters: []string{
+			"Última hora", "Exclusivo", "Atualidade", "Novo", "Relatório", "Local", "Regional", "Incrível",
+		},
+	},
+	"Arabic": {
+		Nouns: []string{
+			"محطة", "عمدة", "دراجة", "مجلس", "مدرسة", "مستشفى", "سوق", "متحف", "جسر", "ميناء", "حديقة", "ساحة",
+			"مكتبة", "مسجد", "قرية", "جار", "صحيفة", "غابة", "إطفائي", "محكمة", "مصنع", "مخبز", "مسرح", "ترام",
+			"مهرجان",
+		},
+		Verbs: []string{
+			"يبني", "يناقش", "يفتتح", "يشرح", "يحتفل", "يجد", "يطالب", "ينتقد", "يخطط", "ينقذ", "يغلق", "يبحث",
+			"يدعم", "يمنع", "يخسر",
+		},
+		HeadlineStarters: []string{
+			"عاجل", "حصري", "آخر الأخبار", "جديد", "تقرير", "محلي", "إقليمي", "لا يصدق",
+		},
+	},
+	"Bengali": {
+		Nouns: []string{
+			"স্টেশন", "মেয়র", "সাইকেল", "পরিষদ", "বিদ্যালয়", "হাসপাতাল", "বাজার", "জাদুঘর", "সেতু", "বন্দর",
+			"উদ্যান", "চত্বর", "গ্রন্থাগার", "মন্দির", "গ্রাম", "প্রতিবেশী", "সংবাদপত্র", "বন", "দমকলকর্মী",
+			"আদালত", "কারখানা", "বেকারি", "নাট্যশালা", "ট্রাম", "উৎসব",
+		},
+		Verbs: []string{
+			"নির্মাণ করে", "আলোচনা করে", "উদ্বোধন করে", "ব্যাখ্যা করে", "উদযাপন করে", "খুঁজে পায়", "দাবি করে",
+			"সমালোচনা করে", "পরিকল্পনা করে", "উদ্ধার করে", "বন্ধ করে", "খোঁজে", "সমর্থন করে", "নিষিদ্ধ করে",
+			"হারায়",
+		},
+		HeadlineStarters: []string{
+			"ব্রেকিং", "একচেটিয়া", "সাম্প্রতিক", "নতুন", "প্রতিবেদন", "স্থানীয়", "আঞ্চলিক", "অবিশ্বাস্য",
+		},
+	},
+	"Bulgarian": {
+		Nouns: []string{
+			"гара", "кмет", "велосипед", "съвет", "училище", "болница", "пазар", "музей", "мост", "пристанище",
+			"парк", "площад", "библиотека", "църква", "село", "съсед", "вестник", "гора", "пожарникар", "съд",
+			"фабрика", "пекарна", "театър", "трамвай", "празник",
+		},
+		Verbs: []string{
+			"строи", "обсъжда", "открива", "обяснява", "празнува", "намира", "изисква", "критикува", "планира",
+			"спасява", "затваря", "търси", "подкрепя", "забранява", "губи",
+		},
+		HeadlineStarters: []string{
+			"Извънредно", "Ексклузивно", "Актуално", "Ново", "Репортаж", "Местни", "Регионални", "Невероятно",
+		},
+	},
+	"Czech": {
+		Nouns: []string{
+			"nádraží", "starosta", "kolo", "zastupitelstvo", "škola", "nemocnice", "trh", "muzeum", "most",
+			"přístav", "park", "náměstí", "knihovna", "kostel", "vesnice", "soused", "noviny", "les", "hasič",
+			"soud", "továrna", "pekárna", "divadlo", "tramvaj", "slavnost",
+		},
+		Verbs: []string{
+			"staví", "projednává", "otevírá", "vysvětluje", "slaví", "nachází", "požaduje", "kritizuje",
+			"plánuje", "zachraňuje", "zavírá", "hledá", "podporuje", "zakazuje", "ztrácí",
+		},
+		HeadlineStarters: []string{
+			"Mimořádně", "Exkluzivně", "Aktuálně", "Nové", "Zpráva", "Místní", "Regionální", "Neuvěřitelné",
+		},
+	},
+	"Greek": {
+		Nouns: []string{
+			"σταθμός", "δήμαρχος", "ποδήλατο", "συμβούλιο", "σχολείο", "νοσοκομείο", "αγορά", "μουσείο",
+			"γέφυρα", "λιμάνι", "πάρκο", "πλατεία", "βιβλιοθήκη", "εκκλησία", "χωριό", "γείτονας", "εφημερίδα",
+			"δάσος", "πυροσβέστης", "δικαστήριο", "εργοστάσιο", "φούρνος", "θέατρο", "τραμ", "γιορτή",
+		},
+		Verbs: []string{
+			"χτίζει", "συζητά", "εγκαινιάζει", "εξηγεί", "γιορτάζει", "βρίσκει", "απαιτεί", "επικρίνει",
+			"σχεδιάζει", "σώζει", "κλείνει", "ψάχνει", "στηρίζει", "απαγορεύει", "χάνει",
+		},
+		HeadlineStarters: []string{
+			"Έκτακτο", "Αποκλειστικό", "Επικαιρότητα", "Νέο", "Ρεπορτάζ", "Τοπικά", "Περιφερειακά", "Απίστευτο",
+		},
+	},
+	"Hindi": {
+		Nouns: []string{
+			"स्टेशन", "महापौर", "साइकिल", "परिषद", "विद्यालय", "अस्पताल", "बाज़ार", "संग्रहालय", "पुल",
+			"बंदरगाह", "उद्यान", "चौक", "पुस्तकालय", "मंदिर", "गाँव", "पड़ोसी", "अख़बार", "जंगल", "दमकलकर्मी",
+			"अदालत", "कारख़ाना", "बेकरी", "रंगमंच", "ट्राम", "मेला",
+		},
+		Verbs: []string{
+			"बनाता है", "चर्चा करता है", "खोलता है", "समझाता है", "मनाता है", "ढूँढता है", "माँग करता है",
+			"आलोचना करता है", "योजना बनाता है", "बचाता है", "बंद करता है", "खोजता है", "समर्थन करता है",
+			"रोक लगाता है", "हारता है",
+		},
+		HeadlineStarters: []string{
+			"ताज़ा ख़बर", "विशेष", "समाचार", "नया", "रिपोर्ट", "स्थानीय", "क्षेत्रीय", "अविश्वसनीय",
+		},
+	},
+	"Hungarian": {
+		Nouns: []string{
+			"pályaudvar", "polgármester", "kerékpár", "képviselő-testület", "iskola", "kórház", "piac",
+			"múzeum", "híd", "kikötő", "park", "tér", "könyvtár", "templom", "falu", "szomszéd", "újság",
+			"erdő", "tűzoltó", "bíróság", "gyár", "pékség", "színház", "villamos", "fesztivál",
+		},
+		Verbs: []string{
+			"épít", "megvitat", "megnyit", "elmagyaráz", "ünnepel", "talál", "követel", "bírál", "tervez",
+			"megment", "bezár", "keres", "támogat", "betilt", "elveszít",
+		},
+		HeadlineStarters: []string{
+			"Rendkívüli", "Exkluzív", "Aktuális", "Új", "Riport", "Helyi", "Regionális", "Hihetetlen",
+		},
+	},
+	"Icelandic": {
+		Nouns: []string{
+			"stöð", "bæjarstjóri", "reiðhjól", "bæjarráð", "skóli", "sjúkrahús", "markaður", "safn", "brú",
+			"höfn", "garður", "torg", "bókasafn", "kirkja", "þorp", "nágranni", "dagblað", "skógur",
+			"slökkviliðsmaður", "dómstóll", "verksmiðja", "bakarí", "leikhús", "sporvagn", "hátíð",
+		},
+		Verbs: []string{
+			"byggir", "ræðir", "opnar", "útskýrir", "fagnar", "finnur", "krefst", "gagnrýnir", "skipuleggur",
+			"bjargar", "lokar", "leitar", "styður", "bannar", "tapar",
+		},
+		HeadlineStarters: []string{
+			"Nýjustu fréttir", "Einkafrétt", "Fréttir", "Nýtt", "Skýrsla", "Innlent", "Landsbyggðin",
+			"Ótrúlegt",
+		},
+	},
+	"Japanese": {
+		Nouns: []string{
+			"駅", "市長", "自転車", "議会", "学校", "病院", "市場", "博物館", "橋", "港", "公園", "広場", "図書館", "神社", "村", "隣人", "新聞",
+			"森", "消防士", "裁判所", "工場", "パン屋", "劇場", "路面電車", "祭り",
+		},
+		Verbs: []string{
+			"建設する", "議論する", "開く", "説明する", "祝う", "見つける", "要求する", "批判する", "計画する", "救う", "閉鎖する", "探す", "支援する",
+			"禁止する", "失う",
+		},
+		HeadlineStarters: []string{
+			"速報", "独占", "最新", "新着", "特集", "地域", "地方", "驚き",
+		},
+	},
+	"Javanese": {
+		Nouns: []string{
+			"stasiun", "bupati", "sepédha", "dhéwan", "sekolah", "rumah sakit", "pasar", "musium", "kreteg",
+			"pelabuhan", "taman", "alun-alun", "perpustakaan", "masjid", "désa", "tangga", "koran", "alas",
+			"pemadam", "pengadilan", "pabrik", "toko roti", "gedhung", "trem", "pahargyan",
+		},
+		Verbs: []string{
+			"mbangun", "ngrembug", "mbukak", "nerangaké", "mèngeti", "nemokaké", "njaluk", "nyacad",
+			"ngrancang", "nylametaké", "nutup", "nggolèki", "nyengkuyung", "nglarang", "kélangan",
+		},
+		HeadlineStarters: []string{
+			"Kabar anyar", "Eksklusif", "Aktual", "Anyar", "Laporan", "Lokal", "Regional", "Nggumunaké",
+		},
+	},
+	"Korean": {
+		Nouns: []string{
+			"역", "시장", "자전거", "의회", "학교", "병원", "재래시장", "박물관", "다리", "항구", "공원", "광장", "도서관", "교회", "마을", "이웃",
+			"신문", "숲", "소방관", "법원", "공장", "빵집", "극장", "전차", "축제",
+		},
+		Verbs: []string{
+			"짓다", "논의하다", "개장하다", "설명하다", "축하하다", "발견하다", "요구하다", "비판하다", "계획하다", "구하다", "폐쇄하다", "찾다", "지원하다",
+			"금지하다", "잃다",
+		},
+		HeadlineStarters: []string{
+			"속보", "단독", "시사", "새소식", "보도", "지역", "지방", "놀라운",
+		},
+	},
+	"Mandarin": {
+		Nouns: []string{
+			"车站", "市长", "自行车", "议会", "学校", "医院", "市场", "博物馆", "桥", "港口", "公园", "广场", "图书馆", "寺庙", "村庄", "邻居",
+			"报纸", "森林", "消防员", "法院", "工厂", "面包店", "剧院", "电车", "节日",
+		},
+		Verbs: []string{
+			"建造", "讨论", "开放", "解释", "庆祝", "发现", "要求", "批评", "计划", "拯救", "关闭", "寻找", "支持", "禁止", "失去",
+		},
+		HeadlineStarters: []string{
+			"突发", "独家", "时事", "最新", "报道", "本地", "地区", "难以置信",
+		},
+	},
+	"Marathi": {
+		Nouns: []string{
+			"स्थानक", "महापौर", "सायकल", "परिषद", "शाळा", "रुग्णालय", "बाजार", "संग्रहालय", "पूल", "बंदर",
+			"उद्यान", "चौक", "ग्रंथालय", "मंदिर", "गाव", "शेजारी", "वृत्तपत्र", "जंगल", "अग्निशामक", "न्यायालय",
+			"कारखाना", "बेकरी", "नाट्यगृह", "ट्राम", "उत्सव",
+		},
+		Verbs: []string{
+			"बांधतो", "चर्चा करतो", "उघडतो", "स्पष्ट करतो", "साजरा करतो", "शोधून काढतो", "मागणी करतो",
+			"टीका करतो", "योजना आखतो", "वाचवतो", "बंद करतो", "शोधतो", "पाठिंबा देतो", "बंदी घालतो", "गमावतो",
+		},
+		HeadlineStarters: []string{
+			"ताजी बातमी", "विशेष", "चालू घडामोडी", "नवीन", "अहवाल", "स्थानिक", "प्रादेशिक", "अविश्वसनीय",
+		},
+	},
+	"Persian": {
+		Nouns: []string{
+			"ایستگاه", "شهردار", "دوچرخه", "شورا", "مدرسه", "بیمارستان", "بازار", "موزه", "پل", "بندر", "پارک",
+			"میدان", "کتابخانه", "مسجد", "روستا", "همسایه", "روزنامه", "جنگل", "آتش‌نشان", "دادگاه", "کارخانه",
+			"نانوایی", "تئاتر", "تراموا", "جشنواره",
+		},
+		Verbs: []string{
+			"می‌سازد", "بررسی می‌کند", "افتتاح می‌کند", "توضیح می‌دهد", "جشن می‌گیرد", "پیدا می‌کند",
+			"مطالبه می‌کند", "انتقاد می‌کند", "برنامه‌ریزی می‌کند", "نجات می‌دهد", "می‌بندد", "جستجو می‌کند",
+			"حمایت می‌کند", "ممنوع می‌کند", "از دست می‌دهد",
+		},
+		HeadlineStarters: []string{
+			"فوری", "اختصاصی", "اخبار روز", "جدید", "گزارش", "محلی", "منطقه‌ای", "باورنکردنی",
+		},
+	},
+	"Polish": {
+		Nouns: []string{
+			"dworzec", "burmistrz", "rower", "rada", "szkoła", "szpital", "rynek", "muzeum", "most", "port",
+			"park", "plac", "biblioteka", "kościół", "wieś", "sąsiad", "gazeta", "las", "strażak", "sąd",
+			"fabryka", "piekarnia", "teatr", "tramwaj", "festyn",
+		},
+		Verbs: []string{
+			"buduje", "omawia", "otwiera", "wyjaśnia", "świętuje", "znajduje", "żąda", "krytykuje", "planuje",
+			"ratuje", "zamyka", "szuka", "wspiera", "zakazuje", "traci",
+		},
+		HeadlineStarters: []string{
+			"Pilne", "Na wyłączność", "Aktualności", "Nowość", "Raport", "Lokalnie", "Z regionu",
+			"Niewiarygodne",
+		},
+	},
+	"Punjabi": {
+		Nouns: []string{
+			"ਸਟੇਸ਼ਨ", "ਮੇਅਰ", "ਸਾਈਕਲ", "ਪਰਿਸ਼ਦ", "ਸਕੂਲ", "ਹਸਪਤਾਲ", "ਬਾਜ਼ਾਰ", "ਅਜਾਇਬਘਰ", "ਪੁਲ", "ਬੰਦਰਗਾਹ",
+			"ਬਾਗ਼", "ਚੌਕ", "ਲਾਇਬ੍ਰੇਰੀ", "ਗੁਰਦੁਆਰਾ", "ਪਿੰਡ", "ਗੁਆਂਢੀ", "ਅਖ਼ਬਾਰ", "ਜੰਗਲ", "ਫਾਇਰਮੈਨ", "ਅਦਾਲਤ",
+			"ਕਾਰਖਾਨਾ", "ਬੇਕਰੀ", "ਥੀਏਟਰ", "ਟਰਾਮ", "ਮੇਲਾ",
+		},
+		Verbs: []string{
+			"ਬਣਾਉਂਦਾ ਹੈ", "ਚਰਚਾ ਕਰਦਾ ਹੈ", "ਖੋਲ੍ਹਦਾ ਹੈ", "ਸਮਝਾਉਂਦਾ ਹੈ", "ਮਨਾਉਂਦਾ ਹੈ", "ਲੱਭਦਾ ਹੈ", "ਮੰਗ ਕਰਦਾ ਹੈ",
+			"ਆਲੋਚਨਾ ਕਰਦਾ ਹੈ", "ਯੋਜਨਾ ਬਣਾਉਂਦਾ ਹੈ", "ਬਚਾਉਂਦਾ ਹੈ", "ਬੰਦ ਕਰਦਾ ਹੈ", "ਖੋਜਦਾ ਹੈ", "ਸਮਰਥਨ ਕਰਦਾ ਹੈ",
+			"ਪਾਬੰਦੀ ਲਾਉਂਦਾ ਹੈ", "ਹਾਰਦਾ ਹੈ",
+		},
+		HeadlineStarters: []string{
+			"ਤਾਜ਼ਾ ਖ਼ਬਰ", "ਵਿਸ਼ੇਸ਼", "ਖ਼ਬਰਾਂ", "ਨਵਾਂ", "ਰਿਪੋਰਟ", "ਸਥਾਨਕ", "ਖੇਤਰੀ", "ਹੈਰਾਨੀਜਨਕ",
+		},
+	},
+	"Romanian": {
+		Nouns: []string{
+			"gară", "primar", "bicicletă", "consiliu", "școală", "spital", "piață", "muzeu", "pod", "port",
+			"parc", "bibliotecă", "biserică", "sat", "vecin", "ziar", "pădure", "pompier", "tribunal",
+			"fabrică", "brutărie", "teatru", "tramvai", "festival", "primărie",
+		},
+		Verbs: []string{
+			"construiește", "discută", "deschide", "explică", "sărbătorește", "găsește", "cere", "critică",
+			"plănuiește", "salvează", "închide", "caută", "susține", "interzice", "pierde",
+		},
+		HeadlineStarters: []string{
+			"Ultima oră", "Exclusiv", "Actualitate", "Nou", "Reportaj", "Local", "Regional", "Incredibil",
+		},
+	},
+	"Russian": {
+		Nouns: []string{
+			"вокзал", "мэр", "велосипед", "совет", "школа", "больница", "рынок", "музей", "мост", "порт",
+			"парк", "площадь", "библиотека", "церковь", "деревня", "сосед", "газета", "лес", "пожарный", "суд",
+			"завод", "пекарня", "театр", "трамвай", "праздник",
+		},
+		Verbs: []string{
+			"строит", "обсуждает", "открывает", "объясняет", "празднует", "находит", "требует", "критикует",
+			"планирует", "спасает", "закрывает", "ищет", "поддерживает", "запрещает", "теряет",
+		},
+		HeadlineStarters: []string{
+			"Срочно", "Эксклюзив", "Актуально", "Новое", "Репортаж", "Местные новости", "Регион", "Невероятно",
+		},
+	},
+	"Swedish": {
+		Nouns: []string{
+			"järnvägsstation", "kommunalråd", "cykel", "kommunfullmäktige", "skola", "sjukhus", "torg",
+			"museum", "bro", "hamn", "park", "bibliotek", "kyrka", "by", "granne", "tidning", "skog",
+			"brandman", "domstol", "fabrik", "bageri", "teater", "spårvagn", "festival", "stadshus",
+		},
+		Verbs: []string{
+			"bygger", "diskuterar", "öppnar", "förklarar", "firar", "hittar", "kräver", "kritiserar",
+			"planerar", "räddar", "stänger", "söker", "stöder", "förbjuder", "förlorar",
+		},
+		HeadlineStarters: []string{
+			"Just nu", "Exklusivt", "Aktuellt", "Nytt", "Reportage", "Lokalt", "Regionalt", "Otroligt",
+		},
+	},
+	"Telugu": {
+		Nouns: []string{
+			"స్టేషన్", "మేయర్", "సైకిల్", "మండలి", "పాఠశాల", "ఆసుపత్రి", "మార్కెట్", "మ్యూజియం", "వంతెన",
+			"ఓడరేవు", "ఉద్యానవనం", "కూడలి", "గ్రంథాలయం", "దేవాలయం", "గ్రామం", "పొరుగువారు", "వార్తాపత్రిక",
+			"అడవి", "అగ్నిమాపకుడు", "న్యాయస్థానం", "కర్మాగారం", "బేకరీ", "నాటకశాల", "ట్రామ్", "పండుగ",
+		},
+		Verbs: []string{
+			"నిర్మిస్తుంది", "చర్చిస్తుంది", "ప్రారంభిస్తుంది", "వివరిస్తుంది", "జరుపుకుంటుంది", "కనుగొంటుంది",
+			"డిమాండ్ చేస్తుంది", "విమర్శిస్తుంది", "ప్రణాళిక వేస్తుంది", "కాపాడుతుంది", "మూసివేస్తుంది",
+			"వెతుకుతుంది", "మద్దతు ఇస్తుంది", "నిషేధిస్తుంది", "కోల్పోతుంది",
+		},
+		HeadlineStarters: []string{
+			"తాజా వార్త", "ప్రత్యేకం", "వర్తమానం", "కొత్త", "నివేదిక", "స్థానికం", "ప్రాంతీయం", "అద్భుతం",
+		},
+	},
+	"Thai": {
+		Nouns: []string{
+			"สถานีรถไฟ", "นายกเทศมนตรี", "จักรยาน", "สภา", "โรงเรียน", "โรงพยาบาล", "ตลาด", "พิพิธภัณฑ์",
+			"สะพาน", "ท่าเรือ", "สวนสาธารณะ", "ลานเมือง", "ห้องสมุด", "วัด", "หมู่บ้าน", "เพื่อนบ้าน",
+			"หนังสือพิมพ์", "ป่า", "นักดับเพลิง", "ศาล", "โรงงาน", "ร้านขนมปัง", "โรงละคร", "รถราง", "เทศกาล",
+		},
+		Verbs: []string{
+			"สร้าง", "หารือ", "เปิด", "อธิบาย", "ฉลอง", "พบ", "เรียกร้อง", "วิจารณ์", "วางแผน", "ช่วยเหลือ",
+			"ปิด", "ค้นหา", "สนับสนุน", "ห้าม", "สูญเสีย",
+		},
+		HeadlineStarters: []string{
+			"ข่าวด่วน", "พิเศษ", "ข่าวล่าสุด", "ใหม่", "รายงาน", "ท้องถิ่น", "ภูมิภาค", "เหลือเชื่อ",
+		},
+	},
+	"Turkish": {
+		Nouns: []string{
+			"istasyon", "belediye başkanı", "bisiklet", "meclis", "okul", "hastane", "pazar", "müze", "köprü",
+			"liman", "park", "meydan", "kütüphane", "cami", "köy", "komşu", "gazete", "orman", "itfaiyeci",
+			"mahkeme", "fabrika", "fırın", "tiyatro", "tramvay", "festival",
+		},
+		Verbs: []string{
+			"inşa ediyor", "tartışıyor", "açıyor", "açıklıyor", "kutluyor", "buluyor", "talep ediyor",
+			"eleştiriyor", "planlıyor", "kurtarıyor", "kapatıyor", "arıyor", "destekliyor", "yasaklıyor",
+			"kaybediyor",
+		},
+		HeadlineStarters: []string{
+			"Son dakika", "Özel", "Gündem", "Yeni", "Rapor", "Yerel", "Bölgesel", "İnanılmaz",
+		},
+	},
+	"Ukrainian": {
+		Nouns: []string{
+			"вокзал", "мер", "велосипед", "рада", "школа", "лікарня", "ринок", "музей", "міст", "порт", "парк",
+			"площа", "бібліотека", "церква", "село", "сусід", "газета", "ліс", "пожежник", "суд", "завод",
+			"пекарня", "театр", "трамвай", "свято",
+		},
+		Verbs: []string{
+			"будує", "обговорює", "відкриває", "пояснює", "святкує", "знаходить", "вимагає", "критикує",
+			"планує", "рятує", "закриває", "шукає", "підтримує", "забороняє", "втрачає",
+		},
+		HeadlineStarters: []string{
+			"Терміново", "Ексклюзив", "Актуально", "Нове", "Репортаж", "Місцеві новини", "Регіон", "Неймовірно",
+		},
+	},
+	"Vietnamese": {
+		Nouns: []string{
+			"nhà ga", "thị trưởng", "xe đạp", "hội đồng", "trường học", "bệnh viện", "chợ", "bảo tàng",
+			"cây cầu", "bến cảng", "công viên", "quảng trường", "thư viện", "ngôi chùa", "ngôi làng",
+			"hàng xóm", "tờ báo", "khu rừng", "lính cứu hỏa", "tòa án", "nhà máy", "tiệm bánh", "nhà hát",
+			"xe điện", "lễ hội",
+		},
+		Verbs: []string{
+			"xây dựng", "thảo luận", "khai trương", "giải thích", "kỷ niệm", "tìm thấy", "yêu cầu", "chỉ trích",
+			"lên kế hoạch", "giải cứu", "đóng cửa", "tìm kiếm", "ủng hộ", "cấm", "mất",
+		},
+		HeadlineStarters: []string{
+			"Tin nóng", "Độc quyền", "Thời sự", "Mới", "Phóng sự", "Địa phương", "Khu vực",
+			"Không thể tin được",
+		},
+	},
+}
+
+// LookupLanguage returns the metadata of the language. Unknown languages, e.g. from dictionary packs, have no
+// language code and are encoded in UTF-8.
+func LookupLanguage(name string) Language {
+	if language, ok := LanguageMetadata[name]; ok {
+		return language
+	}
+
+	return Language{Name: name, Code: "", Charsets: []string{"UTF-8"}}
+}
+
+// VocabularyFor returns the vocabulary of the language. Lists that are missing in the vocabulary of the language,
+// or the whole vocabulary of languages without one, fall back to the default lists.
+func VocabularyFor(name string) Vocabulary {
+	vocabulary := Vocabularies[name]
+	if len(vocabulary.Nouns) == 0 {
+		vocabulary.Nouns = Nouns
+	}
+	if len(vocabulary.Verbs) == 0 {
+		vocabulary.Verbs = Verbs
+	}
+	if len(vocabulary.HeadlineStarters) == 0 {
+		vocabulary.HeadlineStarters = HeadlineStarters
+	}
+
+	return vocabulary
+}
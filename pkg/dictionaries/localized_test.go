@@ -0,0 +1,47 @@
+package dictionaries_test
+
+import (
+	"codeberg.org/konterfai/konterfai/pkg/dictionaries"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Localized dictionaries", func() {
+	It("has metadata for every language used in prompts", func() {
+		for _, language := range dictionaries.Languages {
+			metadata := dictionaries.LookupLanguage(language)
+			Expect(metadata.Code).NotTo(BeEmpty(), language)
+			Expect(metadata.Charsets).To(ContainElement("UTF-8"), language)
+		}
+	})
+
+	It("has a vocabulary for every language used in prompts but English", func() {
+		for _, language := range dictionaries.Languages {
+			if language == "English" {
+				continue
+			}
+			vocabulary, ok := dictionaries.Vocabularies[language]
+			Expect(ok).To(BeTrue(), language)
+			Expect(vocabulary.Nouns).NotTo(BeEmpty(), language)
+			Expect(vocabulary.Verbs).NotTo(BeEmpty(), language)
+			Expect(vocabulary.HeadlineStarters).NotTo(BeEmpty(), language)
+		}
+	})
+
+	It("encodes unknown languages in UTF-8 without language code", func() {
+		Expect(dictionaries.LookupLanguage("Klingon")).To(Equal(dictionaries.Language{
+			Name: "Klingon", Code: "", Charsets: []string{"UTF-8"},
+		}))
+	})
+
+	It("returns the vocabulary of the language", func() {
+		Expect(dictionaries.VocabularyFor("German")).To(Equal(dictionaries.Vocabularies["German"]))
+	})
+
+	It("falls back to the default lists for languages without vocabulary", func() {
+		vocabulary := dictionaries.VocabularyFor("Klingon")
+		Expect(vocabulary.Nouns).To(Equal(dictionaries.Nouns))
+		Expect(vocabulary.Verbs).To(Equal(dictionaries.Verbs))
+		Expect(vocabulary.HeadlineStarters).To(Equal(dictionaries.HeadlineStarters))
+	})
+})
@@ -0,0 +1,29 @@
+package dictionaries
+
+// Stopwords are frequent words per language, they tell languages apart that are written in the same script.
+var Stopwords = map[string][]string{
+	"Dutch": {
+		"de", "het", "een", "en", "van", "niet", "zijn", "op", "voor", "met", "ook", "maar", "dat", "wordt", "bij",
+	},
+	"English": {
+		"the", "and", "of", "to", "is", "was", "that", "with", "for", "this", "are", "from", "have", "which", "not",
+	},
+	"French": {
+		"le", "la", "les", "et", "des", "est", "une", "dans", "pour", "que", "qui", "sur", "pas", "avec", "du",
+	},
+	"German": {
+		"der", "die", "und", "das", "ist", "nicht", "ein", "eine", "mit", "auf", "den", "sich", "auch", "wird", "von",
+	},
+	"Italian": {
+		"il", "di", "che", "della", "è", "per", "una", "sono", "gli", "con", "non", "nel", "anche", "alla", "delle",
+	},
+	"Portuguese": {
+		"o", "os", "do", "da", "que", "não", "uma", "com", "para", "em", "são", "mais", "foi", "pelo", "dos",
+	},
+	"Spanish": {
+		"el", "los", "del", "las", "que", "es", "una", "por", "con", "para", "está", "pero", "más", "fue", "muy",
+	},
+	"Swedish": {
+		"och", "att", "det", "som", "är", "en", "på", "för", "med", "inte", "av", "till", "har", "den", "var",
+	},
+}
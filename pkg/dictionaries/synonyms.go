@@ -0,0 +1,87 @@
+package dictionaries
+
+// SynonymGroups are groups of interchangeable words, the remix engine swaps a word for another word of its group.
+// The words of a group are single words of the same language and word class, a word belongs to one group only.
+var SynonymGroups = [][]string{
+	// English
+	{"big", "large", "huge", "massive"},
+	{"small", "little", "tiny", "minor"},
+	{"fast", "quick", "rapid", "swift"},
+	{"slow", "gradual", "sluggish"},
+	{"new", "novel", "fresh", "recent"},
+	{"old", "former", "earlier", "previous"},
+	{"important", "significant", "crucial", "essential"},
+	{"difficult", "hard", "challenging", "demanding"},
+	{"easy", "simple", "straightforward"},
+	{"good", "fine", "solid", "positive"},
+	{"bad", "poor", "negative", "troubling"},
+	{"many", "numerous", "countless", "several"},
+	{"often", "frequently", "regularly", "repeatedly"},
+	{"also", "additionally", "furthermore", "moreover"},
+	{"however", "nevertheless", "nonetheless"},
+	{"said", "stated", "declared", "explained", "noted"},
+	{"says", "states", "declares", "explains", "notes"},
+	{"showed", "revealed", "demonstrated", "indicated"},
+	{"shows", "reveals", "demonstrates", "indicates"},
+	{"began", "started", "launched", "initiated"},
+	{"begin", "start", "launch", "initiate"},
+	{"help", "assist", "support", "aid"},
+	{"use", "employ", "utilize", "apply"},
+	{"make", "create", "produce", "build"},
+	{"made", "created", "produced", "built"},
+	{"get", "obtain", "receive", "acquire"},
+	{"think", "believe", "consider", "assume"},
+	{"increase", "rise", "growth", "surge"},
+	{"decrease", "decline", "drop", "reduction"},
+	{"city", "town", "municipality"},
+	{"company", "firm", "business", "enterprise"},
+	{"people", "residents", "citizens", "locals"},
+	{"problem", "issue", "difficulty", "concern"},
+	{"plan", "proposal", "scheme", "project"},
+	{"result", "outcome", "consequence", "effect"},
+	{"report", "account", "statement"},
+	{"expert", "specialist", "analyst", "authority"},
+	{"government", "administration", "authorities"},
+	{"research", "study", "investigation", "analysis"},
+	{"area", "region", "district", "zone"},
+	{"event", "occasion", "gathering", "happening"},
+	{"method", "approach", "technique", "procedure"},
+	{"goal", "aim", "objective", "target"},
+	{"quickly", "rapidly", "swiftly", "promptly"},
+	{"recently", "lately", "newly"},
+	{"currently", "presently", "now"},
+	{"completely", "entirely", "fully", "totally"},
+	{"very", "extremely", "highly", "remarkably"},
+	// German
+	{"groß", "riesig", "gewaltig"},
+	{"klein", "winzig", "gering"},
+	{"schnell", "rasch", "zügig"},
+	{"wichtig", "bedeutend", "wesentlich", "entscheidend"},
+	{"sagte", "erklärte", "betonte", "meinte"},
+	{"stadt", "gemeinde", "kommune"},
+	{"unternehmen", "firma", "betrieb"},
+	{"schwierigkeit", "herausforderung", "hürde"},
+	{"auch", "ebenfalls", "zudem", "außerdem"},
+	{"jedoch", "allerdings", "dennoch", "trotzdem"},
+	{"oft", "häufig", "regelmäßig"},
+	// French
+	{"grand", "énorme", "immense", "vaste"},
+	{"petit", "minuscule", "modeste"},
+	{"rapide", "prompt", "vif"},
+	{"essentiel", "majeur", "primordial"},
+	{"ville", "commune", "municipalité"},
+	{"entreprise", "société", "firme"},
+	{"aussi", "également"},
+	{"cependant", "pourtant", "néanmoins", "toutefois"},
+	{"souvent", "fréquemment", "régulièrement"},
+	// Spanish
+	{"grande", "enorme", "inmenso", "amplio"},
+	{"pequeño", "diminuto", "reducido"},
+	{"rápido", "veloz", "ágil"},
+	{"importante", "esencial", "fundamental"},
+	{"dijo", "afirmó", "declaró", "explicó"},
+	{"ciudad", "localidad", "municipio"},
+	{"empresa", "compañía", "sociedad"},
+	{"también", "además", "asimismo"},
+	{"frecuentemente", "habitualmente", "asiduamente"},
+}
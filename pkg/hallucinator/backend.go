@@ -0,0 +1,118 @@
+package hallucinator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTPClient is an interface for the http.Client, it is shared by all backends that talk to a remote service.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Backend is the interface for the text-generation backends of the Hallucinator.
+type Backend interface {
+	// Name returns the name of the backend, it is used in logs and error messages.
+	Name() string
+	// Generate generates a text for the given request.
+	Generate(ctx context.Context, client HTTPClient, request GenerationRequest) (string, error)
+}
+
+// StreamingBackend is implemented by backends that can deliver the text while it is generated.
+type StreamingBackend interface {
+	Backend
+	// GenerateStream generates a text for the given request, calls onToken for every received token
+	// and returns the complete text.
+	GenerateStream(ctx context.Context, client HTTPClient, request GenerationRequest,
+		onToken func(token string)) (string, error)
+}
+
+// Bootstrapper is implemented by backends that check and prepare the remote service before the first generation.
+type Bootstrapper interface {
+	// Bootstrap checks the remote service and prepares the model, the returned state is valid even on errors.
+	Bootstrap(ctx context.Context, client HTTPClient) (BackendState, error)
+}
+
+// HealthChecker is a Backend with several endpoints that are probed periodically by the Hallucinator.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context, client HTTPClient)
+}
+
+// LanguageRestricted is implemented by backends that can only write some languages, like the markov backend.
+// The language of their prompts is picked from these languages instead of all languages.
+type LanguageRestricted interface {
+	// Languages returns the languages the backend writes in.
+	Languages() []string
+}
+
+// BackendState is the state of a backend as found by Bootstrap.
+type BackendState struct {
+	Reachable    bool
+	ModelPresent bool
+}
+
+// GenerationRequest is the backend independent request for a single generation.
+// WordCount and MinimalLength are only honoured by backends that do not follow the prompt, like the markov backend.
+// Model overrides the configured model of the backend, if it is set.
+type GenerationRequest struct {
+	Prompt        string
+	Model         string
+	SystemPrompt  string
+	WordCount     int
+	MinimalLength int
+	Options       GenerationOptions
+}
+
+// GenerationOptions are the tuning options for a single generation.
+// Options that are nil are not sent to the backend, so the backend default is used.
+type GenerationOptions struct {
+	Temperature   float64
+	Seed          int
+	TopP          *float64
+	TopK          *int
+	MinP          *float64
+	RepeatPenalty *float64
+	NumPredict    *int
+	NumCtx        *int
+	Stop          []string
+}
+
+// checkStreamResponse checks a streamed backend response for the common failure cases before it is read.
+func checkStreamResponse(name string, res *http.Response) error {
+	if res.StatusCode != http.StatusOK {
+		if res.Body != nil {
+			res.Body.Close() //nolint: errcheck,gosec
+		}
+
+		return fmt.Errorf("%s did not return 200 OK", name)
+	}
+	if res.Body == nil {
+		return fmt.Errorf("%s did not return a body", name)
+	}
+
+	return nil
+}
+
+// readResponseBody reads the body of a backend response and checks it for the common failure cases.
+func readResponseBody(name string, res *http.Response) ([]byte, error) {
+	if res.Body != nil {
+		defer res.Body.Close() //nolint: errcheck
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s did not return 200 OK", name)
+	}
+	if res.Body == nil {
+		return nil, fmt.Errorf("%s did not return a body", name)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		return nil, fmt.Errorf("%s did return an empty body", name)
+	}
+
+	return body, nil
+}
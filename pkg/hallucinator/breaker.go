@@ -0,0 +1,164 @@
+package hallucinator
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned instead of calling the backend while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all calls pass to the backend.
+	CircuitClosed CircuitState = iota
+	// CircuitHalfOpen lets a single probe call pass to the backend.
+	CircuitHalfOpen
+	// CircuitOpen rejects all calls until the open timeout has passed.
+	CircuitOpen
+)
+
+// String returns the name of the circuit state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker protects the backend from being called while it is failing.
+// After FailureThreshold consecutive failures the circuit opens. After the open timeout a single probe is let through
+// (half-open): if it succeeds the circuit closes, otherwise it opens again with an exponentially growing timeout.
+// A FailureThreshold below 1 disables the circuit breaker.
+type CircuitBreaker struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	MaxBackoff       time.Duration
+
+	state         CircuitState
+	failures      int
+	opened        int
+	openUntil     time.Time
+	probeInFlight bool
+	lock          sync.Mutex
+}
+
+// NewCircuitBreaker creates a new, closed CircuitBreaker instance.
+func NewCircuitBreaker(failureThreshold int, openTimeout, maxBackoff time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		OpenTimeout:      openTimeout,
+		MaxBackoff:       maxBackoff,
+		state:            CircuitClosed,
+	}
+}
+
+// Allow checks if a call to the backend may pass. An open circuit becomes half-open once the open timeout has passed.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	if cb.FailureThreshold < 1 {
+		return true
+	}
+	switch cb.state {
+	case CircuitOpen:
+		if time.Now().Before(cb.openUntil) {
+			return false
+		}
+		cb.state = CircuitHalfOpen
+		cb.probeInFlight = true
+
+		return true
+	case CircuitHalfOpen:
+		if cb.probeInFlight {
+			return false
+		}
+		cb.probeInFlight = true
+
+		return true
+	default:
+		return true
+	}
+}
+
+// Success records a successful call and closes the circuit.
+func (cb *CircuitBreaker) Success() {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.state = CircuitClosed
+	cb.failures = 0
+	cb.opened = 0
+	cb.probeInFlight = false
+}
+
+// Failure records a failed call and opens the circuit if needed.
+func (cb *CircuitBreaker) Failure() {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	if cb.FailureThreshold < 1 {
+		return
+	}
+	if cb.state == CircuitOpen {
+		// A call that has been allowed before the circuit opened does not extend the open timeout.
+		return
+	}
+	cb.failures++
+	cb.probeInFlight = false
+	if cb.state == CircuitHalfOpen || cb.failures >= cb.FailureThreshold {
+		cb.state = CircuitOpen
+		cb.openUntil = time.Now().Add(exponentialBackoff(cb.OpenTimeout, cb.MaxBackoff, cb.opened))
+		cb.opened++
+	}
+}
+
+// State returns the current state of the circuit.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	return cb.state
+}
+
+// RetryIn returns the time until an open circuit lets the next probe pass, it is 0 if the circuit is not open.
+func (cb *CircuitBreaker) RetryIn() time.Duration {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	if cb.state != CircuitOpen {
+		return 0
+	}
+
+	return max(time.Until(cb.openUntil), 0)
+}
+
+// exponentialBackoff returns the backoff for the given attempt (starting at 0), doubling base for every attempt up to
+// maxBackoff. The result is jittered to a random duration between the half and the full backoff, so workers and
+// instances do not retry in lockstep.
+func exponentialBackoff(base, maxBackoff time.Duration, attempt int) time.Duration {
+	backoff := max(base, time.Millisecond)
+	for range attempt {
+		if backoff >= maxBackoff {
+			break
+		}
+		backoff *= 2
+	}
+	if maxBackoff > 0 {
+		backoff = min(backoff, max(maxBackoff, base))
+	}
+	half := backoff / 2
+
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1)) //nolint: gosec
+}
@@ -0,0 +1,85 @@
+package hallucinator_test
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"codeberg.org/konterfai/konterfai/pkg/command"
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CircuitBreaker", func() {
+	var cb *hallucinator.CircuitBreaker
+
+	BeforeEach(func() {
+		cb = hallucinator.NewCircuitBreaker(2, 20*time.Millisecond, time.Second)
+	})
+
+	It("opens after the failure threshold has been reached", func() {
+		Expect(cb.Allow()).To(BeTrue())
+		cb.Failure()
+		Expect(cb.State()).To(Equal(hallucinator.CircuitClosed))
+		cb.Failure()
+		Expect(cb.State()).To(Equal(hallucinator.CircuitOpen))
+		Expect(cb.Allow()).To(BeFalse())
+		Expect(cb.RetryIn()).To(BeNumerically(">", 0))
+	})
+
+	It("resets the failures after a success", func() {
+		cb.Failure()
+		cb.Success()
+		cb.Failure()
+		Expect(cb.State()).To(Equal(hallucinator.CircuitClosed))
+	})
+
+	It("lets a single probe pass after the open timeout and closes on success", func() {
+		cb.Failure()
+		cb.Failure()
+		Eventually(cb.Allow).Should(BeTrue())
+		Expect(cb.State()).To(Equal(hallucinator.CircuitHalfOpen))
+		Expect(cb.Allow()).To(BeFalse())
+		cb.Success()
+		Expect(cb.State()).To(Equal(hallucinator.CircuitClosed))
+		Expect(cb.Allow()).To(BeTrue())
+	})
+
+	It("opens again if the probe fails", func() {
+		cb.Failure()
+		cb.Failure()
+		Eventually(cb.Allow).Should(BeTrue())
+		cb.Failure()
+		Expect(cb.State()).To(Equal(hallucinator.CircuitOpen))
+		Expect(cb.Allow()).To(BeFalse())
+	})
+
+	It("never opens if it is disabled", func() {
+		cb.FailureThreshold = 0
+		for range 10 {
+			cb.Failure()
+		}
+		Expect(cb.State()).To(Equal(hallucinator.CircuitClosed))
+		Expect(cb.Allow()).To(BeTrue())
+	})
+
+	It("stops calling a failing backend", func() {
+		ctx := context.Background()
+		logger, _ := command.SetLogger("off", "")
+		st := statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+		backend := &countingBackend{failures: 100}
+		h := hallucinator.NewHallucinator(ctx, logger, 5, 10, 10, 10, 100, 10, 10, 10, 10, 10,
+			url.URL{Scheme: "http", Host: "localhost:8080"}, backend, 1, 10, 10, st)
+		h.CircuitBreaker = hallucinator.NewCircuitBreaker(2, time.Minute, time.Minute)
+		for range 5 {
+			_, err := h.GenerateHallucination(ctx)
+			Expect(err).To(HaveOccurred())
+		}
+		_, err := h.GenerateHallucination(ctx)
+		Expect(err).To(MatchError(hallucinator.ErrCircuitOpen))
+		Expect(backend.calls.Load()).To(Equal(int32(2)))
+		Expect(st.GetBackendStatus(ctx).CircuitState).To(Equal("open"))
+	})
+})
@@ -0,0 +1,120 @@
+package hallucinator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// cacheStoreFilePattern is the pattern for the file names of the persisted hallucinations.
+const cacheStoreFilePattern = "hallucination-%06d.json"
+
+// CacheStore persists the hallucination cache as a directory of JSON files, one file per hallucination.
+type CacheStore struct {
+	Directory string
+}
+
+// NewCacheStore creates a new CacheStore instance.
+func NewCacheStore(directory string) *CacheStore {
+	return &CacheStore{
+		Directory: directory,
+	}
+}
+
+// Load loads the persisted hallucinations, hallucinations without remaining requests are skipped.
+// A missing directory is not an error, it results in an empty cache.
+func (cs *CacheStore) Load(ctx context.Context) ([]Hallucination, error) {
+	_, span := tracer.Start(ctx, "CacheStore.Load")
+	defer span.End()
+
+	files, err := cs.files()
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []Hallucination{}, nil
+		}
+
+		return nil, err
+	}
+	hallucinations := []Hallucination{}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		hallucination := Hallucination{}
+		if err := json.Unmarshal(content, &hallucination); err != nil {
+			return nil, fmt.Errorf("could not parse %s (%w)", file, err)
+		}
+		if hallucination.RequestCount < 1 || hallucination.Text == "" {
+			continue
+		}
+		hallucinations = append(hallucinations, hallucination)
+	}
+
+	return hallucinations, nil
+}
+
+// Save replaces the persisted hallucinations with the given ones.
+// Every file is written to a temporary file first and renamed afterwards, so a crash never leaves a partial file.
+func (cs *CacheStore) Save(ctx context.Context, hallucinations []Hallucination) error {
+	_, span := tracer.Start(ctx, "CacheStore.Save")
+	defer span.End()
+
+	if err := os.MkdirAll(cs.Directory, 0o750); err != nil {
+		return err
+	}
+	stale, err := cs.files()
+	if err != nil {
+		return err
+	}
+	written := map[string]bool{}
+	for idx, hallucination := range hallucinations {
+		content, err := json.Marshal(hallucination)
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(cs.Directory, fmt.Sprintf(cacheStoreFilePattern, idx))
+		if err := os.WriteFile(file+".tmp", content, 0o600); err != nil {
+			return err
+		}
+		if err := os.Rename(file+".tmp", file); err != nil {
+			return err
+		}
+		written[file] = true
+	}
+	for _, file := range stale {
+		if written[file] {
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// files returns the sorted list of the persisted hallucination files.
+func (cs *CacheStore) files() ([]string, error) {
+	entries, err := os.ReadDir(cs.Directory)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "hallucination-") ||
+			filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		files = append(files, filepath.Join(cs.Directory, entry.Name()))
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
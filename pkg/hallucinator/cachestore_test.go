@@ -0,0 +1,106 @@
+package hallucinator_test
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"codeberg.org/konterfai/konterfai/pkg/command"
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CacheStore", func() {
+	var (
+		ctx   context.Context
+		dir   string
+		store *hallucinator.CacheStore
+		hals  []hallucinator.Hallucination
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = filepath.Join(GinkgoT().TempDir(), "cache")
+		store = hallucinator.NewCacheStore(dir)
+		hals = []hallucinator.Hallucination{
+			{Text: "first hallucination", Prompt: "first prompt", RequestCount: 3},
+			{Text: "second hallucination", Prompt: "second prompt", RequestCount: 1},
+		}
+	})
+
+	It("returns an empty cache if the directory does not exist", func() {
+		loaded, err := store.Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(BeEmpty())
+	})
+
+	It("keeps the remaining request count of every hallucination", func() {
+		Expect(store.Save(ctx, hals)).To(Succeed())
+		loaded, err := store.Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(hals))
+	})
+
+	It("skips hallucinations without remaining requests", func() {
+		hals[0].RequestCount = 0
+		Expect(store.Save(ctx, hals)).To(Succeed())
+		loaded, err := store.Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(hals[1:]))
+	})
+
+	It("removes stale files when the cache shrinks", func() {
+		Expect(store.Save(ctx, hals)).To(Succeed())
+		Expect(store.Save(ctx, hals[1:])).To(Succeed())
+		files, err := os.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+		loaded, err := store.Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(hals[1:]))
+	})
+
+	It("returns an error for a malformed file", func() {
+		Expect(os.MkdirAll(dir, 0o750)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "hallucination-000000.json"), []byte("{"), 0o600)).To(Succeed())
+		_, err := store.Load(ctx)
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("with the hallucinator", func() {
+		var (
+			h            *hallucinator.Hallucinator
+			cancelledCtx context.Context
+		)
+
+		BeforeEach(func() {
+			logger, _ := command.SetLogger("off", "")
+			st := statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+			h = hallucinator.NewHallucinator(ctx, logger, 5, 10, 10, 10, 500, 10, 10, 10, 10, 10,
+				url.URL{Scheme: "http", Host: "localhost:8080"}, &fakeBackend{}, 1, 10, 10, st)
+			h.CacheStore = store
+			var cancel context.CancelFunc
+			cancelledCtx, cancel = context.WithCancel(ctx)
+			cancel()
+		})
+
+		It("loads the cache at start", func() {
+			Expect(store.Save(ctx, hals)).To(Succeed())
+			Expect(h.Start(cancelledCtx)).To(Succeed())
+			Expect(h.GetHallucinationCount(ctx)).To(Equal(2))
+		})
+
+		It("saves the cache on shutdown", func() {
+			for _, hal := range hals {
+				h.AppendHallucination(ctx, hal)
+			}
+			Expect(h.Start(cancelledCtx)).To(Succeed())
+			loaded, err := store.Load(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded).To(Equal(hals))
+		})
+	})
+})
@@ -1,6 +1,10 @@
 package hallucinator
 
-import "go.opentelemetry.io/otel"
+import (
+	"time"
+
+	"go.opentelemetry.io/otel"
+)
 
 const (
 	BackToStartString = "Back to start."
@@ -9,7 +13,29 @@ const (
 	DreamString       = "We are sorry, but the requested article could be not found!"
 )
 
-// invalidResultsRegexps is a list of regular expressions that are used to filter out invalid results.
+// worldPromptFormat is appended to the prompt with the facts of the world the hallucination is about.
+const worldPromptFormat = " Stay consistent with these facts: %s"
+
+// worldTopicCount is the number of random topics that link to entities of the world.
+const worldTopicCount = 5
+
+// defaultLanguage is the language of pages without hallucination and of hallucinations without language.
+const defaultLanguage = "English"
+
+// These are the defaults for the circuit breaker of the backend, they are overwritten by the cli flags.
+const (
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+	defaultMaxBackoff       = 5 * time.Minute
+)
+
+// These are the defaults for the health checks of backends with several endpoints.
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	healthCheckTimeout         = 5 * time.Second
+)
+
+// invalidResultsRegexps is the list of built-in refusal patterns, they are matched case-insensitively.
 var invalidResultsRegexps = []string{
 	// These list has been put together from outputs of the qwen0.5b model
 	// It may be incomplete, but it is a good start.
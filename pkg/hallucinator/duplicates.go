@@ -0,0 +1,145 @@
+package hallucinator
+
+import (
+	"hash/fnv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// These are the defaults of the near-duplicate detection, they are overwritten by the cli flags.
+const (
+	// DefaultDuplicateThreshold is the similarity from which a hallucination is a near-duplicate.
+	DefaultDuplicateThreshold = 0.8
+	// DefaultDuplicateHistory is the number of accepted hallucinations that are remembered.
+	DefaultDuplicateHistory = 100
+	// signatureSize is the number of hash functions of a MinHash signature.
+	signatureSize = 64
+	// shingleLength is the number of words of a shingle.
+	shingleLength = 3
+)
+
+// signatureSeeds are the seeds of the hash functions of the MinHash signatures.
+var signatureSeeds = func() [signatureSize]uint64 {
+	seeds := [signatureSize]uint64{}
+	state := uint64(0x5eed)
+	for i := range seeds {
+		state = splitmix64(state)
+		seeds[i] = state
+	}
+
+	return seeds
+}()
+
+// Signature is the MinHash signature of the word shingles of a text.
+// The share of equal values of two signatures estimates the Jaccard similarity of their texts.
+type Signature []uint64
+
+// NewSignature computes the MinHash signature of the text.
+func NewSignature(text string) Signature {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	signature := make(Signature, signatureSize)
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+	// Texts shorter than a shingle are a single shingle.
+	for i := 0; i == 0 || i+shingleLength <= len(words); i++ {
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(strings.Join(words[i:min(i+shingleLength, len(words))], " ")))
+		shingle := hash.Sum64()
+		for j, seed := range signatureSeeds {
+			signature[j] = min(signature[j], splitmix64(shingle^seed))
+		}
+	}
+
+	return signature
+}
+
+// Similarity returns the estimated Jaccard similarity of the texts of the signatures, between 0 and 1.
+func (s Signature) Similarity(other Signature) float64 {
+	if len(s) == 0 || len(s) != len(other) {
+		return 0
+	}
+	equal := 0
+	for i := range s {
+		if s[i] == other[i] {
+			equal++
+		}
+	}
+
+	return float64(equal) / float64(len(s))
+}
+
+// Diversity returns 1 minus the average similarity of all pairs of signatures, 1 if there are less than two.
+func Diversity(signatures []Signature) float64 {
+	pairs, similarity := 0, 0.0
+	for i := range signatures {
+		for j := i + 1; j < len(signatures); j++ {
+			similarity += signatures[i].Similarity(signatures[j])
+			pairs++
+		}
+	}
+	if pairs == 0 {
+		return 1
+	}
+
+	return 1 - similarity/float64(pairs)
+}
+
+// DuplicateDetector rejects hallucinations that are near-duplicates of the cached hallucinations or of the
+// hallucinations that have been accepted recently.
+type DuplicateDetector struct {
+	Threshold float64
+	history   []Signature
+	next      int
+	lock      sync.Mutex
+}
+
+// NewDuplicateDetector creates a new DuplicateDetector that remembers the last historySize hallucinations.
+func NewDuplicateDetector(threshold float64, historySize int) *DuplicateDetector {
+	return &DuplicateDetector{
+		Threshold: threshold,
+		history:   make([]Signature, 0, max(historySize, 0)),
+	}
+}
+
+// MaxSimilarity returns the highest similarity of the signature to the cached signatures and the history.
+func (d *DuplicateDetector) MaxSimilarity(signature Signature, cached []Signature) float64 {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	highest := 0.0
+	for _, others := range [][]Signature{cached, d.history} {
+		for _, other := range others {
+			highest = max(highest, signature.Similarity(other))
+		}
+	}
+
+	return highest
+}
+
+// Remember adds the signature to the history, replacing the oldest signature if the history is full.
+func (d *DuplicateDetector) Remember(signature Signature) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	switch {
+	case cap(d.history) == 0:
+	case len(d.history) < cap(d.history):
+		d.history = append(d.history, signature)
+	default:
+		d.history[d.next] = signature
+		d.next = (d.next + 1) % len(d.history)
+	}
+}
+
+// splitmix64 is the finalizer of the SplitMix64 generator, it mixes the bits of x.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+
+	return x ^ (x >> 31)
+}
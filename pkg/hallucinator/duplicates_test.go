@@ -0,0 +1,155 @@
+package hallucinator_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"codeberg.org/konterfai/konterfai/pkg/command"
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Near-duplicate detection", func() {
+	const (
+		article = "The city council of Trieste met on Monday to discuss the new harbour. " +
+			"Several members criticised the costs, while the mayor praised the plans for a tram line along the coast. " +
+			"The construction is expected to start next spring and to last for about three years."
+		rewritten = "The city council of Trieste met on Monday to discuss the new harbour. " +
+			"Several members criticised the costs, while the mayor praised the plans for a tram line along the coast. " +
+			"The construction is expected to start next summer and to last for about three years."
+		other = "A bakery in Naples won the national pizza award for the third time in a row. " +
+			"The owner thanked her family and announced a new branch near the railway station."
+	)
+
+	Context("Signature", func() {
+		It("is identical for identical texts", func() {
+			Expect(hallucinator.NewSignature(article).Similarity(hallucinator.NewSignature(article))).To(Equal(1.0))
+		})
+
+		It("is similar for nearly identical texts", func() {
+			Expect(hallucinator.NewSignature(article).Similarity(hallucinator.NewSignature(rewritten))).
+				To(BeNumerically(">", 0.8))
+		})
+
+		It("is dissimilar for different texts", func() {
+			Expect(hallucinator.NewSignature(article).Similarity(hallucinator.NewSignature(other))).
+				To(BeNumerically("<", 0.2))
+		})
+
+		It("handles texts shorter than a shingle", func() {
+			Expect(hallucinator.NewSignature("two words").Similarity(hallucinator.NewSignature("Two words!"))).
+				To(Equal(1.0))
+		})
+	})
+
+	Context("Diversity", func() {
+		It("is 1 for less than two signatures", func() {
+			Expect(hallucinator.Diversity(nil)).To(Equal(1.0))
+		})
+
+		It("is low for nearly identical texts", func() {
+			Expect(hallucinator.Diversity([]hallucinator.Signature{
+				hallucinator.NewSignature(article), hallucinator.NewSignature(rewritten),
+			})).To(BeNumerically("<", 0.2))
+		})
+	})
+
+	Context("DuplicateDetector", func() {
+		It("remembers a rolling history", func() {
+			d := hallucinator.NewDuplicateDetector(0.8, 1)
+			d.Remember(hallucinator.NewSignature(article))
+			Expect(d.MaxSimilarity(hallucinator.NewSignature(rewritten), nil)).To(BeNumerically(">", 0.8))
+			d.Remember(hallucinator.NewSignature(other))
+			Expect(d.MaxSimilarity(hallucinator.NewSignature(rewritten), nil)).To(BeNumerically("<", 0.2))
+		})
+	})
+
+	Context("in the hallucinator", func() {
+		var (
+			ctx     context.Context
+			st      *statistics.Statistics
+			backend *fakeBackend
+			h       *hallucinator.Hallucinator
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			logger, _ := command.SetLogger("off", "")
+			st = statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+			backend = &fakeBackend{text: article}
+			h = hallucinator.NewHallucinator(ctx, logger, 5, 10, 10, 10, 0, 10, 10, 10, 10, 10,
+				url.URL{Scheme: "http", Host: "localhost:8080"}, backend, 1, 10, 10, st)
+			h.Duplicates = hallucinator.NewDuplicateDetector(hallucinator.DefaultDuplicateThreshold,
+				hallucinator.DefaultDuplicateHistory)
+		})
+
+		It("rejects near-duplicates of the cache", func() {
+			hal, err := h.GenerateHallucination(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(h.AppendHallucination(ctx, hal)).To(Succeed())
+			backend.text = rewritten
+			_, err = h.GenerateHallucination(ctx)
+			Expect(err).To(MatchError(HavePrefix("fake returned a near-duplicate hallucination")))
+			Expect(st.GetDiversity(ctx).Duplicates).To(Equal(1))
+			backend.text = other
+			_, err = h.GenerateHallucination(ctx)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects near-duplicates that are appended after the generation", func() {
+			hal, err := h.GenerateHallucination(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(h.AppendHallucination(ctx, hallucinator.Hallucination{Text: rewritten, RequestCount: 1})).
+				To(Succeed())
+			Expect(h.AppendHallucination(ctx, hal)).To(MatchError(HavePrefix("a near-duplicate hallucination")))
+			Expect(h.GetHallucinationCount(ctx)).To(Equal(1))
+			Expect(st.GetDiversity(ctx).Duplicates).To(Equal(1))
+		})
+
+		It("rejects near-duplicates of hallucinations that are no longer cached", func() {
+			Expect(h.AppendHallucination(ctx, hallucinator.Hallucination{Text: article, RequestCount: 1})).
+				To(Succeed())
+			Expect(h.PopHallucination(ctx)).To(ContainSubstring("Trieste"))
+			h.CleanHallucinations(ctx)
+			Expect(h.GetHallucinationCount(ctx)).To(Equal(0))
+			backend.text = rewritten
+			_, err := h.GenerateHallucination(ctx)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("records the diversity of the cache", func() {
+			// The near-duplicates are accepted, so the cache can become uniform.
+			h.Duplicates.Threshold = 1.1
+			for i := range 3 {
+				h.AppendHallucination(ctx, hallucinator.Hallucination{
+					Text: fmt.Sprintf("%s %d", other, i), RequestCount: 1,
+				})
+			}
+			Expect(st.GetDiversity(ctx).Score).To(BeNumerically("<", 0.2))
+			h.AppendHallucination(ctx, hallucinator.Hallucination{Text: article, RequestCount: 1})
+			Expect(st.GetDiversity(ctx).Score).To(BeNumerically(">", 0.4))
+		})
+
+		It("records the diversity of the cache after it has been cleaned", func() {
+			h.Duplicates.Threshold = 1.1
+			h.AppendHallucination(ctx, hallucinator.Hallucination{Text: article, RequestCount: 1})
+			for i := range 3 {
+				h.AppendHallucination(ctx, hallucinator.Hallucination{
+					Text: fmt.Sprintf("%s %d", other, i), RequestCount: 2,
+				})
+			}
+			Expect(st.GetDiversity(ctx).Score).To(BeNumerically(">", 0.4))
+			Expect(h.PopHallucination(ctx)).To(ContainSubstring("Trieste"))
+			h.CleanHallucinations(ctx)
+			Expect(h.GetHallucinationCount(ctx)).To(Equal(3))
+			signatures := []hallucinator.Signature{}
+			for i := range 3 {
+				signatures = append(signatures, hallucinator.NewSignature(fmt.Sprintf("%s %d", other, i)))
+			}
+			Expect(st.GetDiversity(ctx).Score).To(BeNumerically("~", hallucinator.Diversity(signatures), 1e-9))
+		})
+	})
+})
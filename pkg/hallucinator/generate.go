@@ -1,34 +1,30 @@
 package hallucinator
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
 	"math/rand"
-	"net/http"
-	"net/url"
-	"os"
-	"runtime"
-	"strings"
+	"path"
+	"time"
 
 	"codeberg.org/konterfai/konterfai/pkg/dictionaries"
 	"codeberg.org/konterfai/konterfai/pkg/helpers/functions"
 	"codeberg.org/konterfai/konterfai/pkg/helpers/links"
 	"codeberg.org/konterfai/konterfai/pkg/helpers/textblocks"
 	"codeberg.org/konterfai/konterfai/pkg/renderer"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	"codeberg.org/konterfai/konterfai/pkg/world"
 )
 
-// generateFollowUpLink returns a follow-up link for the Hallucinator.
-func (h *Hallucinator) generateFollowUpLink(ctx context.Context, continueText string) string {
+// generateFollowUpLink returns a follow-up link in the given language for the Hallucinator.
+func (h *Hallucinator) generateFollowUpLink(ctx context.Context, language, continueText string) string {
 	ctx, span := tracer.Start(ctx, "Hallucinator.generateFollowUpLink")
 	defer span.End()
 
 	return fmt.Sprintf("<br/><br/><a href=\"%s\">%s</a>",
-		links.RandomLink(
+		links.RandomLocalizedLink(
 			ctx,
+			language,
 			h.hallucinatorURL,
 			h.hallucinatorLinkMaxSubdirectories,
 			h.hallucinatorLinkMaxVariables,
@@ -38,128 +34,192 @@ func (h *Hallucinator) generateFollowUpLink(ctx context.Context, continueText st
 	)
 }
 
-// GenerateHallucination generates a hallucination from the Ollama API.
+// GenerateHallucination generates a hallucination using the configured backend.
 func (h *Hallucinator) GenerateHallucination(ctx context.Context) (Hallucination, error) {
 	ctx, span := tracer.Start(ctx, "Hallucinator.GenerateHallucination")
 	defer span.End()
-	requestURL, err := url.JoinPath(h.ollamaAddress, "/api/chat")
-	if err != nil {
-		h.Logger.ErrorContext(ctx, fmt.Sprintf("could not join url path (%v)", err))
-		defer os.Exit(1)
-		runtime.Goexit()
-	}
-	prompt := h.generatePrompt(ctx)
-	h.Logger.InfoContext(ctx, "generating hallucination with prompt:"+prompt)
-	requestBody := ollamaJSONRequest{
-		Model: h.ollamaModel, Messages: []OllamaMessage{{Role: "user", Content: prompt}},
-		Options: ollamaOptions{Temperature: h.aiTemperature, Seed: h.aiSeed},
-	}
-	requestBodyJSON, err := json.Marshal(requestBody)
-	if err != nil {
-		h.Logger.ErrorContext(ctx, fmt.Sprintf("could not marshal request body (%v)", err))
-		defer os.Exit(1)
-		runtime.Goexit()
-	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewReader(requestBodyJSON))
-	if err != nil {
-		h.Logger.ErrorContext(ctx, fmt.Sprintf("could not create request (%v)", err))
 
-		return Hallucination{}, err
-	}
-	res, err := h.HTTPClient.Do(req)
+	prompt, language, entities := h.generatePrompt(ctx)
+	model := pickWeightedModel(h.Models)
+	h.Logger.InfoContext(ctx, "generating hallucination with prompt:"+prompt)
+	text, err := h.generate(ctx, prompt, model, nil)
 	if err != nil {
-		h.Logger.ErrorContext(ctx, fmt.Sprintf("could not get hallucination from ollama (%v)", err))
-
 		return Hallucination{}, err
 	}
-	if res.StatusCode != http.StatusOK {
-		h.Logger.ErrorContext(ctx, "ollama did not return 200 OK")
-
-		return Hallucination{}, errors.New("ollama did not return 200 OK")
-	}
 
-	pl, err := h.validateBody(ctx, res.Body)
+	pl, err := h.validateBody(ctx, text, language)
 	if err != nil {
 		return Hallucination{}, err
 	}
 
-	if err := res.Body.Close(); err != nil {
-		h.Logger.ErrorContext(ctx, fmt.Sprintf("could not close response body (%v)", err))
-	}
-
-	return Hallucination{Text: pl, Prompt: prompt, RequestCount: h.hallucinationRequestCount}, nil
+	return Hallucination{
+		Text: pl, Prompt: prompt, RequestCount: h.hallucinationRequestCount, Model: model, Language: language,
+		Entities: entities,
+	}, nil
 }
 
-// validateBody checks if the hallucination is valid.
-func (h *Hallucinator) validateBody(ctx context.Context, body io.ReadCloser) (string, error) {
-	_, span := tracer.Start(ctx, "Hallucinator.validateBody")
+// generate calls the backend through the circuit breaker and records the backend state.
+// If onToken is set, the text is streamed to it, backends that cannot stream deliver the whole text as one token.
+// An empty model uses the configured model of the backend.
+func (h *Hallucinator) generate(ctx context.Context, prompt, model string, onToken func(token string),
+) (string, error) {
+	ctx, span := tracer.Start(ctx, "Hallucinator.generate")
 	defer span.End()
 
-	if body == nil {
-		h.Logger.ErrorContext(ctx, "ollama did not return a body")
+	if !h.CircuitBreaker.Allow() {
+		h.recordCircuitState(ctx)
 
-		return "", errors.New("ollama did not return a body")
+		return "", fmt.Errorf("%w for %s, retrying in %s", ErrCircuitOpen, h.backend.Name(),
+			h.CircuitBreaker.RetryIn().Round(time.Second))
+	}
+	request := GenerationRequest{
+		Prompt:        prompt,
+		Model:         model,
+		SystemPrompt:  h.SystemPrompt,
+		WordCount:     h.hallucinationWordCount,
+		MinimalLength: h.hallucinationMinimalLength,
+		Options:       h.OptionRanges.Pick(h.aiTemperature, h.aiSeed),
+	}
+	var (
+		text string
+		err  error
+	)
+	started := time.Now()
+	streamingBackend, canStream := h.backend.(StreamingBackend)
+	switch {
+	case onToken != nil && canStream:
+		text, err = streamingBackend.GenerateStream(ctx, h.HTTPClient, request, onToken)
+	case onToken != nil:
+		text, err = h.backend.Generate(ctx, h.HTTPClient, request)
+		if err == nil {
+			onToken(text)
+		}
+	default:
+		text, err = h.backend.Generate(ctx, h.HTTPClient, request)
 	}
-	resBody, err := io.ReadAll(body)
+	h.statistics.RecordModelGeneration(ctx, h.backend.Name(), model, time.Since(started), err == nil)
 	if err != nil {
-		h.Logger.ErrorContext(ctx, "could not read response body")
+		h.CircuitBreaker.Failure()
+		h.recordCircuitState(ctx)
+		// Repeated errors are only logged at debug level, the backend state is shown on the statistics page.
+		logError := h.Logger.DebugContext
+		if h.statistics.SetBackendError(ctx, h.backend.Name(), err) {
+			logError = h.Logger.ErrorContext
+		}
+		logError(ctx, fmt.Sprintf("could not get hallucination from %s (%v)", h.backend.Name(), err))
 
 		return "", err
 	}
-	if len(resBody) == 0 {
-		h.Logger.ErrorContext(ctx, "ollama did return an empty body")
+	h.CircuitBreaker.Success()
+	h.recordCircuitState(ctx)
+	h.statistics.SetBackendSuccess(ctx, h.backend.Name())
+
+	return text, nil
+}
+
+// recordCircuitState records the state of the circuit breaker in the statistics.
+func (h *Hallucinator) recordCircuitState(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "Hallucinator.recordCircuitState")
+	defer span.End()
+
+	state := h.CircuitBreaker.State()
+	h.statistics.SetBackendCircuitState(ctx, h.backend.Name(), state.String())
+	statistics.BackendCircuitState.WithLabelValues(h.backend.Name()).Set(float64(state))
+}
 
-		return "", errors.New("ollama did return an empty body")
+// validateBody checks if the hallucination in the given language is valid, rejections are counted per reason.
+func (h *Hallucinator) validateBody(ctx context.Context, text, language string) (string, error) {
+	ctx, span := tracer.Start(ctx, "Hallucinator.validateBody")
+	defer span.End()
+
+	name := h.backend.Name()
+	if text == "" {
+		statistics.HallucinationsRejectedTotal.WithLabelValues(name, "empty").Inc()
+		h.Logger.ErrorContext(ctx, name+" did return an empty hallucination")
+
+		return "", fmt.Errorf("%s did return an empty hallucination", name)
 	}
 
-	pl, err := concatOllamaMessages(resBody)
-	if err != nil {
-		h.Logger.ErrorContext(ctx, fmt.Sprintf("could not concatenate ollama messages (%v)", err))
+	if reason, err := h.Validators.Validate(ctx, text, language); err != nil {
+		statistics.HallucinationsRejectedTotal.WithLabelValues(name, reason).Inc()
+		h.Logger.ErrorContext(ctx, fmt.Sprintf("%s returned %v", name, err))
 
-		return "", err
+		return "", fmt.Errorf("%s returned %w", name, err)
 	}
 
-	if !h.isValidResult(ctx, pl) {
-		h.Logger.ErrorContext(ctx, "ollama returned an invalid hallucination")
+	if err := h.checkDuplicate(ctx, text); err != nil {
+		h.recordDuplicate(ctx)
+		h.Logger.ErrorContext(ctx, fmt.Sprintf("%s returned %v", name, err))
 
-		return "", errors.New("ollama returned an invalid hallucination")
+		return "", fmt.Errorf("%s returned %w", name, err)
 	}
 
-	if len(pl) < h.hallucinationMinimalLength {
-		h.Logger.ErrorContext(ctx, "ollama returned a hallucination that is too short")
+	return text, nil
+}
+
+// checkDuplicate returns an error if the text is a near-duplicate of a cached or a recent hallucination.
+// The check is repeated by AppendHallucination, another worker may cache a near-duplicate in the meantime.
+func (h *Hallucinator) checkDuplicate(ctx context.Context, text string) error {
+	ctx, span := tracer.Start(ctx, "Hallucinator.checkDuplicate")
+	defer span.End()
 
-		return "", errors.New("ollama returned a hallucination that is too short")
+	if h.Duplicates == nil {
+		return nil
 	}
+	h.hallucinationLock.Lock()
+	defer h.hallucinationLock.Unlock()
 
-	return pl, nil
+	return h.checkSignature(ctx, NewSignature(text))
 }
 
-// concatOllamaMessages concatenates Ollama messages.
-func concatOllamaMessages(responseBody []byte) (string, error) {
-	responses := strings.Split(string(responseBody), "\n")
-	var payload []string
-	for _, message := range responses {
-		m := OllamaResponse{}
-		if err := json.Unmarshal([]byte(message), &m); err != nil {
-			return "", err
-		}
-		if msg := strings.Trim(m.Message.Content, " "); msg != "" && msg != "\n" {
-			payload = append(payload, msg)
-		}
-		if m.Done {
-			break
-		}
+// checkSignature returns an error if the signature is similar to the signature of a cached or a recent hallucination.
+func (h *Hallucinator) checkSignature(ctx context.Context, signature Signature) error {
+	// This function does not have a lock on the hallucinations list. It is expected that the caller has locked the list.
+	// This happens in checkDuplicate and AppendHallucination.
+	_, span := tracer.Start(ctx, "Hallucinator.checkSignature")
+	defer span.End()
+
+	cached := make([]Signature, 0, len(h.hallucinations))
+	for _, hallucination := range h.hallucinations {
+		cached = append(cached, hallucination.signature)
+	}
+	similarity := h.Duplicates.MaxSimilarity(signature, cached)
+	if similarity >= h.Duplicates.Threshold {
+		return fmt.Errorf("a near-duplicate hallucination (%.0f%% similar)", similarity*100)
 	}
 
-	return strings.Join(payload, " "), nil
+	return nil
 }
 
-// generatePrompt generates a prompt for the Hallucinator.
-func (h *Hallucinator) generatePrompt(ctx context.Context) string {
+// recordDuplicate counts a hallucination that has been rejected as near-duplicate.
+func (h *Hallucinator) recordDuplicate(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "Hallucinator.recordDuplicate")
+	defer span.End()
+
+	statistics.HallucinationsRejectedTotal.WithLabelValues(h.backend.Name(), "duplicate").Inc()
+	h.statistics.RecordDuplicate(ctx)
+}
+
+// generatePrompt generates a prompt for the Hallucinator, it returns the prompt, the language of the reply and the
+// ids of the entities of the world the prompt is about. With a world, the topic starts with the names of a story of
+// the world and the prompt ends with its facts.
+func (h *Hallucinator) generatePrompt(ctx context.Context) (string, string, []int) {
 	ctx, span := tracer.Start(ctx, "Hallucinator.generatePrompt")
 	defer span.End()
 	words := ""
-	for range h.promptWordCount {
+	facts := ""
+	entities := []int{}
+	if h.World != nil {
+		story := h.World.Story(ctx)
+		for _, entity := range story {
+			words += entity.Name + " "
+			entities = append(entities, entity.ID)
+		}
+		if len(story) > 0 {
+			facts = fmt.Sprintf(worldPromptFormat, world.Facts(story))
+		}
+	}
+	for range h.promptWordCount - len(entities) {
 		rnd := rand.Intn(100) % 3 //nolint: gosec
 		switch rnd {
 		case 0:
@@ -173,23 +233,73 @@ func (h *Hallucinator) generatePrompt(ctx context.Context) string {
 		}
 	}
 
+	languages := h.languages()
+	language := functions.PickRandomStringFromSlice(ctx, &languages)
+
 	return fmt.Sprintf(functions.PickRandomStringFromSlice(ctx, &dictionaries.Prompts),
 		functions.PickRandomStringFromSlice(ctx, &dictionaries.ArticleTypes),
 		words,
 		h.hallucinationWordCount,
-		functions.PickRandomStringFromSlice(ctx, &dictionaries.Languages),
-	)
+		language,
+	) + facts, language, entities
 }
 
-// generateRandomTopicLinks generates random topic links.
-func (h *Hallucinator) generateRandomTopicLinks(ctx context.Context) []renderer.RandomTopic {
+// languages returns the languages the backend writes in, all languages unless the backend is restricted.
+func (h *Hallucinator) languages() []string {
+	if restricted, ok := h.backend.(LanguageRestricted); ok {
+		return restricted.Languages()
+	}
+
+	return dictionaries.Languages
+}
+
+// generateHeadline generates a headline in the given language. With a world, it is about the entities.
+func (h *Hallucinator) generateHeadline(ctx context.Context, language string, entities []int) string {
+	ctx, span := tracer.Start(ctx, "Hallucinator.generateHeadline")
+	defer span.End()
+
+	names := []string{}
+	for _, id := range entities {
+		if h.World == nil {
+			break
+		}
+		if entity, ok := h.World.Entity(id); ok {
+			names = append(names, entity.Name)
+		}
+	}
+	if len(names) < 2 {
+		return textblocks.RandomLocalizedHeadline(ctx, language)
+	}
+
+	return textblocks.LocalizedHeadlineAbout(ctx, language, names[0], names[1+rand.Intn(len(names)-1)]) //nolint: gosec
+}
+
+// generateRandomTopicLinks generates random topic links in the given language.
+// With a world, the first topics link to the entities and to random entities of the world.
+func (h *Hallucinator) generateRandomTopicLinks(ctx context.Context, language string,
+	entities []int,
+) []renderer.RandomTopic {
 	ctx, span := tracer.Start(ctx, "Hallucinator.generateRandomTopicLinks")
 	defer span.End()
 	topics := make([]renderer.RandomTopic, 0, 10)
-	for range 10 {
+	if h.World != nil {
+		related := []world.Entity{}
+		for _, id := range entities {
+			if entity, ok := h.World.Entity(id); ok {
+				related = append(related, entity)
+			}
+		}
+		for _, entity := range append(related, h.World.Random(ctx, worldTopicCount)...) {
+			link := h.hallucinatorURL
+			link.Path = path.Join(link.Path, entity.Link())
+			topics = append(topics, renderer.RandomTopic{Topic: entity.Name, Link: link.String()})
+		}
+	}
+	for len(topics) < 10 {
 		topics = append(topics, renderer.RandomTopic{
-			Topic: textblocks.RandomTopic(ctx),
-			Link: links.RandomLink(ctx,
+			Topic: textblocks.RandomLocalizedTopic(ctx, language),
+			Link: links.RandomLocalizedLink(ctx,
+				language,
 				h.hallucinatorURL,
 				h.hallucinatorLinkMaxSubdirectories,
 				h.hallucinatorLinkMaxVariables,
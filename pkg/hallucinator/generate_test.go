@@ -3,20 +3,39 @@ package hallucinator_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 
 	"codeberg.org/konterfai/konterfai/pkg/command"
 	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
 	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	"codeberg.org/konterfai/konterfai/pkg/world"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/mock"
 )
 
+// fakeBackend is a Backend that returns a fixed text without talking to a remote service.
+type fakeBackend struct {
+	text string
+	err  error
+}
+
+// Name returns the name of the fake backend.
+func (b *fakeBackend) Name() string {
+	return "fake"
+}
+
+// Generate returns the configured text and error.
+func (b *fakeBackend) Generate(_ context.Context, _ hallucinator.HTTPClient, _ hallucinator.GenerationRequest) (string, error) {
+	return b.text, b.err
+}
+
 var _ = Describe("Generate", func() {
 	const (
 		longHallucinationText = "dummy hallucination text " +
@@ -61,8 +80,7 @@ var _ = Describe("Generate", func() {
 				Scheme: "http",
 				Host:   "localhost:8080",
 			},
-			"http://localhost:11434",
-			"dummy",
+			hallucinator.NewOllamaBackend("http://localhost:11434", "dummy"),
 			1,
 			10,
 			10,
@@ -178,4 +196,59 @@ var _ = Describe("Generate", func() {
 		Expect(err).To(MatchError("ollama returned a hallucination that is too short"))
 		Expect(hal).To(Equal(hallucinator.Hallucination{}))
 	})
+
+	Context("with a custom backend", func() {
+		newHallucinator := func(backend hallucinator.Backend) *hallucinator.Hallucinator {
+			return hallucinator.NewHallucinator(ctx, logger, 5, 10, 10, 10, 500, 10, 10, 10, 10, 10,
+				url.URL{Scheme: "http", Host: "localhost:8080"}, backend, 1, 10, 10, st)
+		}
+
+		It("returns the text of the backend", func() {
+			h = newHallucinator(&fakeBackend{text: longHallucinationText})
+			hal, err := h.GenerateHallucination(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hal.Text).To(Equal(longHallucinationText))
+			Expect(hal.Prompt).NotTo(BeEmpty())
+		})
+
+		It("stores the picked model and records its statistics", func() {
+			h = newHallucinator(&fakeBackend{text: longHallucinationText})
+			h.Models = []hallucinator.WeightedModel{{Name: "tinyllama", Weight: 1}}
+			hal, err := h.GenerateHallucination(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hal.Model).To(Equal("tinyllama"))
+			Expect(st.GetModelStatistics(ctx)).To(HaveKeyWithValue("tinyllama",
+				HaveField("Generations", 1)))
+		})
+
+		It("draws the prompt, the headline and the topics from the world", func() {
+			h = newHallucinator(&fakeBackend{text: longHallucinationText})
+			h.World = world.Generate(1, 5)
+			hal, err := h.GenerateHallucination(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hal.Entities).To(HaveLen(4))
+			event, _ := h.World.Entity(hal.Entities[0])
+			Expect(event.Kind).To(Equal(world.KindEvent))
+			Expect(hal.Prompt).To(ContainSubstring(event.Name))
+			Expect(hal.Prompt).To(ContainSubstring("Stay consistent with these facts: " + event.Description))
+			h.AppendHallucination(ctx, hal)
+			page := h.PopHallucination(ctx)
+			Expect(page).To(ContainSubstring(`href="http://localhost:8080` + event.Link() + `"`))
+			Expect(page).To(MatchRegexp(`<title>[^<]*` + regexp.QuoteMeta(event.Name)))
+		})
+
+		It("returns the error of the backend", func() {
+			h = newHallucinator(&fakeBackend{err: errors.New("backend is down")})
+			hal, err := h.GenerateHallucination(ctx)
+			Expect(err).To(MatchError("backend is down"))
+			Expect(hal).To(Equal(hallucinator.Hallucination{}))
+		})
+
+		It("validates the text of the backend", func() {
+			h = newHallucinator(&fakeBackend{text: "This is a short response"})
+			hal, err := h.GenerateHallucination(ctx)
+			Expect(err).To(MatchError("fake returned a hallucination that is too short"))
+			Expect(hal).To(Equal(hallucinator.Hallucination{}))
+		})
+	})
 })
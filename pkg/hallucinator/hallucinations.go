@@ -25,17 +25,15 @@ func (h *Hallucinator) GetHallucinationCount(ctx context.Context) int {
 
 // DecreaseHallucinationRequestCount decreases the request count of a hallucination by 1.
 func (h *Hallucinator) DecreaseHallucinationRequestCount(ctx context.Context, id int) {
+	// This function does not have a lock on the hallucinations list. It is expected that the caller has locked the list.
+	// This happens in PopHallucination and PopRandomHallucination.
 	ctx, span := tracer.Start(ctx, "Hallucinator.DecreaseHallucinationRequestCount")
 	defer span.End()
 
-	go func() {
-		h.hallucinationLock.Lock()
-		defer h.hallucinationLock.Unlock()
-		if id < 0 || h.GetHallucinationCount(ctx) <= id {
-			return
-		}
-		h.hallucinations[id].RequestCount--
-	}()
+	if id < 0 || h.GetHallucinationCount(ctx) <= id {
+		return
+	}
+	h.hallucinations[id].RequestCount--
 }
 
 // PopHallucination withdraws the first hallucination from the list of hallucinations.
@@ -47,55 +45,12 @@ func (h *Hallucinator) PopHallucination(ctx context.Context) string {
 	defer h.hallucinationLock.Unlock()
 	h.CleanHallucinations(ctx)
 	if h.GetHallucinationCount(ctx) < 1 {
-		hallucination, err := h.renderer.RenderInRandomTemplate(ctx,
-			renderer.RenderData{
-				NewsAnchor:   textblocks.RandomNewsPaperName(ctx),
-				Headline:     Dream404String,
-				Content:      DreamString,
-				FollowUpLink: template.HTML(h.generateFollowUpLink(ctx, BackToStartString)), //nolint: gosec
-				RandomTopics: h.generateRandomTopicLinks(ctx),
-				Year:         functions.PickRandomYear(ctx),
-				MetaData: renderer.MetaData{
-					Description: DreamString,
-					Keywords:    textblocks.RandomKeywords(ctx, 10),
-					Charset:     functions.PickRandomStringFromSlice(ctx, &dictionaries.Charsets),
-				},
-				LanguageCode: functions.PickRandomStringFromSlice(ctx, &dictionaries.LanguageCodes),
-			})
-		if err != nil {
-			return fmt.Sprintf("Could not render template, error: %v", err)
-		}
-
-		return hallucination
-	}
-	currentHallucination := h.hallucinations[0].Text
-	var metaDescription string
-	if len(currentHallucination) < 255 {
-		metaDescription = currentHallucination
-	} else {
-		metaDescription = currentHallucination[:255]
+		return h.renderDream(ctx)
 	}
+	current := h.hallucinations[0]
 	h.DecreaseHallucinationRequestCount(ctx, 0)
-	hallucination, err := h.renderer.RenderInRandomTemplate(ctx,
-		renderer.RenderData{
-			NewsAnchor:   textblocks.RandomNewsPaperName(ctx),
-			Headline:     textblocks.RandomHeadline(ctx),
-			Content:      template.HTML(h.clutterTextWithRandomHref(ctx, h.hallucinations[0].Text)), //nolint: gosec
-			FollowUpLink: template.HTML(h.generateFollowUpLink(ctx, ContinueString)),                //nolint: gosec
-			RandomTopics: h.generateRandomTopicLinks(ctx),
-			Year:         functions.PickRandomYear(ctx),
-			MetaData: renderer.MetaData{
-				Description: metaDescription,
-				Keywords:    textblocks.RandomKeywords(ctx, 10),
-				Charset:     functions.PickRandomStringFromSlice(ctx, &dictionaries.Charsets),
-			},
-			LanguageCode: functions.PickRandomStringFromSlice(ctx, &dictionaries.LanguageCodes),
-		})
-	if err != nil {
-		return fmt.Sprintf("Could not render template, error: %v", err)
-	}
 
-	return hallucination
+	return h.renderHallucination(ctx, current)
 }
 
 // PopRandomHallucination withdraws a random hallucination from the list of hallucinations.
@@ -103,68 +58,167 @@ func (h *Hallucinator) PopRandomHallucination(ctx context.Context) string {
 	h.hallucinationLock.Lock()
 	defer h.hallucinationLock.Unlock()
 	if h.GetHallucinationCount(ctx) < 1 {
-		hallucination, err := h.renderer.RenderInRandomTemplate(ctx,
-			renderer.RenderData{
-				NewsAnchor:   textblocks.RandomNewsPaperName(ctx),
-				Headline:     Dream404String,
-				Content:      DreamString,
-				FollowUpLink: template.HTML(h.generateFollowUpLink(ctx, BackToStartString)), //nolint: gosec
-				RandomTopics: h.generateRandomTopicLinks(ctx),
-				Year:         functions.PickRandomYear(ctx),
-				MetaData: renderer.MetaData{
-					Description: DreamString,
-					Keywords:    textblocks.RandomKeywords(ctx, 10),
-					Charset:     functions.PickRandomStringFromSlice(ctx, &dictionaries.Charsets),
-				},
-				LanguageCode: functions.PickRandomStringFromSlice(ctx, &dictionaries.LanguageCodes),
-			})
-		if err != nil {
-			return fmt.Sprintf("Could not render template, error: %v", err)
-		}
-
-		return hallucination
+		return h.renderDream(ctx)
 	}
 	randomIndex := rand.Intn(h.GetHallucinationCount(ctx)) //nolint: gosec
 	h.DecreaseHallucinationRequestCount(ctx, randomIndex)
-	currentHallucination := h.hallucinations[randomIndex].Text
-	var metaDescription string
-	if len(currentHallucination) < 255 {
-		metaDescription = currentHallucination
-	} else {
-		metaDescription = currentHallucination[:255]
-	}
-	hallucination, err := h.renderer.RenderInRandomTemplate(ctx,
-		renderer.RenderData{
-			NewsAnchor:   textblocks.RandomNewsPaperName(ctx),
-			Headline:     textblocks.RandomHeadline(ctx),
-			Content:      template.HTML(h.clutterTextWithRandomHref(ctx, currentHallucination)), //nolint: gosec
-			FollowUpLink: template.HTML(h.generateFollowUpLink(ctx, ContinueString)),            //nolint: gosec
-			RandomTopics: h.generateRandomTopicLinks(ctx),
-			Year:         functions.PickRandomYear(ctx),
-			MetaData: renderer.MetaData{
-				Description: metaDescription,
-				Keywords:    textblocks.RandomKeywords(ctx, 10),
-				Charset:     functions.PickRandomStringFromSlice(ctx, &dictionaries.Charsets),
-			},
-			LanguageCode: functions.PickRandomStringFromSlice(ctx, &dictionaries.LanguageCodes),
-		})
+	hallucination := h.renderHallucination(ctx, h.hallucinations[randomIndex])
+	h.CleanHallucinations(ctx)
+
+	return hallucination
+}
+
+// renderHallucination renders the hallucination in a random template, the page metadata matches its language.
+func (h *Hallucinator) renderHallucination(ctx context.Context, current Hallucination) string {
+	ctx, span := tracer.Start(ctx, "Hallucinator.renderHallucination")
+	defer span.End()
+
+	text := current.Text
+	if h.Remix != nil {
+		text = h.Remix.Remix(ctx, text, h.remixDonors(ctx, current))
+	}
+	if h.Mutator != nil {
+		text = h.Mutator.Mutate(ctx, text)
+	}
+	metaDescription := text
+	// The description is cut by runes, cutting by bytes would split the multi-byte runes of many languages.
+	if runes := []rune(metaDescription); len(runes) >= 255 {
+		metaDescription = string(runes[:255])
+	}
+	rd := h.newRenderData(ctx, current.Language, current.Entities, ContinueString)
+	rd.Headline = h.generateHeadline(ctx, rd.Language, current.Entities)
+	// The text is escaped like a streamed text, only the links are markup.
+	rd.Content = template.HTML( //nolint: gosec
+		h.clutterTextWithRandomHref(ctx, rd.Language, template.HTMLEscapeString(text)))
+	rd.MetaData.Description = metaDescription
+	hallucination, err := h.renderer.RenderInRandomTemplate(ctx, rd.RenderData)
 	if err != nil {
 		return fmt.Sprintf("Could not render template, error: %v", err)
 	}
-	h.CleanHallucinations(ctx)
 
 	return hallucination
 }
 
+// remixDonors returns the texts of the other cached hallucinations in the language of the current hallucination.
+func (h *Hallucinator) remixDonors(ctx context.Context, current Hallucination) []string {
+	// This function does not have a lock on the hallucinations list. It is expected that the caller has locked the list.
+	// This happens in PopHallucination and PopRandomHallucination.
+	_, span := tracer.Start(ctx, "Hallucinator.remixDonors")
+	defer span.End()
+
+	donors := []string{}
+	for _, hallucination := range h.hallucinations {
+		if hallucination.Language == current.Language && hallucination.Text != current.Text {
+			donors = append(donors, hallucination.Text)
+		}
+	}
+
+	return donors
+}
+
+// renderDream renders the page that is returned while there is no hallucination.
+func (h *Hallucinator) renderDream(ctx context.Context) string {
+	ctx, span := tracer.Start(ctx, "Hallucinator.renderDream")
+	defer span.End()
+
+	rd := h.newRenderData(ctx, "", nil, BackToStartString)
+	rd.Headline = Dream404String
+	rd.Content = DreamString
+	rd.MetaData.Description = DreamString
+	hallucination, err := h.renderer.RenderInRandomTemplate(ctx, rd.RenderData)
+	if err != nil {
+		return fmt.Sprintf("Could not render template, error: %v", err)
+	}
+
+	return hallucination
+}
+
+// localizedRenderData is the render data of a page together with the language it is rendered in.
+type localizedRenderData struct {
+	renderer.RenderData
+	Language string
+}
+
+// newRenderData creates the render data of a page in the given language, without headline and content.
+// The language code, the charset, the topics and the links match the language. An empty language is the default one.
+// The topics link to the entities of the world the page is about.
+func (h *Hallucinator) newRenderData(ctx context.Context, language string, entities []int,
+	followUpText string,
+) localizedRenderData {
+	ctx, span := tracer.Start(ctx, "Hallucinator.newRenderData")
+	defer span.End()
+
+	if language == "" {
+		language = defaultLanguage
+	}
+	metadata := dictionaries.LookupLanguage(language)
+
+	return localizedRenderData{
+		RenderData: renderer.RenderData{
+			NewsAnchor:   textblocks.RandomNewsPaperName(ctx),
+			FollowUpLink: template.HTML(h.generateFollowUpLink(ctx, language, followUpText)), //nolint: gosec
+			RandomTopics: h.generateRandomTopicLinks(ctx, language, entities),
+			Year:         functions.PickRandomYear(ctx),
+			MetaData: renderer.MetaData{
+				Keywords: textblocks.RandomKeywords(ctx, 10),
+				Charset:  functions.PickRandomStringFromSlice(ctx, &metadata.Charsets),
+			},
+			LanguageCode: metadata.Code,
+		},
+		Language: language,
+	}
+}
+
 // AppendHallucination appends a hallucination to the list of hallucinations.
-func (h *Hallucinator) AppendHallucination(ctx context.Context, hallucination Hallucination) {
+// With near-duplicate detection, it returns an error instead if the hallucination is a near-duplicate of a cached or
+// a recent hallucination. The check is done while the list is locked, so concurrent workers can not both cache
+// near-duplicates that passed the check of the generation.
+func (h *Hallucinator) AppendHallucination(ctx context.Context, hallucination Hallucination) error {
 	ctx, span := tracer.Start(ctx, "Hallucinator.AppendHallucination")
 	defer span.End()
 
 	h.hallucinationLock.Lock()
 	defer h.hallucinationLock.Unlock()
+	if h.Duplicates != nil {
+		hallucination.signature = NewSignature(hallucination.Text)
+		if err := h.checkSignature(ctx, hallucination.signature); err != nil {
+			h.recordDuplicate(ctx)
+
+			return err
+		}
+		h.Duplicates.Remember(hallucination.signature)
+		for _, cached := range h.hallucinations {
+			h.similaritySum += hallucination.signature.Similarity(cached.signature)
+		}
+	}
 	h.hallucinations = append(h.hallucinations, hallucination)
 	h.setHallucinationCount(ctx)
+	h.updateDiversity(ctx)
+
+	return nil
+}
+
+// updateDiversity records the diversity of the cached hallucinations in the statistics.
+// It is derived from the sum of the similarities of all pairs, which is kept up to date when hallucinations are
+// appended or cleaned, so the pairs do not need to be compared again.
+func (h *Hallucinator) updateDiversity(ctx context.Context) {
+	// This function does not have a lock on the hallucinations list. It is expected that the caller has locked the list.
+	// This happens in AppendHallucination and CleanHallucinations.
+	ctx, span := tracer.Start(ctx, "Hallucinator.updateDiversity")
+	defer span.End()
+
+	if h.Duplicates == nil {
+		return
+	}
+	pairs := len(h.hallucinations) * (len(h.hallucinations) - 1) / 2
+	if pairs == 0 {
+		// Reset the sum to not accumulate rounding errors.
+		h.similaritySum = 0
+		h.statistics.SetDiversity(ctx, 1)
+
+		return
+	}
+	h.statistics.SetDiversity(ctx, 1-h.similaritySum/float64(pairs))
 }
 
 // CleanHallucinations cleans the list of hallucinations and removes hallucinations with requestCount 0.
@@ -178,13 +232,32 @@ func (h *Hallucinator) CleanHallucinations(ctx context.Context) {
 		return
 	}
 	newHallucinations := []Hallucination{}
+	removedHallucinations := []Hallucination{}
 	for _, hallucination := range h.hallucinations {
 		if hallucination.RequestCount > 0 {
 			newHallucinations = append(newHallucinations, hallucination)
+
+			continue
+		}
+		removedHallucinations = append(removedHallucinations, hallucination)
+	}
+	if len(removedHallucinations) == 0 {
+		return
+	}
+	if h.Duplicates != nil {
+		// Every pair with a removed hallucination is subtracted once.
+		for idx, removed := range removedHallucinations {
+			for _, other := range newHallucinations {
+				h.similaritySum -= removed.signature.Similarity(other.signature)
+			}
+			for _, other := range removedHallucinations[idx+1:] {
+				h.similaritySum -= removed.signature.Similarity(other.signature)
+			}
 		}
 	}
 	h.hallucinations = newHallucinations
 	h.setHallucinationCount(ctx)
+	h.updateDiversity(ctx)
 }
 
 // setHallucinationCount sets the hallucination count from the length of the hallucination slice.
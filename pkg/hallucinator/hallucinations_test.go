@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"log/slog"
 	"net/url"
+	"strings"
+	"unicode/utf8"
 
 	"codeberg.org/konterfai/konterfai/pkg/command"
 	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/mutator"
 	"codeberg.org/konterfai/konterfai/pkg/statistics"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -46,8 +49,7 @@ var _ = Describe("Hallucinator", func() {
 				Scheme: "http",
 				Host:   "localhost:8080",
 			},
-			"http://localhost:11434",
-			"dummy",
+			hallucinator.NewOllamaBackend("http://localhost:11434", "dummy"),
 			10,
 			10,
 			10,
@@ -147,11 +149,46 @@ var _ = Describe("Hallucinator", func() {
 			Expect(c).To(BeNumerically("<", 10))
 		})
 
+		It("renders the page metadata in the language of the hallucination", func() {
+			h.AppendHallucination(ctx, hallucinator.Hallucination{
+				RequestCount: 1,
+				Prompt:       "dummy hallucination prompt in Thai",
+				Text:         "dummy hallucination text in Thai",
+				Language:     "Thai",
+			})
+			hal := h.PopHallucination(ctx)
+			Expect(hal).To(ContainSubstring(`<html lang="th">`))
+			Expect(hal).To(MatchRegexp(`<meta charset="(UTF-8|ISO-8859-11)">`))
+		})
+
+		It("cuts the meta description without splitting runes", func() {
+			text := "a" + strings.Repeat("東京で新しい駅が開業した。", 30)
+			h.AppendHallucination(ctx, hallucinator.Hallucination{RequestCount: 1, Text: text, Language: "Japanese"})
+			hal := h.PopHallucination(ctx)
+			Expect(utf8.ValidString(hal)).To(BeTrue())
+			Expect(hal).To(ContainSubstring(`content="` + string([]rune(text)[:255]) + `"`))
+		})
+
+		It("applies the mutations before rendering", func() {
+			h.Mutator = mutator.NewMutator(1, 1, map[string]string{"Zorglub": "Quxland"})
+			h.AppendHallucination(ctx, hallucinator.Hallucination{
+				RequestCount: 1,
+				Text:         "The new harbour of Zorglub opened on Monday.",
+			})
+			hal := h.PopRandomHallucination(ctx)
+			Expect(hal).To(ContainSubstring("Quxland"))
+			Expect(hal).NotTo(ContainSubstring("Zorglub"))
+		})
+
+		It("renders pages without language in the default language", func() {
+			Expect(h.PopHallucination(ctx)).To(ContainSubstring(`<html lang="en">`))
+		})
+
 		It("does not fail when decreasing the hallucination count and the id is < 0", func() {
 			h.DecreaseHallucinationRequestCount(ctx, -1)
 		})
 
-		It("sets the hallucinationMinimalLength to math.MaxInt when the value is < 1", func() {
+		It("accepts a hallucinationMinimalLength < 1, which disables the check", func() {
 			h := hallucinator.NewHallucinator(
 				ctx,
 				logger,
@@ -169,8 +206,7 @@ var _ = Describe("Hallucinator", func() {
 					Scheme: "http",
 					Host:   "localhost:8080",
 				},
-				"http://localhost:11434",
-				"dummy",
+				&fakeBackend{text: "A short article."},
 				10,
 				10,
 				10,
@@ -193,6 +229,9 @@ var _ = Describe("Hallucinator", func() {
 			})
 
 			Expect(h.GetHallucinationCount(ctx)).To(Equal(10))
+			hal, err := h.GenerateHallucination(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hal.Text).To(Equal("A short article."))
 		})
 	})
 })
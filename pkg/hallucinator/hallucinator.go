@@ -4,30 +4,28 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"math"
 	"math/rand"
 	"net/http"
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"codeberg.org/konterfai/konterfai/pkg/helpers/functions"
 	"codeberg.org/konterfai/konterfai/pkg/helpers/links"
+	"codeberg.org/konterfai/konterfai/pkg/mutator"
 	"codeberg.org/konterfai/konterfai/pkg/renderer"
 	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	"codeberg.org/konterfai/konterfai/pkg/world"
 )
 
-// httpClient is an interface for the http.Client.
-type httpClient interface {
-	Do(req *http.Request) (*http.Response, error)
-}
-
 // Hallucinator is the structure for the Hallucinator.
 type Hallucinator struct {
 	Interval                                time.Duration
 	hallucinations                          []Hallucination
 	hallucinationCount                      int
+	similaritySum                           float64
 	hallucinationLock                       sync.Mutex
 	hallucinationCountLock                  sync.Mutex
 	hallucinationCacheSize                  int
@@ -39,15 +37,31 @@ type Hallucinator struct {
 	hallucinatorLinkHasVariablesProbability float64
 	hallucinatorLinkMaxVariables            int
 	hallucinatorURL                         url.URL
-	ollamaAddress                           string
-	ollamaModel                             string
 	aiTemperature                           float64
 	aiSeed                                  int
 	promptWordCount                         int
 
-	HTTPClient httpClient
-	renderer   *renderer.Renderer
-	statistics *statistics.Statistics
+	GenerationWorkers      int
+	HealthCheckInterval    time.Duration
+	Models                 []WeightedModel
+	OptionRanges           GenerationOptionRanges
+	SystemPrompt           string
+	Validators             ValidatorChain
+	Duplicates             *DuplicateDetector
+	Remix                  *Remixer
+	Mutator                *mutator.Mutator
+	World                  *world.World
+	pendingGenerations     int
+	pendingGenerationsLock sync.Mutex
+	promptsNeedUpdate      atomic.Bool
+
+	HTTPClient      HTTPClient
+	BootstrapClient HTTPClient
+	CacheStore      *CacheStore
+	CircuitBreaker  *CircuitBreaker
+	backend         Backend
+	renderer        *renderer.Renderer
+	statistics      *statistics.Statistics
 
 	Logger *slog.Logger
 }
@@ -65,9 +79,8 @@ func NewHallucinator(ctx context.Context, logger *slog.Logger,
 	hallucinatorLinkHasVariablesProbability float64,
 	hallucinatorLinkMaxVariables int,
 	hallucinatorURL url.URL,
-	ollamaAddress string,
-	ollamaModel string,
-	ollamaRequestTimeOut time.Duration,
+	backend Backend,
+	backendRequestTimeOut time.Duration,
 	aiTemperature float64,
 	aiSeed int,
 	statistics *statistics.Statistics,
@@ -83,9 +96,6 @@ func NewHallucinator(ctx context.Context, logger *slog.Logger,
 			hallucinatorLinkHasVariablesProbability,
 		)
 	}
-	if hallucinationMinimalLength < 1 {
-		hallucinationMinimalLength = math.MaxInt
-	}
 
 	return &Hallucinator{
 		Interval:                                interval,
@@ -100,46 +110,48 @@ func NewHallucinator(ctx context.Context, logger *slog.Logger,
 		hallucinatorLinkHasVariablesProbability: hallucinatorLinkHasVariablesProbability,
 		hallucinatorLinkMaxVariables:            hallucinatorLinkMaxVariables,
 		hallucinatorURL:                         hallucinatorURL,
-		ollamaAddress:                           ollamaAddress,
-		ollamaModel:                             ollamaModel,
 		aiTemperature:                           aiTemperature,
 		aiSeed:                                  aiSeed,
 		promptWordCount:                         hallucinatorPromptWordCount,
 
 		HTTPClient: &http.Client{
-			Timeout: ollamaRequestTimeOut,
+			Timeout: backendRequestTimeOut,
 		},
-		renderer:   renderer.NewRenderer(ctx, logger, headLineLinks[:]),
-		statistics: statistics,
-		Logger:     logger,
+		// Pulling a model can take much longer than a generation, the bootstrap is only bounded by the context.
+		BootstrapClient:     &http.Client{},
+		CircuitBreaker:      NewCircuitBreaker(defaultFailureThreshold, defaultOpenTimeout, defaultMaxBackoff),
+		HealthCheckInterval: defaultHealthCheckInterval,
+		Validators:          NewDefaultValidators(hallucinationMinimalLength),
+		backend:             backend,
+		renderer:            renderer.NewRenderer(ctx, logger, headLineLinks[:]),
+		statistics:          statistics,
+		Logger:              logger,
 	}
 }
 
 // Start starts the Hallucinator.
 func (h *Hallucinator) Start(ctx context.Context) error {
 	// No need to trace this function as it is the entry point and an endless loop.
-	promptNeedsUpdate := false
-	for {
-		if h.GetHallucinationCount(ctx) < h.hallucinationCacheSize {
-			promptNeedsUpdate = true
-			h.Logger.Info(fmt.Sprintf("hallucinations cache has empty slots, generating more... [%d/%d]",
-				len(h.hallucinations)+1, h.hallucinationCacheSize))
-			hal, err := h.GenerateHallucination(ctx)
-			if err != nil {
-				functions.SleepWithContext(ctx, h.Logger, h.Interval)
-				h.Logger.ErrorContext(ctx, fmt.Sprintf("could not generate hallucination (%v)", err))
-
-				continue
-			}
-			h.AppendHallucination(ctx, hal)
-
-			// Update Prometheus metrics
-			statistics.PromptsGeneratedTotal.Inc()
-
-			continue
-		}
-		h.Logger.InfoContext(ctx, "hallucinations cache is full, waiting for next interval...")
-		if promptNeedsUpdate {
+	h.promptsNeedUpdate.Store(h.loadCache(ctx))
+	h.bootstrapBackend(ctx)
+	workers := sync.WaitGroup{}
+	if healthChecker, ok := h.backend.(HealthChecker); ok {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			h.runHealthChecks(ctx, healthChecker)
+		}()
+	}
+	for id := range max(h.GenerationWorkers, 1) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			h.runGenerationWorker(ctx, id)
+		}()
+	}
+	for ctx.Err() == nil {
+		if h.GetHallucinationCount(ctx) >= h.hallucinationCacheSize && h.promptsNeedUpdate.Swap(false) {
+			h.Logger.InfoContext(ctx, "hallucinations cache is full, waiting for next interval...")
 			go func() {
 				prompts := map[string]int{}
 				h.hallucinationLock.Lock()
@@ -151,14 +163,104 @@ func (h *Hallucinator) Start(ctx context.Context) error {
 				h.hallucinationLock.Unlock()
 				h.statistics.UpdatePrompts(ctx, prompts)
 			}()
-			promptNeedsUpdate = false
+			h.saveCache(ctx)
 		}
 		functions.SleepWithContext(ctx, h.Logger, h.Interval)
 	}
+	workers.Wait()
+	h.saveCache(context.WithoutCancel(ctx))
+
+	return nil
+}
+
+// bootstrapBackend bootstraps the backend, if it supports it, and records its state in the statistics.
+func (h *Hallucinator) bootstrapBackend(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "Hallucinator.bootstrapBackend")
+	defer span.End()
+
+	bootstrapper, ok := h.backend.(Bootstrapper)
+	if !ok {
+		return
+	}
+	state, err := bootstrapper.Bootstrap(ctx, h.BootstrapClient)
+	if err != nil {
+		// The error marks the backend as unreachable, the state of the bootstrap is more precise.
+		h.statistics.SetBackendError(ctx, h.backend.Name(), err)
+	}
+	h.statistics.SetBackendState(ctx, h.backend.Name(), state.Reachable, state.ModelPresent)
+	if err != nil {
+		h.Logger.ErrorContext(ctx, fmt.Sprintf("could not bootstrap %s (%v)", h.backend.Name(), err))
+
+		return
+	}
+	h.Logger.InfoContext(ctx, h.backend.Name()+" is reachable and the model is present")
+}
+
+// runHealthChecks probes the endpoints of the backend every HealthCheckInterval until the context is done.
+func (h *Hallucinator) runHealthChecks(ctx context.Context, healthChecker HealthChecker) {
+	// No need to trace this function as it is an endless loop, every health check is traced by the backend.
+	for ctx.Err() == nil {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		healthChecker.CheckHealth(checkCtx, h.BootstrapClient)
+		cancel()
+		functions.SleepWithContext(ctx, h.Logger, max(h.HealthCheckInterval, time.Second))
+	}
+}
+
+// loadCache loads the persisted hallucinations into the cache, it returns true if any hallucination was loaded.
+func (h *Hallucinator) loadCache(ctx context.Context) bool {
+	ctx, span := tracer.Start(ctx, "Hallucinator.loadCache")
+	defer span.End()
+
+	if h.CacheStore == nil {
+		return false
+	}
+	hallucinations, err := h.CacheStore.Load(ctx)
+	if err != nil {
+		h.Logger.ErrorContext(ctx, fmt.Sprintf("could not load hallucinations cache (%v)", err))
+
+		return false
+	}
+	if len(hallucinations) > h.hallucinationCacheSize {
+		hallucinations = hallucinations[:h.hallucinationCacheSize]
+	}
+	loaded := 0
+	for _, hallucination := range hallucinations {
+		if err := h.AppendHallucination(ctx, hallucination); err != nil {
+			h.Logger.ErrorContext(ctx, fmt.Sprintf("could not load cached hallucination (%v)", err))
+
+			continue
+		}
+		loaded++
+	}
+	h.Logger.InfoContext(ctx, fmt.Sprintf("loaded %d hallucinations from %s", loaded, h.CacheStore.Directory))
+
+	return loaded > 0
+}
+
+// saveCache persists the hallucinations cache.
+func (h *Hallucinator) saveCache(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "Hallucinator.saveCache")
+	defer span.End()
+
+	if h.CacheStore == nil {
+		return
+	}
+	h.hallucinationLock.Lock()
+	hallucinations := make([]Hallucination, len(h.hallucinations))
+	copy(hallucinations, h.hallucinations)
+	h.hallucinationLock.Unlock()
+	if err := h.CacheStore.Save(ctx, hallucinations); err != nil {
+		h.Logger.ErrorContext(ctx, fmt.Sprintf("could not save hallucinations cache (%v)", err))
+
+		return
+	}
+	h.Logger.InfoContext(ctx, fmt.Sprintf("saved %d hallucinations to %s",
+		len(hallucinations), h.CacheStore.Directory))
 }
 
 // clutterTextWithRandomHref clutters the given text with random hrefs.
-func (h *Hallucinator) clutterTextWithRandomHref(ctx context.Context, text string) string {
+func (h *Hallucinator) clutterTextWithRandomHref(ctx context.Context, language, text string) string {
 	ctx, span := tracer.Start(ctx, "Hallucinator.clutterTextWithRandomHref")
 	defer span.End()
 
@@ -172,7 +274,8 @@ func (h *Hallucinator) clutterTextWithRandomHref(ctx context.Context, text strin
 		i := rand.Intn(len(textSlice)) //nolint: gosec
 		if !generated[i] {
 			textSlice[i] = fmt.Sprintf("<a href=\"%s\">%s</a>",
-				links.RandomLink(ctx,
+				links.RandomLocalizedLink(ctx,
+					language,
 					h.hallucinatorURL,
 					h.hallucinatorLinkMaxSubdirectories,
 					h.hallucinatorLinkMaxVariables,
@@ -61,8 +61,7 @@ var _ = Describe("Hallucinator", func() {
 				Scheme: "http",
 				Host:   "localhost:8080",
 			},
-			"http://localhost:11434",
-			"dummy",
+			hallucinator.NewOllamaBackend("http://localhost:11434", "dummy"),
 			10,
 			10,
 			10,
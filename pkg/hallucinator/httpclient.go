@@ -0,0 +1,113 @@
+package hallucinator
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// HTTPClientConfig is the configuration of the http clients that talk to the generation backend.
+// CertFile and KeyFile are the client certificate for mTLS, they must be set together.
+// An empty ProxyURL uses the proxy from the environment (HTTP_PROXY, HTTPS_PROXY and NO_PROXY).
+type HTTPClientConfig struct {
+	Headers  map[string]string
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	ProxyURL string
+}
+
+// ParseHeaders parses headers in the format "Name: value".
+func ParseHeaders(headers []string) (map[string]string, error) {
+	parsed := map[string]string{}
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid header %q (expected \"Name: value\")", header)
+		}
+		parsed[http.CanonicalHeaderKey(name)] = strings.TrimSpace(value)
+	}
+
+	return parsed, nil
+}
+
+// NewHTTPClient creates a new http client for the generation backend, a timeout of 0 means no timeout.
+func NewHTTPClient(config HTTPClientConfig, timeout time.Duration) (*http.Client, error) {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("default transport is not an http.Transport")
+	}
+	transport = transport.Clone()
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url %q (%w)", config.ProxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	var roundTripper http.RoundTripper = transport
+	if len(config.Headers) > 0 {
+		roundTripper = &headerTransport{base: transport, headers: config.Headers}
+	}
+
+	return &http.Client{Transport: roundTripper, Timeout: timeout}, nil
+}
+
+// tlsConfig creates the tls configuration with the CA bundle and the client certificate.
+func (config HTTPClientConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA bundle (%w)", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle %s does not contain any certificate", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.CertFile != "" || config.KeyFile != "" {
+		if config.CertFile == "" || config.KeyFile == "" {
+			return nil, errors.New("client certificate and key must be set together")
+		}
+		certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate (%w)", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// headerTransport adds headers to every request.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+// RoundTrip adds the headers to a copy of the request and sends it.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,98 @@
+package hallucinator_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTPClient", func() {
+	var (
+		server  *httptest.Server
+		headers http.Header
+		dir     string
+	)
+
+	// writePEM writes a PEM block to a file in the temporary directory and returns its path.
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600)).To(Succeed())
+
+		return path
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header.Clone()
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("parses headers", func() {
+		parsed, err := hallucinator.ParseHeaders([]string{"authorization: Bearer secret", "X-Test:value"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(map[string]string{"Authorization": "Bearer secret", "X-Test": "value"}))
+		_, err = hallucinator.ParseHeaders([]string{"no value"})
+		Expect(err).To(MatchError(`invalid header "no value" (expected "Name: value")`))
+	})
+
+	It("trusts the CA bundle and sends the headers", func() {
+		server.StartTLS()
+		client, err := hallucinator.NewHTTPClient(hallucinator.HTTPClientConfig{
+			Headers: map[string]string{"Authorization": "Bearer secret"},
+			CAFile:  writePEM("ca.pem", "CERTIFICATE", server.Certificate().Raw),
+		}, 0)
+		Expect(err).NotTo(HaveOccurred())
+		res, err := client.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Body.Close()).To(Succeed())
+		Expect(headers.Get("Authorization")).To(Equal("Bearer secret"))
+	})
+
+	It("rejects an unknown CA without the bundle", func() {
+		server.StartTLS()
+		client, err := hallucinator.NewHTTPClient(hallucinator.HTTPClientConfig{}, 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.Get(server.URL)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("presents the client certificate", func() {
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MinVersion: tls.VersionTLS12}
+		server.StartTLS()
+		certificate := server.TLS.Certificates[0]
+		key, err := x509.MarshalPKCS8PrivateKey(certificate.PrivateKey)
+		Expect(err).NotTo(HaveOccurred())
+		client, err := hallucinator.NewHTTPClient(hallucinator.HTTPClientConfig{
+			CAFile:   writePEM("ca.pem", "CERTIFICATE", server.Certificate().Raw),
+			CertFile: writePEM("client.pem", "CERTIFICATE", certificate.Certificate[0]),
+			KeyFile:  writePEM("client-key.pem", "PRIVATE KEY", key),
+		}, 0)
+		Expect(err).NotTo(HaveOccurred())
+		res, err := client.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Body.Close()).To(Succeed())
+	})
+
+	It("rejects incomplete or invalid configurations", func() {
+		_, err := hallucinator.NewHTTPClient(hallucinator.HTTPClientConfig{CertFile: "client.pem"}, 0)
+		Expect(err).To(MatchError("client certificate and key must be set together"))
+		_, err = hallucinator.NewHTTPClient(hallucinator.HTTPClientConfig{CAFile: filepath.Join(dir, "missing.pem")}, 0)
+		Expect(err).To(MatchError(ContainSubstring("could not read CA bundle")))
+		_, err = hallucinator.NewHTTPClient(hallucinator.HTTPClientConfig{ProxyURL: "://proxy"}, 0)
+		Expect(err).To(MatchError(ContainSubstring("invalid proxy url")))
+	})
+})
@@ -0,0 +1,208 @@
+package hallucinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"codeberg.org/konterfai/konterfai/pkg/dictionaries"
+	"codeberg.org/konterfai/konterfai/pkg/helpers/functions"
+)
+
+// markovDictionarySentences is the number of sentences generated from the dictionaries for training.
+const markovDictionarySentences = 5000
+
+// markovDictionaryTemplates are the sentence templates used to train the Markov chain on the dictionaries.
+// The verbs are %[1]s and %[2]s, the nouns are %[3]s, %[4]s and %[5]s and the city is %[6]s.
+var markovDictionaryTemplates = []string{
+	"The %[3]s of %[6]s will %[1]s the %[4]s.",
+	"In %[6]s, every %[3]s wants to %[1]s a %[4]s and %[2]s the %[5]s.",
+	"Nobody in %[6]s could %[1]s the %[3]s, so the %[4]s had to %[2]s it.",
+	"According to the %[3]s, the %[4]s did not %[1]s the %[5]s.",
+	"The %[3]s and the %[4]s of %[6]s decided to %[1]s together.",
+	"Experts from %[6]s say that a %[3]s can %[1]s any %[4]s.",
+	"When the %[3]s began to %[1]s, the %[4]s of %[6]s refused to %[2]s.",
+	"It is said that the %[3]s will never %[1]s the %[4]s again.",
+	"A %[3]s from %[6]s was seen trying to %[1]s a %[4]s near the %[5]s.",
+	"Why would the %[3]s %[1]s the %[4]s, asked the %[5]s of %[6]s.",
+}
+
+// MarkovBackend is an offline Backend that generates texts from an n-gram Markov chain.
+// It does not need any external service and is trained once at startup.
+type MarkovBackend struct {
+	order  int
+	chain  map[string][]string
+	starts [][]string
+}
+
+// NewMarkovBackend creates a new MarkovBackend instance with the given order (prefix length) and trains it on the
+// files of the corpus directory, if set, and the embedded dictionaries, if useDictionaries is set.
+// It fails if the training data has no sentence starts, a capitalized word at the beginning of a sentence.
+func NewMarkovBackend(ctx context.Context, order int, corpusDir string, useDictionaries bool,
+) (*MarkovBackend, error) {
+	ctx, span := tracer.Start(ctx, "NewMarkovBackend")
+	defer span.End()
+
+	backend := &MarkovBackend{
+		order: max(order, 1),
+		chain: map[string][]string{},
+	}
+	if corpusDir != "" {
+		if err := backend.TrainFromDirectory(corpusDir); err != nil {
+			return nil, err
+		}
+	}
+	if useDictionaries {
+		backend.TrainFromDictionaries(ctx)
+	}
+	if len(backend.starts) == 0 {
+		return nil, errors.New("markov backend has no training data with sentences that start with a capital letter")
+	}
+
+	return backend, nil
+}
+
+// Name returns the name of the backend.
+func (b *MarkovBackend) Name() string {
+	return "markov"
+}
+
+// Languages returns the languages the backend writes in, the markov backend writes in the language of its corpus
+// and ignores the language of the prompt.
+func (b *MarkovBackend) Languages() []string {
+	return []string{"English"}
+}
+
+// Size returns the number of prefixes the Markov chain has been trained on.
+func (b *MarkovBackend) Size() int {
+	return len(b.chain)
+}
+
+// Train trains the Markov chain on the given text.
+func (b *MarkovBackend) Train(text string) {
+	words := strings.Fields(text)
+	if len(words) <= b.order {
+		return
+	}
+	for i := 0; i+b.order < len(words); i++ {
+		prefix := words[i : i+b.order]
+		if isMarkovSentenceStart(words, i) {
+			b.starts = append(b.starts, prefix)
+		}
+		key := strings.Join(prefix, " ")
+		b.chain[key] = append(b.chain[key], words[i+b.order])
+	}
+}
+
+// TrainFromDirectory trains the Markov chain on all regular files in the given directory and its subdirectories.
+func (b *MarkovBackend) TrainFromDirectory(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read markov corpus file %s (%w)", path, err)
+		}
+		b.Train(string(content))
+
+		return nil
+	})
+}
+
+// TrainFromDictionaries trains the Markov chain on sentences built from the embedded dictionaries.
+func (b *MarkovBackend) TrainFromDictionaries(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "MarkovBackend.TrainFromDictionaries")
+	defer span.End()
+
+	noun := func() string {
+		return strings.ToLower(functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns))
+	}
+	sentences := make([]string, 0, markovDictionarySentences)
+	for range markovDictionarySentences {
+		sentences = append(sentences, fmt.Sprintf(functions.PickRandomStringFromSlice(ctx, &markovDictionaryTemplates),
+			functions.PickRandomStringFromSlice(ctx, &dictionaries.Verbs),
+			functions.PickRandomStringFromSlice(ctx, &dictionaries.Verbs),
+			noun(), noun(), noun(),
+			functions.PickRandomStringFromSlice(ctx, &dictionaries.Cities),
+		))
+	}
+	b.Train(strings.Join(sentences, " "))
+}
+
+// Generate generates a text by walking the Markov chain, the client is not used.
+func (b *MarkovBackend) Generate(ctx context.Context, _ HTTPClient, request GenerationRequest) (string, error) {
+	_, span := tracer.Start(ctx, "MarkovBackend.Generate")
+	defer span.End()
+
+	if len(b.starts) == 0 {
+		return "", errors.New("markov chain has not been trained")
+	}
+	text := strings.Builder{}
+	wordCount := 0
+	sentenceCount := 0
+	paragraphLength := rand.Intn(4) + 3 //nolint: gosec
+	for wordCount < request.WordCount || text.Len() < request.MinimalLength {
+		sentence := b.generateSentence(max(request.WordCount-wordCount, b.order))
+		switch {
+		case sentenceCount > 0 && sentenceCount%paragraphLength == 0:
+			text.WriteString("\n\n")
+		case sentenceCount > 0:
+			text.WriteString(" ")
+		}
+		text.WriteString(strings.Join(sentence, " "))
+		wordCount += len(sentence)
+		sentenceCount++
+	}
+
+	return text.String(), nil
+}
+
+// generateSentence walks the Markov chain from a random sentence start until the sentence ends.
+// The sentence is cut off after maxWords words and twice the order, whatever is higher.
+func (b *MarkovBackend) generateSentence(maxWords int) []string {
+	start := b.starts[rand.Intn(len(b.starts))] //nolint: gosec
+	words := append([]string{}, start...)
+	for !isMarkovSentenceEnd(words[len(words)-1]) {
+		if len(words) >= max(maxWords, 2*b.order) {
+			words[len(words)-1] = strings.TrimRightFunc(words[len(words)-1], unicode.IsPunct) + "."
+
+			break
+		}
+		next := b.chain[strings.Join(words[len(words)-b.order:], " ")]
+		if len(next) == 0 {
+			words[len(words)-1] = strings.TrimRightFunc(words[len(words)-1], unicode.IsPunct) + "."
+
+			break
+		}
+		words = append(words, next[rand.Intn(len(next))]) //nolint: gosec
+	}
+
+	return words
+}
+
+// isMarkovSentenceStart checks if the word at index i starts a sentence.
+func isMarkovSentenceStart(words []string, i int) bool {
+	first := []rune(words[i])
+	if len(first) == 0 || !unicode.IsUpper(first[0]) {
+		return false
+	}
+
+	return i == 0 || isMarkovSentenceEnd(words[i-1])
+}
+
+// isMarkovSentenceEnd checks if the word ends a sentence.
+func isMarkovSentenceEnd(word string) bool {
+	word = strings.TrimRight(word, "\"')]")
+
+	return strings.HasSuffix(word, ".") || strings.HasSuffix(word, "!") || strings.HasSuffix(word, "?")
+}
@@ -0,0 +1,95 @@
+package hallucinator_test
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"codeberg.org/konterfai/konterfai/pkg/command"
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MarkovBackend", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("fails without training data", func() {
+		_, err := hallucinator.NewMarkovBackend(ctx, 2, "", false)
+		Expect(err).To(MatchError(ContainSubstring("markov backend has no training data")))
+	})
+
+	It("fails if the corpus has no sentence starts", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "a.txt"),
+			[]byte("the quick brown fox jumps over the lazy dog."), 0o600)).To(Succeed())
+		_, err := hallucinator.NewMarkovBackend(ctx, 2, dir, false)
+		Expect(err).To(MatchError(ContainSubstring("markov backend has no training data")))
+	})
+
+	It("generates a text from a corpus directory", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(dir, "sub"), 0o750)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "a.txt"),
+			[]byte("The quick brown fox jumps over the lazy dog."), 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "sub", "b.txt"),
+			[]byte("The lazy dog sleeps under the old tree."), 0o600)).To(Succeed())
+		backend, err := hallucinator.NewMarkovBackend(ctx, 2, dir, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backend.Size()).To(BeNumerically(">", 0))
+
+		text, err := backend.Generate(ctx, nil, hallucinator.GenerationRequest{WordCount: 50})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(HavePrefix("The "))
+		Expect(len(strings.Fields(text))).To(BeNumerically(">=", 50))
+		Expect(text).To(HaveSuffix("."))
+	})
+
+	It("returns an error if the corpus directory does not exist", func() {
+		_, err := hallucinator.NewMarkovBackend(ctx, 2, filepath.Join(GinkgoT().TempDir(), "missing"), false)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("honours the minimal length", func() {
+		backend, err := hallucinator.NewMarkovBackend(ctx, 2, "", true)
+		Expect(err).NotTo(HaveOccurred())
+		text, err := backend.Generate(ctx, nil, hallucinator.GenerationRequest{WordCount: 1, MinimalLength: 2000})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(text)).To(BeNumerically(">=", 2000))
+	})
+
+	It("can be used by the hallucinator", func() {
+		logger, _ := command.SetLogger("off", "")
+		st := statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+		backend, err := hallucinator.NewMarkovBackend(ctx, 2, "", true)
+		Expect(err).NotTo(HaveOccurred())
+		h := hallucinator.NewHallucinator(ctx, logger, 5, 10, 10, 10, 500, 100, 10, 10, 10, 10,
+			url.URL{Scheme: "http", Host: "localhost:8080"}, backend, 1, 10, 10, st)
+		hal, err := h.GenerateHallucination(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(hal.Text)).To(BeNumerically(">=", 500))
+		Expect(hal.Prompt).NotTo(BeEmpty())
+		Expect(hal.RequestCount).To(Equal(10))
+	})
+
+	It("only gets prompts in the languages it writes in", func() {
+		logger, _ := command.SetLogger("off", "")
+		st := statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+		backend, err := hallucinator.NewMarkovBackend(ctx, 2, "", true)
+		Expect(err).NotTo(HaveOccurred())
+		h := hallucinator.NewHallucinator(ctx, logger, 5, 10, 10, 10, 10, 10, 10, 10, 10, 10,
+			url.URL{Scheme: "http", Host: "localhost:8080"}, backend, 1, 10, 10, st)
+		for range 20 {
+			hal, err := h.GenerateHallucination(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hal.Language).To(Equal("English"))
+		}
+	})
+})
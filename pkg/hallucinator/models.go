@@ -0,0 +1,71 @@
+package hallucinator
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// WeightedModel is a model of the generation backend with its weight in the rotation.
+type WeightedModel struct {
+	Name   string
+	Weight int
+}
+
+// ParseWeightedModels parses a comma separated list of models with optional weights, e.g. "qwen2:0.5b=3,tinyllama=1".
+// Models without weight have a weight of 1. An empty string results in an empty list.
+func ParseWeightedModels(value string) ([]WeightedModel, error) {
+	models := []WeightedModel{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, weight, hasWeight := strings.Cut(entry, "=")
+		model := WeightedModel{Name: strings.TrimSpace(name), Weight: 1}
+		if model.Name == "" {
+			return nil, fmt.Errorf("invalid model %q (missing name)", entry)
+		}
+		if hasWeight {
+			w, err := strconv.Atoi(strings.TrimSpace(weight))
+			if err != nil || w < 1 {
+				return nil, fmt.Errorf("invalid model %q (weight must be a positive integer)", entry)
+			}
+			model.Weight = w
+		}
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+// ModelNames returns the names of the given models.
+func ModelNames(models []WeightedModel) []string {
+	names := make([]string, 0, len(models))
+	for _, model := range models {
+		names = append(names, model.Name)
+	}
+
+	return names
+}
+
+// pickWeightedModel picks a random model according to the weights, it returns an empty string if there are no models.
+func pickWeightedModel(models []WeightedModel) string {
+	total := 0
+	for _, model := range models {
+		total += model.Weight
+	}
+	if total < 1 {
+		return ""
+	}
+	pick := rand.Intn(total) //nolint: gosec
+	for _, model := range models {
+		if pick < model.Weight {
+			return model.Name
+		}
+		pick -= model.Weight
+	}
+
+	return ""
+}
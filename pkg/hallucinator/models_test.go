@@ -0,0 +1,40 @@
+package hallucinator_test
+
+import (
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Weighted models", func() {
+	It("parses a weighted list of models", func() {
+		models, err := hallucinator.ParseWeightedModels("qwen2:0.5b=3, tinyllama")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(models).To(Equal([]hallucinator.WeightedModel{
+			{Name: "qwen2:0.5b", Weight: 3},
+			{Name: "tinyllama", Weight: 1},
+		}))
+		Expect(hallucinator.ModelNames(models)).To(Equal([]string{"qwen2:0.5b", "tinyllama"}))
+	})
+
+	It("parses a single model without weight", func() {
+		models, err := hallucinator.ParseWeightedModels("qwen2:0.5b")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(models).To(Equal([]hallucinator.WeightedModel{{Name: "qwen2:0.5b", Weight: 1}}))
+	})
+
+	It("returns an empty list for an empty value", func() {
+		models, err := hallucinator.ParseWeightedModels("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(models).To(BeEmpty())
+	})
+
+	It("rejects invalid weights and names", func() {
+		_, err := hallucinator.ParseWeightedModels("qwen2:0.5b=0")
+		Expect(err).To(MatchError(`invalid model "qwen2:0.5b=0" (weight must be a positive integer)`))
+		_, err = hallucinator.ParseWeightedModels("tinyllama=abc")
+		Expect(err).To(HaveOccurred())
+		_, err = hallucinator.ParseWeightedModels("=3")
+		Expect(err).To(MatchError(`invalid model "=3" (missing name)`))
+	})
+})
@@ -0,0 +1,357 @@
+package hallucinator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// OllamaBackend is the Backend for the Ollama chat API.
+// PullModel and KeepAlive are only used by Bootstrap, KeepAlive is also sent with every generation.
+// Models are all models used in the rotation, they are bootstrapped together with Model.
+// If Endpoints is set, the requests are spread across its addresses instead of being sent to Address.
+type OllamaBackend struct {
+	Address   string
+	Endpoints *EndpointPool
+	Model     string
+	Models    []string
+	PullModel bool
+	KeepAlive string
+}
+
+// NewOllamaBackend creates a new OllamaBackend instance.
+func NewOllamaBackend(address, model string) *OllamaBackend {
+	return &OllamaBackend{
+		Address: address,
+		Model:   model,
+	}
+}
+
+// Name returns the name of the backend.
+func (b *OllamaBackend) Name() string {
+	return "ollama"
+}
+
+// Generate generates a text from the Ollama API.
+func (b *OllamaBackend) Generate(ctx context.Context, client HTTPClient, request GenerationRequest,
+) (text string, err error) {
+	ctx, span := tracer.Start(ctx, "OllamaBackend.Generate")
+	defer span.End()
+
+	address, release, err := b.acquireAddress()
+	if err != nil {
+		return "", err
+	}
+	defer func() { release(err) }()
+	req, err := b.newChatRequest(ctx, address, request, false)
+	if err != nil {
+		return "", err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resBody, err := readResponseBody(b.Name(), res)
+	if err != nil {
+		return "", err
+	}
+
+	return concatOllamaMessages(resBody)
+}
+
+// GenerateStream generates a text from the Ollama API and calls onToken for every streamed message.
+func (b *OllamaBackend) GenerateStream(ctx context.Context, client HTTPClient, request GenerationRequest,
+	onToken func(token string),
+) (text string, err error) {
+	ctx, span := tracer.Start(ctx, "OllamaBackend.GenerateStream")
+	defer span.End()
+
+	address, release, err := b.acquireAddress()
+	if err != nil {
+		return "", err
+	}
+	defer func() { release(err) }()
+	req, err := b.newChatRequest(ctx, address, request, true)
+	if err != nil {
+		return "", err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	if err := checkStreamResponse(b.Name(), res); err != nil {
+		return "", err
+	}
+	defer res.Body.Close() //nolint: errcheck
+	payload := strings.Builder{}
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		m := OllamaResponse{}
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			return "", err
+		}
+		if m.Message.Content != "" {
+			payload.WriteString(m.Message.Content)
+			onToken(m.Message.Content)
+		}
+		if m.Done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(payload.String()), nil
+}
+
+// acquireAddress returns the address for the next request and a function that must be called with its result.
+func (b *OllamaBackend) acquireAddress() (string, func(err error), error) {
+	if b.Endpoints == nil {
+		return b.Address, func(error) {}, nil
+	}
+	endpoint, err := b.Endpoints.Acquire()
+	if err != nil {
+		return "", nil, fmt.Errorf("%w for ollama", err)
+	}
+	started := time.Now()
+
+	return endpoint.Address, func(err error) {
+		b.Endpoints.Release(endpoint, time.Since(started), err)
+	}, nil
+}
+
+// addresses returns the addresses of all endpoints.
+func (b *OllamaBackend) addresses() []string {
+	if b.Endpoints == nil {
+		return []string{b.Address}
+	}
+
+	return b.Endpoints.Addresses()
+}
+
+// newChatRequest creates the http request for the chat API.
+func (b *OllamaBackend) newChatRequest(ctx context.Context, address string, request GenerationRequest, stream bool,
+) (*http.Request, error) {
+	requestURL, err := url.JoinPath(address, "/api/chat")
+	if err != nil {
+		return nil, err
+	}
+	messages := []OllamaMessage{}
+	if request.SystemPrompt != "" {
+		messages = append(messages, OllamaMessage{Role: "system", Content: request.SystemPrompt})
+	}
+	messages = append(messages, OllamaMessage{Role: "user", Content: request.Prompt})
+	options := request.Options
+	requestBody := ollamaJSONRequest{
+		Model: b.model(request), Messages: messages, Stream: stream,
+		Options: ollamaOptions{
+			Temperature: options.Temperature, Seed: options.Seed, TopP: options.TopP, TopK: options.TopK,
+			MinP: options.MinP, RepeatPenalty: options.RepeatPenalty, NumPredict: options.NumPredict,
+			NumCtx: options.NumCtx, Stop: options.Stop,
+		},
+		KeepAlive: b.KeepAlive,
+	}
+	requestBodyJSON, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewReader(requestBodyJSON))
+}
+
+// Bootstrap checks if Ollama is reachable and the models are present on every endpoint.
+// Missing models are pulled if PullModel is set. If KeepAlive is set, the models are loaded into memory.
+// The backend is reachable and has its models if at least one endpoint is bootstrapped, failing endpoints are
+// reported in the error and ejected by the health checks.
+func (b *OllamaBackend) Bootstrap(ctx context.Context, client HTTPClient) (BackendState, error) {
+	ctx, span := tracer.Start(ctx, "OllamaBackend.Bootstrap")
+	defer span.End()
+
+	state := BackendState{}
+	errs := []error{}
+	for _, address := range b.addresses() {
+		addressState, err := b.bootstrapAddress(ctx, client, address)
+		state.Reachable = state.Reachable || addressState.Reachable
+		state.ModelPresent = state.ModelPresent || addressState.ModelPresent
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return state, errors.Join(errs...)
+}
+
+// bootstrapAddress bootstraps all models on a single endpoint.
+func (b *OllamaBackend) bootstrapAddress(ctx context.Context, client HTTPClient, address string,
+) (BackendState, error) {
+	ctx, span := tracer.Start(ctx, "OllamaBackend.bootstrapAddress")
+	defer span.End()
+
+	state := BackendState{}
+	tags := OllamaTagsResponse{}
+	if err := b.request(ctx, client, address, http.MethodGet, "/api/tags", nil, &tags); err != nil {
+		return state, fmt.Errorf("ollama is not reachable at %s (%w)", address, err)
+	}
+	state.Reachable = true
+	for _, model := range b.bootstrapModels() {
+		if err := b.bootstrapModel(ctx, client, address, tags.Models, model); err != nil {
+			return state, err
+		}
+	}
+	state.ModelPresent = true
+
+	return state, nil
+}
+
+// CheckHealth probes every endpoint and ejects endpoints that are not reachable or miss a model.
+func (b *OllamaBackend) CheckHealth(ctx context.Context, client HTTPClient) {
+	ctx, span := tracer.Start(ctx, "OllamaBackend.CheckHealth")
+	defer span.End()
+
+	if b.Endpoints == nil {
+		return
+	}
+	b.Endpoints.CheckHealth(ctx, func(ctx context.Context, address string) error {
+		tags := OllamaTagsResponse{}
+		if err := b.request(ctx, client, address, http.MethodGet, "/api/tags", nil, &tags); err != nil {
+			return err
+		}
+		for _, model := range b.bootstrapModels() {
+			if !hasModel(tags.Models, model) {
+				return fmt.Errorf("ollama model %s is not present at %s", model, address)
+			}
+		}
+
+		return nil
+	})
+}
+
+// bootstrapModel pulls the model if it is missing and PullModel is set, and preloads it if KeepAlive is set.
+func (b *OllamaBackend) bootstrapModel(ctx context.Context, client HTTPClient, address string,
+	models []OllamaModel, model string,
+) error {
+	ctx, span := tracer.Start(ctx, "OllamaBackend.bootstrapModel")
+	defer span.End()
+
+	if !hasModel(models, model) {
+		if !b.PullModel {
+			return fmt.Errorf("ollama model %s is not present, pull it manually or enable --ollama-pull-model", model)
+		}
+		pull := OllamaPullResponse{}
+		if err := b.request(ctx, client, address, http.MethodPost, "/api/pull",
+			ollamaPullRequest{Model: model, Stream: false}, &pull); err != nil {
+			return fmt.Errorf("could not pull ollama model %s (%w)", model, err)
+		}
+		if pull.Status != "success" {
+			return fmt.Errorf("could not pull ollama model %s (%s)", model, pull.Error)
+		}
+	}
+	if b.KeepAlive != "" {
+		if err := b.request(ctx, client, address, http.MethodPost, "/api/generate",
+			ollamaPreloadRequest{Model: model, KeepAlive: b.KeepAlive}, nil); err != nil {
+			return fmt.Errorf("could not preload ollama model %s (%w)", model, err)
+		}
+	}
+
+	return nil
+}
+
+// bootstrapModels returns Model and all Models without duplicates.
+func (b *OllamaBackend) bootstrapModels() []string {
+	models := []string{b.Model}
+	for _, model := range b.Models {
+		if !slices.Contains(models, model) {
+			models = append(models, model)
+		}
+	}
+
+	return models
+}
+
+// model returns the model of the request, or the configured model if the request does not set one.
+func (b *OllamaBackend) model(request GenerationRequest) string {
+	if request.Model != "" {
+		return request.Model
+	}
+
+	return b.Model
+}
+
+// hasModel checks if the model is in the given list, a model without tag matches the latest tag.
+func hasModel(models []OllamaModel, wanted string) bool {
+	for _, model := range models {
+		for _, name := range []string{model.Name, model.Model} {
+			if name == wanted || (!strings.Contains(wanted, ":") && name == wanted+":latest") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// request sends a JSON request to the Ollama API and decodes the response into response, if it is not nil.
+func (b *OllamaBackend) request(ctx context.Context, client HTTPClient, address, method, path string,
+	body, response any,
+) error {
+	requestURL, err := url.JoinPath(address, path)
+	if err != nil {
+		return err
+	}
+	var requestBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&requestBody).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, &requestBody)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resBody, err := readResponseBody(b.Name(), res)
+	if err != nil {
+		return err
+	}
+	if response == nil {
+		return nil
+	}
+
+	return json.Unmarshal(resBody, response)
+}
+
+// concatOllamaMessages concatenates Ollama messages.
+func concatOllamaMessages(responseBody []byte) (string, error) {
+	responses := strings.Split(string(responseBody), "\n")
+	var payload []string
+	for _, message := range responses {
+		m := OllamaResponse{}
+		if err := json.Unmarshal([]byte(message), &m); err != nil {
+			return "", err
+		}
+		if msg := strings.Trim(m.Message.Content, " "); msg != "" && msg != "\n" {
+			payload = append(payload, msg)
+		}
+		if m.Done {
+			break
+		}
+	}
+
+	return strings.Join(payload, " "), nil
+}
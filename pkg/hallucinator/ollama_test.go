@@ -0,0 +1,166 @@
+package hallucinator_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("OllamaBackend", func() {
+	var (
+		ctx     context.Context
+		client  *MockHttpClient
+		backend *hallucinator.OllamaBackend
+	)
+
+	// onPath mocks the response for the given path of the Ollama API.
+	onPath := func(path string, statusCode int, body string) {
+		client.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+			return req.URL.Path == path
+		})).Return(&http.Response{
+			StatusCode: statusCode,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil).Once()
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = new(MockHttpClient)
+		backend = hallucinator.NewOllamaBackend("http://localhost:11434", "qwen2:0.5b")
+	})
+
+	Context("Bootstrap", func() {
+		It("finds a present model", func() {
+			onPath("/api/tags", http.StatusOK, `{"models": [{"name": "qwen2:0.5b", "model": "qwen2:0.5b"}]}`)
+			state, err := backend.Bootstrap(ctx, client)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(hallucinator.BackendState{Reachable: true, ModelPresent: true}))
+		})
+
+		It("matches a model without tag with the latest tag", func() {
+			backend.Model = "llama3"
+			onPath("/api/tags", http.StatusOK, `{"models": [{"name": "llama3:latest"}]}`)
+			state, err := backend.Bootstrap(ctx, client)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.ModelPresent).To(BeTrue())
+		})
+
+		It("reports an unreachable service", func() {
+			client.On("Do", mock.Anything).Return(&http.Response{}, errors.New("connection refused"))
+			state, err := backend.Bootstrap(ctx, client)
+			Expect(err).To(MatchError(ContainSubstring("ollama is not reachable at http://localhost:11434")))
+			Expect(state).To(Equal(hallucinator.BackendState{}))
+		})
+
+		It("reports a missing model without pulling it", func() {
+			onPath("/api/tags", http.StatusOK, `{"models": [{"name": "llama3:latest"}]}`)
+			state, err := backend.Bootstrap(ctx, client)
+			Expect(err).To(MatchError(ContainSubstring("ollama model qwen2:0.5b is not present")))
+			Expect(state).To(Equal(hallucinator.BackendState{Reachable: true}))
+		})
+
+		It("pulls a missing model and preloads it", func() {
+			backend.PullModel = true
+			backend.KeepAlive = "24h"
+			onPath("/api/tags", http.StatusOK, `{"models": []}`)
+			onPath("/api/pull", http.StatusOK, `{"status": "success"}`)
+			onPath("/api/generate", http.StatusOK, `{"model": "qwen2:0.5b", "done": true}`)
+			state, err := backend.Bootstrap(ctx, client)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(hallucinator.BackendState{Reachable: true, ModelPresent: true}))
+			client.AssertNumberOfCalls(GinkgoT(), "Do", 3)
+		})
+
+		It("bootstraps every model of the rotation", func() {
+			backend.Models = []string{"qwen2:0.5b", "tinyllama"}
+			onPath("/api/tags", http.StatusOK, `{"models": [{"name": "qwen2:0.5b"}]}`)
+			_, err := backend.Bootstrap(ctx, client)
+			Expect(err).To(MatchError(ContainSubstring("ollama model tinyllama is not present")))
+		})
+
+		It("is reachable if one of several endpoints is bootstrapped", func() {
+			endpoints, err := hallucinator.NewEndpointPool("ollama",
+				[]string{"http://a:11434", "http://b:11434"}, hallucinator.BalanceRoundRobin, 3)
+			Expect(err).NotTo(HaveOccurred())
+			backend.Endpoints = endpoints
+			for range 2 {
+				client.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.Host == "a:11434"
+				})).Return(&http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"models": [{"name": "qwen2:0.5b"}]}`)),
+				}, nil).Once()
+			}
+			client.On("Do", mock.Anything).Return(&http.Response{}, errors.New("connection refused"))
+			state, err := backend.Bootstrap(ctx, client)
+			Expect(err).To(MatchError(ContainSubstring("ollama is not reachable at http://b:11434")))
+			Expect(state).To(Equal(hallucinator.BackendState{Reachable: true, ModelPresent: true}))
+
+			backend.CheckHealth(ctx, client)
+			Expect(endpoints.Healthy()).To(Equal([]string{"http://a:11434"}))
+		})
+
+		It("reports a failed pull", func() {
+			backend.PullModel = true
+			onPath("/api/tags", http.StatusOK, `{"models": []}`)
+			onPath("/api/pull", http.StatusOK, `{"error": "pull model manifest: file does not exist"}`)
+			_, err := backend.Bootstrap(ctx, client)
+			Expect(err).To(MatchError("could not pull ollama model qwen2:0.5b (pull model manifest: file does not exist)"))
+		})
+	})
+
+	Context("GenerateStream", func() {
+		It("calls onToken for every streamed message", func() {
+			onPath("/api/chat", http.StatusOK, strings.Join([]string{
+				`{"message": {"role": "assistant", "content": "This is"}, "done": false}`,
+				`{"message": {"role": "assistant", "content": " a streamed"}, "done": false}`,
+				``,
+				`{"message": {"role": "assistant", "content": " response"}, "done": true}`,
+			}, "\n"))
+			tokens := []string{}
+			text, err := backend.GenerateStream(ctx, client, hallucinator.GenerationRequest{Prompt: "prompt"},
+				func(token string) {
+					tokens = append(tokens, token)
+				})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(text).To(Equal("This is a streamed response"))
+			Expect(tokens).To(Equal([]string{"This is", " a streamed", " response"}))
+		})
+
+		It("sends the system prompt and the set generation options", func() {
+			var body string
+			client.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+				raw, _ := io.ReadAll(req.Body)
+				body = string(raw)
+
+				return true
+			})).Return(&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"message": {"content": "response"}, "done": true}`)),
+			}, nil).Once()
+			topK := 40
+			_, err := backend.GenerateStream(ctx, client, hallucinator.GenerationRequest{
+				Prompt: "prompt", SystemPrompt: "you are a journalist",
+				Options: hallucinator.GenerationOptions{Temperature: 0.7, TopK: &topK, Stop: []string{"###"}},
+			}, func(string) {})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(ContainSubstring(`{"role":"system","content":"you are a journalist"}`))
+			Expect(body).To(ContainSubstring(`"top_k":40`))
+			Expect(body).To(ContainSubstring(`"stop":["###"]`))
+			Expect(body).NotTo(ContainSubstring(`"top_p"`))
+		})
+
+		It("returns an error if the service does not return 200 OK", func() {
+			onPath("/api/chat", http.StatusNotFound, "")
+			_, err := backend.GenerateStream(ctx, client, hallucinator.GenerationRequest{}, func(string) {})
+			Expect(err).To(MatchError("ollama did not return 200 OK"))
+		})
+	})
+})
@@ -0,0 +1,228 @@
+package hallucinator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+)
+
+// OpenAIMaxTemperature is the highest temperature accepted by OpenAI-compatible APIs, higher temperatures are
+// clamped to it.
+const OpenAIMaxTemperature = 2.0
+
+// OpenAIBackend is the Backend for OpenAI-compatible chat-completions APIs,
+// like the ones provided by llama.cpp server, vLLM or LocalAI.
+type OpenAIBackend struct {
+	Address string
+	Model   string
+	APIKey  string
+	Stream  bool
+}
+
+// NewOpenAIBackend creates a new OpenAIBackend instance.
+func NewOpenAIBackend(address, model, apiKey string, stream bool) *OpenAIBackend {
+	return &OpenAIBackend{
+		Address: address,
+		Model:   model,
+		APIKey:  apiKey,
+		Stream:  stream,
+	}
+}
+
+// Name returns the name of the backend.
+func (b *OpenAIBackend) Name() string {
+	return "openai"
+}
+
+// Generate generates a text from an OpenAI-compatible chat-completions API.
+func (b *OpenAIBackend) Generate(ctx context.Context, client HTTPClient, request GenerationRequest) (string, error) {
+	ctx, span := tracer.Start(ctx, "OpenAIBackend.Generate")
+	defer span.End()
+
+	req, err := b.newChatRequest(ctx, request, b.Stream)
+	if err != nil {
+		return "", err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resBody, err := readResponseBody(b.Name(), res)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		text  string
+		usage *OpenAIUsage
+	)
+	if b.Stream {
+		text, usage, err = concatOpenAIEvents(resBody)
+	} else {
+		text, usage, err = parseOpenAIResponse(resBody)
+	}
+	if err != nil {
+		return "", err
+	}
+	b.recordUsage(usage)
+
+	return text, nil
+}
+
+// GenerateStream generates a text from an OpenAI-compatible chat-completions API and calls onToken for every delta.
+// The response is always streamed, regardless of the Stream setting.
+func (b *OpenAIBackend) GenerateStream(ctx context.Context, client HTTPClient, request GenerationRequest,
+	onToken func(token string),
+) (string, error) {
+	ctx, span := tracer.Start(ctx, "OpenAIBackend.GenerateStream")
+	defer span.End()
+
+	req, err := b.newChatRequest(ctx, request, true)
+	if err != nil {
+		return "", err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	if err := checkStreamResponse(b.Name(), res); err != nil {
+		return "", err
+	}
+	defer res.Body.Close() //nolint: errcheck
+	text, usage, err := readOpenAIEvents(res.Body, onToken)
+	if err != nil {
+		return "", err
+	}
+	b.recordUsage(usage)
+
+	return text, nil
+}
+
+// newChatRequest creates the http request for the chat-completions API.
+func (b *OpenAIBackend) newChatRequest(ctx context.Context, request GenerationRequest, stream bool,
+) (*http.Request, error) {
+	requestURL, err := url.JoinPath(b.Address, "/v1/chat/completions")
+	if err != nil {
+		return nil, err
+	}
+	messages := []OpenAIMessage{}
+	if request.SystemPrompt != "" {
+		messages = append(messages, OpenAIMessage{Role: "system", Content: request.SystemPrompt})
+	}
+	messages = append(messages, OpenAIMessage{Role: "user", Content: request.Prompt})
+	options := request.Options
+	requestBody := openAIChatRequest{
+		Model:         b.model(request),
+		Messages:      messages,
+		Stream:        stream,
+		Temperature:   min(options.Temperature, OpenAIMaxTemperature),
+		Seed:          options.Seed,
+		TopP:          options.TopP,
+		MaxTokens:     options.NumPredict,
+		Stop:          options.Stop,
+		TopK:          options.TopK,
+		MinP:          options.MinP,
+		RepeatPenalty: options.RepeatPenalty,
+	}
+	if stream {
+		requestBody.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+	requestBodyJSON, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewReader(requestBodyJSON))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+b.APIKey)
+	}
+
+	return req, nil
+}
+
+// recordUsage records the token usage reported by the service.
+func (b *OpenAIBackend) recordUsage(usage *OpenAIUsage) {
+	if usage == nil {
+		return
+	}
+	statistics.BackendTokensTotal.WithLabelValues(b.Name(), "prompt").Add(float64(usage.PromptTokens))
+	statistics.BackendTokensTotal.WithLabelValues(b.Name(), "completion").Add(float64(usage.CompletionTokens))
+}
+
+// parseOpenAIResponse parses a non-streaming chat-completions response.
+func parseOpenAIResponse(responseBody []byte) (string, *OpenAIUsage, error) {
+	r := OpenAIResponse{}
+	if err := json.Unmarshal(responseBody, &r); err != nil {
+		return "", nil, err
+	}
+	if len(r.Choices) == 0 {
+		return "", nil, errors.New("openai did not return any choices")
+	}
+
+	return strings.TrimSpace(r.Choices[0].Message.Content), r.Usage, nil
+}
+
+// concatOpenAIEvents concatenates the deltas of a streamed (server-sent events) chat-completions response.
+func concatOpenAIEvents(responseBody []byte) (string, *OpenAIUsage, error) {
+	return readOpenAIEvents(bytes.NewReader(responseBody), nil)
+}
+
+// readOpenAIEvents reads a streamed (server-sent events) chat-completions response,
+// calls onToken for every delta, if it is set, and returns the concatenated deltas.
+func readOpenAIEvents(r io.Reader, onToken func(token string)) (string, *OpenAIUsage, error) {
+	var (
+		payload strings.Builder
+		usage   *OpenAIUsage
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, isData := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "data:")
+		if !isData {
+			// Empty lines separate the events, comments and other fields are not of interest.
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		event := OpenAIResponse{}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return "", nil, err
+		}
+		for _, choice := range event.Choices {
+			payload.WriteString(choice.Delta.Content)
+			if onToken != nil && choice.Delta.Content != "" {
+				onToken(choice.Delta.Content)
+			}
+		}
+		if event.Usage != nil {
+			usage = event.Usage
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, err
+	}
+
+	return strings.TrimSpace(payload.String()), usage, nil
+}
+
+// model returns the model of the request, or the configured model if the request does not set one.
+func (b *OpenAIBackend) model(request GenerationRequest) string {
+	if request.Model != "" {
+		return request.Model
+	}
+
+	return b.Model
+}
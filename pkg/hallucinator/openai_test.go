@@ -0,0 +1,181 @@
+package hallucinator_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"codeberg.org/konterfai/konterfai/pkg/command"
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("OpenAIBackend", func() {
+	var (
+		ctx     context.Context
+		client  *MockHttpClient
+		backend *hallucinator.OpenAIBackend
+		request hallucinator.GenerationRequest
+		logger  *slog.Logger
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		logger, _ = command.SetLogger("off", "")
+		client = new(MockHttpClient)
+		backend = hallucinator.NewOpenAIBackend("http://localhost:8000", "dummy", "secret", false)
+		request = hallucinator.GenerationRequest{Prompt: "write me an article"}
+	})
+
+	It("returns the message of a chat completion", func() {
+		client.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+			return req.URL.String() == "http://localhost:8000/v1/chat/completions" &&
+				req.Header.Get("Authorization") == "Bearer secret"
+		})).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(`{"choices": [{"message": {"role": "assistant",` +
+				` "content": "This is a valid response"}}], "usage": {"prompt_tokens": 4, "completion_tokens": 5}}`)),
+		}, nil)
+		text, err := backend.Generate(ctx, client, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(Equal("This is a valid response"))
+	})
+
+	It("does not send an Authorization header without api key", func() {
+		backend.APIKey = ""
+		client.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+			return req.Header.Get("Authorization") == ""
+		})).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"choices": [{"message": {"content": "valid"}}]}`)),
+		}, nil)
+		text, err := backend.Generate(ctx, client, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(Equal("valid"))
+	})
+
+	It("concatenates the deltas of a streamed chat completion", func() {
+		backend.Stream = true
+		client.On("Do", mock.Anything).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(strings.Join([]string{
+				`data: {"choices": [{"delta": {"role": "assistant", "content": ""}}]}`,
+				``,
+				`data: {"choices": [{"delta": {"content": "This is"}}]}`,
+				``,
+				`: keep-alive`,
+				`data: {"choices": [{"delta": {"content": " a streamed response"}}]}`,
+				``,
+				`data: {"choices": [], "usage": {"prompt_tokens": 4, "completion_tokens": 5}}`,
+				``,
+				`data: [DONE]`,
+				``,
+			}, "\n"))),
+		}, nil)
+		text, err := backend.Generate(ctx, client, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(Equal("This is a streamed response"))
+	})
+
+	It("streams the deltas to onToken", func() {
+		client.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+			body, _ := io.ReadAll(req.Body)
+
+			return strings.Contains(string(body), `"stream":true`)
+		})).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(strings.Join([]string{
+				`data: {"choices": [{"delta": {"content": "This is"}}]}`,
+				`data: {"choices": [{"delta": {"content": " streamed"}}]}`,
+				`data: [DONE]`,
+			}, "\n\n"))),
+		}, nil)
+		tokens := []string{}
+		text, err := backend.GenerateStream(ctx, client, request, func(token string) {
+			tokens = append(tokens, token)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(Equal("This is streamed"))
+		Expect(tokens).To(Equal([]string{"This is", " streamed"}))
+	})
+
+	It("clamps the temperature to the range of OpenAI-compatible APIs", func() {
+		request.Options = hallucinator.GenerationOptions{Temperature: 30}
+		client.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+			body, _ := io.ReadAll(req.Body)
+
+			return strings.Contains(string(body), `"temperature":2,`)
+		})).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"choices": [{"message": {"content": "valid"}}]}`)),
+		}, nil)
+		text, err := backend.Generate(ctx, client, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(Equal("valid"))
+	})
+
+	It("sends num_predict as max_tokens and the system prompt", func() {
+		numPredict := 256
+		request.SystemPrompt = "you are a journalist"
+		request.Options = hallucinator.GenerationOptions{NumPredict: &numPredict}
+		client.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+			body, _ := io.ReadAll(req.Body)
+
+			return strings.Contains(string(body), `"max_tokens":256`) &&
+				strings.Contains(string(body), `{"role":"system","content":"you are a journalist"}`) &&
+				!strings.Contains(string(body), `"top_p"`)
+		})).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"choices": [{"message": {"content": "valid"}}]}`)),
+		}, nil)
+		text, err := backend.Generate(ctx, client, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(Equal("valid"))
+	})
+
+	It("returns an error if the service does not return 200 OK", func() {
+		client.On("Do", mock.Anything).Return(&http.Response{StatusCode: http.StatusUnauthorized}, nil)
+		_, err := backend.Generate(ctx, client, request)
+		Expect(err).To(MatchError("openai did not return 200 OK"))
+	})
+
+	It("returns an error if the service does not return any choices", func() {
+		client.On("Do", mock.Anything).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"choices": []}`)),
+		}, nil)
+		_, err := backend.Generate(ctx, client, request)
+		Expect(err).To(MatchError("openai did not return any choices"))
+	})
+
+	It("returns an error if a streamed event is malformed", func() {
+		backend.Stream = true
+		client.On("Do", mock.Anything).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`data: {"choices": [{"delta": {"content": "This`)),
+		}, nil)
+		_, err := backend.Generate(ctx, client, request)
+		Expect(err).To(MatchError("unexpected end of JSON input"))
+	})
+
+	It("can be used by the hallucinator", func() {
+		st := statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+		h := hallucinator.NewHallucinator(ctx, logger, 5, 10, 10, 10, 10, 10, 10, 10, 10, 10,
+			url.URL{Scheme: "http", Host: "localhost:8080"}, backend, 1, 10, 10, st)
+		client.On("Do", mock.Anything).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(
+				`{"choices": [{"message": {"content": "This is a valid response"}}]}`)),
+		}, nil)
+		h.HTTPClient = client
+		hal, err := h.GenerateHallucination(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hal.Text).To(Equal("This is a valid response"))
+	})
+})
@@ -0,0 +1,113 @@
+package hallucinator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// OptionRange is the range of a generation option, a value is picked at random for every generation.
+// An OptionRange that has not been parsed is unset, the backend default is used for it.
+type OptionRange struct {
+	Min float64
+	Max float64
+	set bool
+}
+
+// ParseOptionRange parses a single value ("0.9") or a range ("0.8:0.95") of a generation option.
+// An empty string results in an unset OptionRange.
+func ParseOptionRange(value string) (OptionRange, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return OptionRange{}, nil
+	}
+	minValue, maxValue, isRange := strings.Cut(value, ":")
+	if !isRange {
+		maxValue = minValue
+	}
+	lower, err := strconv.ParseFloat(strings.TrimSpace(minValue), 64)
+	if err != nil {
+		return OptionRange{}, fmt.Errorf("invalid option range %q (%w)", value, err)
+	}
+	upper, err := strconv.ParseFloat(strings.TrimSpace(maxValue), 64)
+	if err != nil {
+		return OptionRange{}, fmt.Errorf("invalid option range %q (%w)", value, err)
+	}
+	if lower > upper {
+		return OptionRange{}, fmt.Errorf("invalid option range %q (minimum is greater than maximum)", value)
+	}
+
+	return OptionRange{Min: lower, Max: upper, set: true}, nil
+}
+
+// IsSet checks if the OptionRange has been set.
+func (r OptionRange) IsSet() bool {
+	return r.set
+}
+
+// String returns the OptionRange in the format it is parsed from.
+func (r OptionRange) String() string {
+	switch {
+	case !r.set:
+		return ""
+	case r.Min == r.Max:
+		return strconv.FormatFloat(r.Min, 'f', -1, 64)
+	default:
+		return strconv.FormatFloat(r.Min, 'f', -1, 64) + ":" + strconv.FormatFloat(r.Max, 'f', -1, 64)
+	}
+}
+
+// pickFloat picks a random value from the range, it returns nil if the range is unset.
+func (r OptionRange) pickFloat() *float64 {
+	if !r.set {
+		return nil
+	}
+	value := r.Min + rand.Float64()*(r.Max-r.Min) //nolint: gosec
+
+	return &value
+}
+
+// pickInt picks a random integer from the range, it returns nil if the range is unset.
+func (r OptionRange) pickInt() *int {
+	value := r.pickFloat()
+	if value == nil {
+		return nil
+	}
+	rounded := int(math.Round(*value))
+
+	return &rounded
+}
+
+// GenerationOptionRanges are the configured ranges of the generation options.
+// Unset options are not sent to the backend, so the backend (or model) default is used.
+type GenerationOptionRanges struct {
+	Temperature   OptionRange
+	TopP          OptionRange
+	TopK          OptionRange
+	MinP          OptionRange
+	RepeatPenalty OptionRange
+	NumPredict    OptionRange
+	NumCtx        OptionRange
+	Stop          []string
+}
+
+// Pick picks the options for a single generation. The temperature falls back to the given default if it is unset.
+func (r GenerationOptionRanges) Pick(temperature float64, seed int) GenerationOptions {
+	if picked := r.Temperature.pickFloat(); picked != nil {
+		temperature = *picked
+	}
+
+	return GenerationOptions{
+		Temperature:   temperature,
+		Seed:          seed,
+		TopP:          r.TopP.pickFloat(),
+		TopK:          r.TopK.pickInt(),
+		MinP:          r.MinP.pickFloat(),
+		RepeatPenalty: r.RepeatPenalty.pickFloat(),
+		NumPredict:    r.NumPredict.pickInt(),
+		NumCtx:        r.NumCtx.pickInt(),
+		Stop:          r.Stop,
+	}
+}
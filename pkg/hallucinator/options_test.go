@@ -0,0 +1,69 @@
+package hallucinator_test
+
+import (
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generation options", func() {
+	Context("ParseOptionRange", func() {
+		It("leaves an empty value unset", func() {
+			r, err := hallucinator.ParseOptionRange("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.IsSet()).To(BeFalse())
+		})
+
+		It("parses a single value", func() {
+			r, err := hallucinator.ParseOptionRange("0.9")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.IsSet()).To(BeTrue())
+			Expect(r.Min).To(Equal(0.9))
+			Expect(r.Max).To(Equal(0.9))
+			Expect(r.String()).To(Equal("0.9"))
+		})
+
+		It("parses a range", func() {
+			r, err := hallucinator.ParseOptionRange("0.8:0.95")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.Min).To(Equal(0.8))
+			Expect(r.Max).To(Equal(0.95))
+			Expect(r.String()).To(Equal("0.8:0.95"))
+		})
+
+		It("rejects invalid ranges", func() {
+			_, err := hallucinator.ParseOptionRange("abc")
+			Expect(err).To(HaveOccurred())
+			_, err = hallucinator.ParseOptionRange("1:abc")
+			Expect(err).To(HaveOccurred())
+			_, err = hallucinator.ParseOptionRange("2:1")
+			Expect(err).To(MatchError(`invalid option range "2:1" (minimum is greater than maximum)`))
+		})
+	})
+
+	Context("Pick", func() {
+		It("leaves unset options nil and keeps the default temperature", func() {
+			options := hallucinator.GenerationOptionRanges{}.Pick(0.7, 42)
+			Expect(options.Temperature).To(Equal(0.7))
+			Expect(options.Seed).To(Equal(42))
+			Expect(options.TopP).To(BeNil())
+			Expect(options.TopK).To(BeNil())
+			Expect(options.NumPredict).To(BeNil())
+		})
+
+		It("picks values within the configured ranges", func() {
+			temperature, _ := hallucinator.ParseOptionRange("0.5:1.5")
+			topK, _ := hallucinator.ParseOptionRange("20:40")
+			ranges := hallucinator.GenerationOptionRanges{Temperature: temperature, TopK: topK, Stop: []string{"###"}}
+			for range 100 {
+				options := ranges.Pick(30, 0)
+				Expect(options.Temperature).To(BeNumerically(">=", 0.5))
+				Expect(options.Temperature).To(BeNumerically("<=", 1.5))
+				Expect(options.TopK).NotTo(BeNil())
+				Expect(*options.TopK).To(BeNumerically(">=", 20))
+				Expect(*options.TopK).To(BeNumerically("<=", 40))
+				Expect(options.Stop).To(Equal([]string{"###"}))
+			}
+		})
+	})
+})
@@ -0,0 +1,174 @@
+package hallucinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+)
+
+// ErrNoHealthyEndpoint is returned by EndpointPool.Acquire if all endpoints have been ejected.
+var ErrNoHealthyEndpoint = errors.New("no healthy endpoint available")
+
+const (
+	// BalanceRoundRobin spreads the requests evenly across the healthy endpoints.
+	BalanceRoundRobin = "round-robin"
+	// BalanceLeastOutstanding sends a request to the healthy endpoint with the fewest requests in flight.
+	BalanceLeastOutstanding = "least-outstanding"
+)
+
+// Endpoint is a single address of an EndpointPool.
+type Endpoint struct {
+	Address string
+
+	healthy     bool
+	outstanding int
+	failures    int
+}
+
+// EndpointPool spreads the requests of a backend across several endpoints.
+// An endpoint is ejected after EjectAfter consecutive failures and re-admitted by a successful health probe.
+// An EjectAfter below 1 never ejects endpoints.
+type EndpointPool struct {
+	Backend    string
+	Strategy   string
+	EjectAfter int
+
+	endpoints []*Endpoint
+	next      int
+	lock      sync.Mutex
+}
+
+// NewEndpointPool creates a new EndpointPool instance, all endpoints start healthy.
+func NewEndpointPool(backend string, addresses []string, strategy string, ejectAfter int) (*EndpointPool, error) {
+	if strategy != BalanceRoundRobin && strategy != BalanceLeastOutstanding {
+		return nil, fmt.Errorf("unknown balancing strategy %q", strategy)
+	}
+	pool := &EndpointPool{Backend: backend, Strategy: strategy, EjectAfter: ejectAfter}
+	for _, address := range addresses {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		pool.endpoints = append(pool.endpoints, &Endpoint{Address: address, healthy: true})
+		statistics.SetEndpointHealth(backend, address, true)
+	}
+	if len(pool.endpoints) == 0 {
+		return nil, errors.New("endpoint pool has no addresses")
+	}
+
+	return pool, nil
+}
+
+// Addresses returns the addresses of all endpoints, healthy or not.
+func (p *EndpointPool) Addresses() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	addresses := make([]string, 0, len(p.endpoints))
+	for _, endpoint := range p.endpoints {
+		addresses = append(addresses, endpoint.Address)
+	}
+
+	return addresses
+}
+
+// Healthy returns the addresses of the healthy endpoints.
+func (p *EndpointPool) Healthy() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	addresses := []string{}
+	for _, endpoint := range p.endpoints {
+		if endpoint.healthy {
+			addresses = append(addresses, endpoint.Address)
+		}
+	}
+
+	return addresses
+}
+
+// Acquire picks a healthy endpoint according to the strategy. The endpoint must be given back with Release.
+func (p *EndpointPool) Acquire() (*Endpoint, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var picked *Endpoint
+	for i := range len(p.endpoints) {
+		endpoint := p.endpoints[(p.next+i)%len(p.endpoints)]
+		if !endpoint.healthy {
+			continue
+		}
+		if p.Strategy == BalanceRoundRobin {
+			picked = endpoint
+			p.next = (p.next + i + 1) % len(p.endpoints)
+
+			break
+		}
+		if picked == nil || endpoint.outstanding < picked.outstanding {
+			picked = endpoint
+		}
+	}
+	if picked == nil {
+		return nil, ErrNoHealthyEndpoint
+	}
+	if p.Strategy == BalanceLeastOutstanding {
+		// Rotating the start of the search spreads the requests between endpoints with the same load.
+		p.next = (p.next + 1) % len(p.endpoints)
+	}
+	picked.outstanding++
+	statistics.SetEndpointOutstandingRequests(p.Backend, picked.Address, picked.outstanding)
+
+	return picked, nil
+}
+
+// Release gives an acquired endpoint back and records the result of the request.
+func (p *EndpointPool) Release(endpoint *Endpoint, latency time.Duration, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	endpoint.outstanding--
+	statistics.SetEndpointOutstandingRequests(p.Backend, endpoint.Address, endpoint.outstanding)
+	if errors.Is(err, context.Canceled) {
+		// The request has been canceled by the caller, it says nothing about the health of the endpoint.
+		return
+	}
+	if err == nil {
+		endpoint.failures = 0
+		statistics.RecordEndpointGeneration(p.Backend, endpoint.Address, latency)
+
+		return
+	}
+	endpoint.failures++
+	if p.EjectAfter > 0 && endpoint.failures >= p.EjectAfter && endpoint.healthy {
+		p.setHealthy(endpoint, false)
+	}
+}
+
+// CheckHealth probes every endpoint, ejecting failing and re-admitting recovered endpoints.
+func (p *EndpointPool) CheckHealth(ctx context.Context, probe func(ctx context.Context, address string) error) {
+	for _, address := range p.Addresses() {
+		err := probe(ctx, address)
+		p.lock.Lock()
+		for _, endpoint := range p.endpoints {
+			if endpoint.Address != address {
+				continue
+			}
+			if err == nil {
+				endpoint.failures = 0
+			}
+			p.setHealthy(endpoint, err == nil)
+		}
+		p.lock.Unlock()
+	}
+}
+
+// setHealthy sets the health of the endpoint, the caller must hold the lock.
+func (p *EndpointPool) setHealthy(endpoint *Endpoint, healthy bool) {
+	endpoint.healthy = healthy
+	statistics.SetEndpointHealth(p.Backend, endpoint.Address, healthy)
+}
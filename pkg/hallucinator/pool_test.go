@@ -0,0 +1,79 @@
+package hallucinator_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EndpointPool", func() {
+	addresses := []string{"http://a:11434", "http://b:11434", "http://c:11434"}
+
+	It("rejects unknown strategies and empty address lists", func() {
+		_, err := hallucinator.NewEndpointPool("ollama", addresses, "random", 3)
+		Expect(err).To(MatchError(`unknown balancing strategy "random"`))
+		_, err = hallucinator.NewEndpointPool("ollama", []string{" "}, hallucinator.BalanceRoundRobin, 3)
+		Expect(err).To(MatchError("endpoint pool has no addresses"))
+	})
+
+	It("spreads the requests round-robin", func() {
+		pool, err := hallucinator.NewEndpointPool("ollama", addresses, hallucinator.BalanceRoundRobin, 3)
+		Expect(err).NotTo(HaveOccurred())
+		picked := []string{}
+		for range 6 {
+			endpoint, err := pool.Acquire()
+			Expect(err).NotTo(HaveOccurred())
+			picked = append(picked, endpoint.Address)
+			pool.Release(endpoint, time.Millisecond, nil)
+		}
+		Expect(picked).To(Equal(append(addresses, addresses...)))
+	})
+
+	It("picks the endpoint with the fewest outstanding requests", func() {
+		pool, err := hallucinator.NewEndpointPool("ollama", addresses, hallucinator.BalanceLeastOutstanding, 3)
+		Expect(err).NotTo(HaveOccurred())
+		held := map[string]int{}
+		for range 6 {
+			endpoint, err := pool.Acquire()
+			Expect(err).NotTo(HaveOccurred())
+			held[endpoint.Address]++
+		}
+		Expect(held).To(Equal(map[string]int{addresses[0]: 2, addresses[1]: 2, addresses[2]: 2}))
+	})
+
+	It("ejects failing endpoints and re-admits them after a successful probe", func() {
+		pool, err := hallucinator.NewEndpointPool("ollama", addresses[:2], hallucinator.BalanceRoundRobin, 2)
+		Expect(err).NotTo(HaveOccurred())
+		for range 4 {
+			endpoint, err := pool.Acquire()
+			Expect(err).NotTo(HaveOccurred())
+			pool.Release(endpoint, time.Millisecond, errors.New("connection refused"))
+		}
+		Expect(pool.Healthy()).To(BeEmpty())
+		_, err = pool.Acquire()
+		Expect(err).To(MatchError(hallucinator.ErrNoHealthyEndpoint))
+
+		pool.CheckHealth(context.Background(), func(_ context.Context, address string) error {
+			if address == addresses[1] {
+				return fmt.Errorf("%s is down", address)
+			}
+
+			return nil
+		})
+		Expect(pool.Healthy()).To(Equal([]string{addresses[0]}))
+	})
+
+	It("does not count canceled requests as failures", func() {
+		pool, err := hallucinator.NewEndpointPool("ollama", addresses[:1], hallucinator.BalanceRoundRobin, 1)
+		Expect(err).NotTo(HaveOccurred())
+		endpoint, err := pool.Acquire()
+		Expect(err).NotTo(HaveOccurred())
+		pool.Release(endpoint, time.Millisecond, fmt.Errorf("stream aborted (%w)", context.Canceled))
+		Expect(pool.Healthy()).To(HaveLen(1))
+	})
+})
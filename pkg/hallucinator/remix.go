@@ -0,0 +1,196 @@
+package hallucinator
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"codeberg.org/konterfai/konterfai/pkg/dictionaries"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+)
+
+// These are the defaults of the remix engine, they are overwritten by the cli flags.
+const (
+	// DefaultRemixSpliceProbability is the probability that a sentence is replaced with a sentence of another
+	// hallucination.
+	DefaultRemixSpliceProbability = 0.2
+	// DefaultRemixSynonymProbability is the probability that a word is swapped for one of its synonyms.
+	DefaultRemixSynonymProbability = 0.3
+	// sentenceTerminators are the runes that end a sentence.
+	sentenceTerminators = ".!?。！？"
+)
+
+// Remixer derives new texts from the cached hallucinations, so a hallucination can be served many times without
+// being served verbatim. It splices in sentences of other hallucinations of the same language, reorders the
+// paragraphs and swaps words for their synonyms.
+type Remixer struct {
+	// Probability is the probability that a served page is remixed, 0 serves the cached texts verbatim.
+	Probability float64
+	// SpliceProbability is the probability that a sentence is replaced with a sentence of another hallucination.
+	SpliceProbability float64
+	// SynonymProbability is the probability that a word is swapped for one of its synonyms.
+	SynonymProbability float64
+	synonyms           map[string][]string
+}
+
+// NewRemixer creates a new Remixer with the synonym groups of the dictionaries.
+func NewRemixer(probability, spliceProbability, synonymProbability float64) *Remixer {
+	synonyms := map[string][]string{}
+	for _, group := range dictionaries.SynonymGroups {
+		for _, word := range group {
+			lowered := strings.ToLower(word)
+			if _, ok := synonyms[lowered]; !ok {
+				synonyms[lowered] = group
+			}
+		}
+	}
+
+	return &Remixer{
+		Probability:        probability,
+		SpliceProbability:  spliceProbability,
+		SynonymProbability: synonymProbability,
+		synonyms:           synonyms,
+	}
+}
+
+// Remix returns a remixed copy of the text, or the text itself if the page is not remixed.
+// The donors are the texts of other hallucinations in the same language, their sentences are spliced into the text.
+func (r *Remixer) Remix(ctx context.Context, text string, donors []string) string {
+	_, span := tracer.Start(ctx, "Remixer.Remix")
+	defer span.End()
+
+	if r.Probability <= 0 || rand.Float64() >= r.Probability { //nolint: gosec
+		return text
+	}
+	donorSentences := []string{}
+	for _, donor := range donors {
+		for _, paragraph := range splitParagraphs(donor) {
+			donorSentences = append(donorSentences, splitSentences(paragraph)...)
+		}
+	}
+	paragraphs := splitParagraphs(text)
+	for i, paragraph := range paragraphs {
+		sentences := splitSentences(paragraph)
+		for j := range sentences {
+			if len(donorSentences) > 0 && rand.Float64() < r.SpliceProbability { //nolint: gosec
+				sentences[j] = donorSentences[rand.Intn(len(donorSentences))] //nolint: gosec
+			}
+		}
+		paragraphs[i] = r.swapSynonyms(joinSentences(sentences))
+	}
+	rand.Shuffle(len(paragraphs), func(i, j int) { //nolint: gosec
+		paragraphs[i], paragraphs[j] = paragraphs[j], paragraphs[i]
+	})
+	statistics.HallucinationsRemixedTotal.Inc()
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// swapSynonyms swaps the words of the text that have synonyms with the synonym probability.
+// The capitalization of a swapped word is kept.
+func (r *Remixer) swapSynonyms(text string) string {
+	if len(r.synonyms) == 0 || r.SynonymProbability <= 0 {
+		return text
+	}
+	swapped := strings.Builder{}
+	word := strings.Builder{}
+	flush := func() {
+		swapped.WriteString(r.swapWord(word.String()))
+		word.Reset()
+	}
+	for _, c := range text {
+		if unicode.IsLetter(c) {
+			word.WriteRune(c)
+
+			continue
+		}
+		flush()
+		swapped.WriteRune(c)
+	}
+	flush()
+
+	return swapped.String()
+}
+
+// swapWord returns another random word of the synonym group of the word with the capitalization of the word, or the word itself.
+func (r *Remixer) swapWord(word string) string {
+	group, ok := r.synonyms[strings.ToLower(word)]
+	if !ok || rand.Float64() >= r.SynonymProbability { //nolint: gosec
+		return word
+	}
+	synonyms := slices.DeleteFunc(slices.Clone(group), func(synonym string) bool {
+		return strings.EqualFold(synonym, word)
+	})
+	if len(synonyms) == 0 {
+		return word
+	}
+	synonym := synonyms[rand.Intn(len(synonyms))] //nolint: gosec
+	first, _ := utf8.DecodeRuneInString(word)
+	switch {
+	case utf8.RuneCountInString(word) > 1 && strings.ToUpper(word) == word:
+		return strings.ToUpper(synonym)
+	case unicode.IsUpper(first):
+		synonymFirst, synonymSize := utf8.DecodeRuneInString(synonym)
+
+		return string(unicode.ToUpper(synonymFirst)) + synonym[synonymSize:]
+	default:
+		return strings.ToLower(synonym)
+	}
+}
+
+// splitParagraphs splits the text into its non-empty paragraphs, paragraphs are separated by a blank line.
+func splitParagraphs(text string) []string {
+	paragraphs := []string{}
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+
+	return paragraphs
+}
+
+// splitSentences splits the paragraph into its sentences. A sentence ends with a terminator that is followed by
+// a space, full-width terminators like 。 end a sentence without a space.
+func splitSentences(paragraph string) []string {
+	sentences := []string{}
+	runes := []rune(paragraph)
+	start := 0
+	for i, c := range runes {
+		if !strings.ContainsRune(sentenceTerminators, c) {
+			continue
+		}
+		if i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && c < unicode.MaxLatin1 {
+			continue
+		}
+		if sentence := strings.TrimSpace(string(runes[start : i+1])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = i + 1
+	}
+	if sentence := strings.TrimSpace(string(runes[start:])); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+
+	return sentences
+}
+
+// joinSentences joins the sentences with a space, sentences ending with a full-width terminator are joined
+// without a space.
+func joinSentences(sentences []string) string {
+	joined := strings.Builder{}
+	for i, sentence := range sentences {
+		if i > 0 {
+			last, _ := utf8.DecodeLastRuneInString(sentences[i-1])
+			if last < unicode.MaxLatin1 {
+				joined.WriteString(" ")
+			}
+		}
+		joined.WriteString(sentence)
+	}
+
+	return joined.String()
+}
@@ -0,0 +1,92 @@
+package hallucinator_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"codeberg.org/konterfai/konterfai/pkg/command"
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Remixer", func() {
+	const (
+		article = "The city council of Trieste met on Monday to discuss the new harbour.\n\n" +
+			"Several members criticised the costs. The mayor praised the plans for a tram line.\n\n" +
+			"The construction is expected to start next spring."
+		donor = "A bakery in Naples won the national pizza award. The owner thanked her family."
+	)
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("returns the text verbatim if the probability is 0", func() {
+		r := hallucinator.NewRemixer(0, 1, 1)
+		Expect(r.Remix(ctx, article, []string{donor})).To(Equal(article))
+	})
+
+	It("reorders the paragraphs without losing one", func() {
+		r := hallucinator.NewRemixer(1, 0, 0)
+		Eventually(func() string {
+			return r.Remix(ctx, article, nil)
+		}).ShouldNot(Equal(article))
+		Expect(strings.Split(r.Remix(ctx, article, nil), "\n\n")).To(ConsistOf(strings.Split(article, "\n\n")))
+	})
+
+	It("splices in the sentences of the donors", func() {
+		r := hallucinator.NewRemixer(1, 1, 0)
+		remixed := r.Remix(ctx, article, []string{donor})
+		Expect(remixed).NotTo(ContainSubstring("Trieste"))
+		Expect(strings.Split(remixed, "\n\n")).To(HaveEach(Or(
+			HavePrefix("A bakery in Naples won the national pizza award."),
+			HavePrefix("The owner thanked her family."),
+		)))
+	})
+
+	It("keeps the text if there are no donors", func() {
+		r := hallucinator.NewRemixer(1, 1, 0)
+		Expect(strings.Split(r.Remix(ctx, article, nil), "\n\n")).To(ConsistOf(strings.Split(article, "\n\n")))
+	})
+
+	It("splits sentences with full-width terminators", func() {
+		r := hallucinator.NewRemixer(1, 1, 0)
+		Expect(r.Remix(ctx, "東京で新しい駅が開業した。多くの人が訪れた。", []string{"大阪で祭りが開かれた。"})).
+			To(Equal("大阪で祭りが開かれた。大阪で祭りが開かれた。"))
+	})
+
+	It("swaps synonyms and keeps their capitalization", func() {
+		r := hallucinator.NewRemixer(1, 0, 1)
+		remixed := r.Remix(ctx, "Big news: the BIG city said it was big.", nil)
+		Expect(remixed).NotTo(ContainSubstring("big"))
+		Expect(remixed).To(MatchRegexp(`^[A-Z][a-z]+ news: the [A-Z]+ [a-z]+ [a-z]+ it was [a-z]+\.$`))
+	})
+
+	Context("in the hallucinator", func() {
+		It("remixes the served pages with the other cached hallucinations of the same language", func() {
+			const spliced = "A bakery in Naples won the national pizza award."
+			logger, _ := command.SetLogger("off", "")
+			st := statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+			h := hallucinator.NewHallucinator(ctx, logger, 5, 10, 10, 10, 0, 10, 0, 10, 10, 10,
+				url.URL{Scheme: "http", Host: "localhost:8080"}, &fakeBackend{text: article}, 1, 10, 10, st)
+			h.Remix = hallucinator.NewRemixer(1, 1, 0)
+			h.AppendHallucination(ctx, hallucinator.Hallucination{Text: article, RequestCount: 1, Language: "English"})
+			h.AppendHallucination(ctx, hallucinator.Hallucination{Text: spliced, RequestCount: 1, Language: "English"})
+			h.AppendHallucination(ctx, hallucinator.Hallucination{
+				Text: "Die Bäckerei in Neapel gewann den Preis.", RequestCount: 1, Language: "German",
+			})
+			page := h.PopHallucination(ctx)
+			// Every sentence of the article is swapped with the only sentence of the only donor.
+			Expect(page).To(ContainSubstring(spliced + " " + spliced))
+			Expect(page).NotTo(ContainSubstring("Trieste"))
+			Expect(page).NotTo(ContainSubstring("mayor"))
+			Expect(page).NotTo(ContainSubstring("construction"))
+			Expect(page).NotTo(ContainSubstring("Neapel"))
+		})
+	})
+})
@@ -0,0 +1,126 @@
+package hallucinator
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+)
+
+// StreamHallucination generates a new hallucination and writes the rendered page with write while it is generated.
+// The head of the page is written immediately, the content token by token and the tail after the generation.
+// If a Mutator is set, the content is buffered and written sentence by sentence, so every sentence is mutated as a
+// whole.
+// A valid hallucination is appended to the cache afterward, with the request that triggered it already deducted.
+func (h *Hallucinator) StreamHallucination(ctx context.Context, write func(chunk string) error) error {
+	ctx, span := tracer.Start(ctx, "Hallucinator.StreamHallucination")
+	defer span.End()
+
+	prompt, language, entities := h.generatePrompt(ctx)
+	model := pickWeightedModel(h.Models)
+	rd := h.newRenderData(ctx, language, entities, ContinueString)
+	rd.Headline = h.generateHeadline(ctx, rd.Language, entities)
+	rd.MetaData.Description = rd.Headline
+	head, tail, err := h.renderer.RenderInRandomTemplateAroundContent(ctx, rd.RenderData)
+	if err != nil {
+		return err
+	}
+	if err := write(head); err != nil {
+		return err
+	}
+	h.Logger.InfoContext(ctx, "streaming hallucination with prompt:"+prompt)
+	var writeErr error
+	writeContent := func(content string) {
+		if writeErr == nil {
+			writeErr = write(template.HTMLEscapeString(content))
+		}
+	}
+	pending := strings.Builder{}
+	text, err := h.generate(ctx, prompt, model, func(token string) {
+		if h.Mutator == nil {
+			writeContent(token)
+
+			return
+		}
+		pending.WriteString(token)
+		if end := sentencesEnd(pending.String()); end > 0 {
+			buffered := pending.String()
+			writeContent(h.Mutator.Mutate(ctx, buffered[:end]))
+			pending.Reset()
+			pending.WriteString(buffered[end:])
+		}
+	})
+	if pending.Len() > 0 {
+		writeContent(h.Mutator.Mutate(ctx, pending.String()))
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	if err != nil {
+		if writeErr := write(DreamString + tail); writeErr != nil {
+			return writeErr
+		}
+
+		return err
+	}
+	if err := write(tail); err != nil {
+		return err
+	}
+	h.appendStreamedHallucination(ctx, Hallucination{
+		Text: text, Prompt: prompt, Model: model, Language: language, Entities: entities,
+	})
+
+	return nil
+}
+
+// appendStreamedHallucination appends a streamed hallucination to the cache, if it is valid and the cache has room.
+// The request that triggered the hallucination is deducted from its request count.
+func (h *Hallucinator) appendStreamedHallucination(ctx context.Context, hallucination Hallucination) {
+	ctx, span := tracer.Start(ctx, "Hallucinator.appendStreamedHallucination")
+	defer span.End()
+
+	pl, err := h.validateBody(ctx, hallucination.Text, hallucination.Language)
+	if err != nil || h.hallucinationRequestCount < 2 || !h.reserveGenerationSlot(ctx) {
+		return
+	}
+	hallucination.Text = pl
+	hallucination.RequestCount = h.hallucinationRequestCount - 1
+	err = h.AppendHallucination(ctx, hallucination)
+	h.releaseGenerationSlot(ctx)
+	if err != nil {
+		h.Logger.ErrorContext(ctx, fmt.Sprintf("could not cache streamed hallucination (%v)", err))
+
+		return
+	}
+	h.promptsNeedUpdate.Store(true)
+
+	// Update Prometheus metrics
+	statistics.PromptsGeneratedTotal.Inc()
+}
+
+// sentencesEnd returns the index after the last complete sentence of the text, 0 if there is none.
+// A sentence is complete if its terminator is followed by a space, full-width terminators like 。 complete a sentence
+// without a space.
+func sentencesEnd(text string) int {
+	end := 0
+	for i, c := range text {
+		if !strings.ContainsRune(sentenceTerminators, c) {
+			continue
+		}
+		next := i + utf8.RuneLen(c)
+		if c >= unicode.MaxLatin1 {
+			end = next
+
+			continue
+		}
+		if following, _ := utf8.DecodeRuneInString(text[next:]); next < len(text) && unicode.IsSpace(following) {
+			end = next
+		}
+	}
+
+	return end
+}
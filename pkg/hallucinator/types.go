@@ -2,17 +2,24 @@ package hallucinator
 
 // Hallucination is a hallucination generated by the Hallucinator.
 type Hallucination struct {
-	Text         string
-	Prompt       string
-	RequestCount int
+	Text         string `json:"text"`
+	Prompt       string `json:"prompt"`
+	RequestCount int    `json:"requestCount"`
+	Model        string `json:"model,omitempty"`
+	Language     string `json:"language,omitempty"`
+	// Entities are the ids of the entities of the world the hallucination is about.
+	Entities []int `json:"entities,omitempty"`
+	// signature is the MinHash signature of the text, it is only computed for near-duplicate detection.
+	signature Signature
 }
 
 // ollamaJSONRequest is the request structure for the Ollama API.
 type ollamaJSONRequest struct {
-	Model    string          `json:"model"`
-	Messages []OllamaMessage `json:"messages"`
-	Stream   bool            `json:"stream"`
-	Options  ollamaOptions   `json:"options"`
+	Model     string          `json:"model"`
+	Messages  []OllamaMessage `json:"messages"`
+	Stream    bool            `json:"stream"`
+	Options   ollamaOptions   `json:"options"`
+	KeepAlive string          `json:"keep_alive,omitempty"` //nolint: tagliatelle
 }
 
 // OllamaMessage is the message structure for the Ollama API.
@@ -23,8 +30,15 @@ type OllamaMessage struct {
 
 // ollamaOptions is the options structure for the Ollama API.
 type ollamaOptions struct {
-	Temperature float64 `json:"temperature"`
-	Seed        int     `json:"seed"`
+	Temperature   float64  `json:"temperature"`
+	Seed          int      `json:"seed"`
+	TopP          *float64 `json:"top_p,omitempty"`          //nolint: tagliatelle
+	TopK          *int     `json:"top_k,omitempty"`          //nolint: tagliatelle
+	MinP          *float64 `json:"min_p,omitempty"`          //nolint: tagliatelle
+	RepeatPenalty *float64 `json:"repeat_penalty,omitempty"` //nolint: tagliatelle
+	NumPredict    *int     `json:"num_predict,omitempty"`    //nolint: tagliatelle
+	NumCtx        *int     `json:"num_ctx,omitempty"`        //nolint: tagliatelle
+	Stop          []string `json:"stop,omitempty"`
 }
 
 // OllamaResponse is the response structure for the Ollama API.
@@ -40,3 +54,84 @@ type OllamaResponse struct {
 	EvalCount       int           `json:"eval_count"`        //nolint: tagliatelle
 	EvalDuration    int           `json:"eval_duration"`     //nolint: tagliatelle
 }
+
+// OllamaTagsResponse is the response structure for the model list of the Ollama API.
+type OllamaTagsResponse struct {
+	Models []OllamaModel `json:"models"`
+}
+
+// OllamaModel is the model structure for the Ollama API.
+type OllamaModel struct {
+	Name  string `json:"name"`
+	Model string `json:"model"`
+}
+
+// ollamaPullRequest is the request structure for pulling a model with the Ollama API.
+type ollamaPullRequest struct {
+	Model  string `json:"model"`
+	Stream bool   `json:"stream"`
+}
+
+// OllamaPullResponse is the response structure for pulling a model with the Ollama API.
+type OllamaPullResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+// ollamaPreloadRequest is the request structure for loading a model into memory with the Ollama API.
+type ollamaPreloadRequest struct {
+	Model     string `json:"model"`
+	KeepAlive string `json:"keep_alive"` //nolint: tagliatelle
+}
+
+// openAIChatRequest is the request structure for OpenAI-compatible chat-completions APIs.
+// TopK, MinP and RepeatPenalty are not part of the OpenAI API, but understood by llama.cpp server and vLLM.
+type openAIChatRequest struct {
+	Model         string               `json:"model"`
+	Messages      []OpenAIMessage      `json:"messages"`
+	Stream        bool                 `json:"stream"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"` //nolint: tagliatelle
+	Temperature   float64              `json:"temperature"`
+	Seed          int                  `json:"seed"`
+	TopP          *float64             `json:"top_p,omitempty"`      //nolint: tagliatelle
+	MaxTokens     *int                 `json:"max_tokens,omitempty"` //nolint: tagliatelle
+	Stop          []string             `json:"stop,omitempty"`
+	TopK          *int                 `json:"top_k,omitempty"`          //nolint: tagliatelle
+	MinP          *float64             `json:"min_p,omitempty"`          //nolint: tagliatelle
+	RepeatPenalty *float64             `json:"repeat_penalty,omitempty"` //nolint: tagliatelle
+}
+
+// openAIStreamOptions is the stream options structure for OpenAI-compatible chat-completions APIs.
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"` //nolint: tagliatelle
+}
+
+// OpenAIMessage is the message structure for OpenAI-compatible chat-completions APIs.
+type OpenAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// OpenAIResponse is the response structure for OpenAI-compatible chat-completions APIs.
+// When streaming, each server-sent event carries one OpenAIResponse with the Delta of the choices set.
+type OpenAIResponse struct {
+	ID      string         `json:"id"`
+	Model   string         `json:"model"`
+	Choices []OpenAIChoice `json:"choices"`
+	Usage   *OpenAIUsage   `json:"usage,omitempty"`
+}
+
+// OpenAIChoice is the choice structure for OpenAI-compatible chat-completions APIs.
+type OpenAIChoice struct {
+	Index        int           `json:"index"`
+	Message      OpenAIMessage `json:"message"`
+	Delta        OpenAIMessage `json:"delta"`
+	FinishReason string        `json:"finish_reason"` //nolint: tagliatelle
+}
+
+// OpenAIUsage is the token usage structure for OpenAI-compatible chat-completions APIs.
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`     //nolint: tagliatelle
+	CompletionTokens int `json:"completion_tokens"` //nolint: tagliatelle
+	TotalTokens      int `json:"total_tokens"`      //nolint: tagliatelle
+}
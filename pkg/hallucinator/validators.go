@@ -0,0 +1,295 @@
+package hallucinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"codeberg.org/konterfai/konterfai/pkg/dictionaries"
+)
+
+// These are the defaults of the validators, they are overwritten by the cli flags.
+const (
+	// DefaultMinUniqueRatio is the minimal share of distinct phrases in a hallucination.
+	DefaultMinUniqueRatio = 0.3
+	// DefaultMinScriptRatio is the minimal share of letters written in the script of the language.
+	DefaultMinScriptRatio = 0.5
+	// repetitionPhraseLength is the number of words of the phrases the repetition validator compares.
+	repetitionPhraseLength = 3
+)
+
+// Validator checks a generated hallucination before it is cached.
+type Validator interface {
+	// Name is the reason that is recorded when the validator rejects a hallucination.
+	Name() string
+	// Validate returns an error if the hallucination in the given language is rejected.
+	// The error completes the sentence "<backend> returned ...".
+	Validate(ctx context.Context, text, language string) error
+}
+
+// ValidatorChain runs validators in order, the first rejection stops the chain.
+type ValidatorChain []Validator
+
+// Validate runs the validators, it returns the name of the validator that rejected the hallucination and its error.
+func (c ValidatorChain) Validate(ctx context.Context, text, language string) (string, error) {
+	ctx, span := tracer.Start(ctx, "ValidatorChain.Validate")
+	defer span.End()
+
+	for _, validator := range c {
+		if err := validator.Validate(ctx, text, language); err != nil {
+			return validator.Name(), err
+		}
+	}
+
+	return "", nil
+}
+
+// NewDefaultValidators returns the validators that are used if none are configured.
+func NewDefaultValidators(minimalLength int) ValidatorChain {
+	refusals, _ := NewRefusalValidator(invalidResultsRegexps) // the built-in patterns are valid.
+
+	return ValidatorChain{
+		refusals,
+		&MinLengthValidator{MinimalLength: minimalLength},
+		&RepetitionValidator{MinUniqueRatio: DefaultMinUniqueRatio},
+	}
+}
+
+// RefusalValidator rejects hallucinations in which the model refuses to write the article.
+type RefusalValidator struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRefusalValidator creates a new RefusalValidator, the patterns are compiled once and matched case-insensitively.
+func NewRefusalValidator(patterns []string) (*RefusalValidator, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid refusal pattern %q (%w)", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	return &RefusalValidator{patterns: compiled}, nil
+}
+
+// LoadRefusalValidator creates a new RefusalValidator with the built-in patterns and the patterns of the file,
+// one regular expression per line. An empty path only uses the built-in patterns.
+func LoadRefusalValidator(path string) (*RefusalValidator, error) {
+	patterns := slices.Clone(invalidResultsRegexps)
+	if path != "" {
+		lines, err := dictionaries.ReadLines(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read refusal patterns (%w)", err)
+		}
+		patterns = append(patterns, lines...)
+	}
+
+	return NewRefusalValidator(patterns)
+}
+
+// Name returns the name of the refusal validator.
+func (v *RefusalValidator) Name() string {
+	return "refusal"
+}
+
+// Validate rejects the hallucination if it matches one of the patterns.
+func (v *RefusalValidator) Validate(ctx context.Context, text, _ string) error {
+	_, span := tracer.Start(ctx, "RefusalValidator.Validate")
+	defer span.End()
+
+	for _, re := range v.patterns {
+		if re.MatchString(text) {
+			return fmt.Errorf("a refusal (matches %q)", strings.TrimPrefix(re.String(), "(?i)"))
+		}
+	}
+
+	return nil
+}
+
+// MinLengthValidator rejects hallucinations that are shorter than MinimalLength characters, <1 disables the check.
+type MinLengthValidator struct {
+	MinimalLength int
+}
+
+// Name returns the name of the minimal length validator.
+func (v *MinLengthValidator) Name() string {
+	return "too_short"
+}
+
+// Validate rejects the hallucination if it is too short.
+func (v *MinLengthValidator) Validate(ctx context.Context, text, _ string) error {
+	_, span := tracer.Start(ctx, "MinLengthValidator.Validate")
+	defer span.End()
+
+	if v.MinimalLength > 0 && utf8.RuneCountInString(text) < v.MinimalLength {
+		return errors.New("a hallucination that is too short")
+	}
+
+	return nil
+}
+
+// RepetitionValidator rejects degenerate hallucinations in which the model repeats itself in a loop.
+// The share of distinct phrases of three words must be at least MinUniqueRatio, 0 disables the check.
+type RepetitionValidator struct {
+	MinUniqueRatio float64
+}
+
+// Name returns the name of the repetition validator.
+func (v *RepetitionValidator) Name() string {
+	return "repetition"
+}
+
+// Validate rejects the hallucination if too few of its phrases are distinct.
+func (v *RepetitionValidator) Validate(ctx context.Context, text, _ string) error {
+	_, span := tracer.Start(ctx, "RepetitionValidator.Validate")
+	defer span.End()
+
+	words := strings.Fields(strings.ToLower(text))
+	if v.MinUniqueRatio <= 0 || len(words) < 2*repetitionPhraseLength {
+		return nil
+	}
+	phrases := map[string]struct{}{}
+	total := len(words) - repetitionPhraseLength + 1
+	for i := range total {
+		phrases[strings.Join(words[i:i+repetitionPhraseLength], " ")] = struct{}{}
+	}
+	ratio := float64(len(phrases)) / float64(total)
+	if ratio < v.MinUniqueRatio {
+		return fmt.Errorf("a repetitive hallucination (%.0f%% distinct phrases)", ratio*100)
+	}
+
+	return nil
+}
+
+// DenylistValidator rejects hallucinations that contain one of the phrases, case-insensitively.
+type DenylistValidator struct {
+	phrases []string
+}
+
+// NewDenylistValidator creates a new DenylistValidator.
+func NewDenylistValidator(phrases []string) *DenylistValidator {
+	lowered := make([]string, 0, len(phrases))
+	for _, phrase := range phrases {
+		lowered = append(lowered, strings.ToLower(phrase))
+	}
+
+	return &DenylistValidator{phrases: lowered}
+}
+
+// LoadDenylistValidator creates a new DenylistValidator with the phrases of the file, one phrase per line.
+func LoadDenylistValidator(path string) (*DenylistValidator, error) {
+	phrases, err := dictionaries.ReadLines(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read denylist (%w)", err)
+	}
+
+	return NewDenylistValidator(phrases), nil
+}
+
+// Name returns the name of the denylist validator.
+func (v *DenylistValidator) Name() string {
+	return "denylist"
+}
+
+// Validate rejects the hallucination if it contains one of the phrases.
+func (v *DenylistValidator) Validate(ctx context.Context, text, _ string) error {
+	_, span := tracer.Start(ctx, "DenylistValidator.Validate")
+	defer span.End()
+
+	lowered := strings.ToLower(text)
+	for _, phrase := range v.phrases {
+		if strings.Contains(lowered, phrase) {
+			return fmt.Errorf("a hallucination with the denied phrase %q", phrase)
+		}
+	}
+
+	return nil
+}
+
+// LanguageValidator rejects hallucinations that are not written in the language of the prompt.
+// At least MinScriptRatio of the letters must be written in one of the scripts of the language. Languages that
+// share a script are told apart by their stopwords. Languages without scripts or stopwords are not checked.
+type LanguageValidator struct {
+	MinScriptRatio float64
+}
+
+// Name returns the name of the language validator.
+func (v *LanguageValidator) Name() string {
+	return "language"
+}
+
+// Validate rejects the hallucination if it is written in another language.
+func (v *LanguageValidator) Validate(ctx context.Context, text, language string) error {
+	_, span := tracer.Start(ctx, "LanguageValidator.Validate")
+	defer span.End()
+
+	metadata := dictionaries.LookupLanguage(language)
+	scripts := []*unicode.RangeTable{}
+	for _, script := range metadata.Scripts {
+		if table, ok := unicode.Scripts[script]; ok {
+			scripts = append(scripts, table)
+		}
+	}
+	if len(scripts) > 0 {
+		letters, matching := 0, 0
+		for _, r := range text {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			letters++
+			if unicode.In(r, scripts...) {
+				matching++
+			}
+		}
+		if letters > 0 && float64(matching)/float64(letters) < v.MinScriptRatio {
+			return fmt.Errorf("a hallucination that is not written in %s (%d of %d letters in its script)",
+				language, matching, letters)
+		}
+	}
+	if detected := detectLanguageByStopwords(text); detected != "" && detected != language &&
+		len(dictionaries.Stopwords[language]) > 0 {
+		return fmt.Errorf("a hallucination that is not written in %s (looks like %s)", language, detected)
+	}
+
+	return nil
+}
+
+// detectLanguageByStopwords returns the language whose stopwords are most frequent in the text.
+// It returns an empty string if no language is clearly ahead of the others.
+func detectLanguageByStopwords(text string) string {
+	counts := map[string]int{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		for language, stopwords := range dictionaries.Stopwords {
+			for _, stopword := range stopwords {
+				if word == stopword {
+					counts[language]++
+				}
+			}
+		}
+	}
+	best, runnerUp := "", 0
+	for language, count := range counts {
+		switch {
+		case best == "" || count > counts[best]:
+			runnerUp = counts[best]
+			best = language
+		case count > runnerUp:
+			runnerUp = count
+		}
+	}
+	// A language is only detected if it has twice as many stopwords as any other language.
+	if best == "" || counts[best] < 2*runnerUp || counts[best] < 3 {
+		return ""
+	}
+
+	return best
+}
@@ -0,0 +1,123 @@
+package hallucinator_test
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"codeberg.org/konterfai/konterfai/pkg/command"
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var _ = Describe("Validators", func() {
+	const article = "The city council of Trieste met on Monday to discuss the new harbour. " +
+		"Several members criticised the costs, while the mayor praised the plans for a tram line along the coast."
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Context("RefusalValidator", func() {
+		It("rejects refusals of the built-in patterns case-insensitively", func() {
+			v, err := hallucinator.LoadRefusalValidator("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v.Validate(ctx, "SORRY, BUT I CAN'T ASSIST WITH THAT.", "")).To(MatchError(ContainSubstring("a refusal")))
+			Expect(v.Validate(ctx, article, "")).To(Succeed())
+		})
+
+		It("loads additional patterns from a file", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "refusals.txt")
+			Expect(os.WriteFile(path, []byte("# comment\nas a language model\n"), 0o600)).To(Succeed())
+			v, err := hallucinator.LoadRefusalValidator(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v.Validate(ctx, "As a Language Model I write no news.", "")).To(HaveOccurred())
+		})
+
+		It("returns an error for invalid patterns", func() {
+			_, err := hallucinator.NewRefusalValidator([]string{"("})
+			Expect(err).To(MatchError(ContainSubstring("invalid refusal pattern")))
+		})
+	})
+
+	Context("MinLengthValidator", func() {
+		It("rejects short hallucinations", func() {
+			v := &hallucinator.MinLengthValidator{MinimalLength: 500}
+			Expect(v.Validate(ctx, article, "")).To(MatchError("a hallucination that is too short"))
+		})
+
+		It("counts characters instead of bytes", func() {
+			v := &hallucinator.MinLengthValidator{MinimalLength: 5}
+			Expect(v.Validate(ctx, "日本語", "")).To(HaveOccurred())
+		})
+
+		It("is disabled by a minimal length < 1", func() {
+			v := &hallucinator.MinLengthValidator{MinimalLength: 0}
+			Expect(v.Validate(ctx, "short", "")).To(Succeed())
+		})
+	})
+
+	Context("RepetitionValidator", func() {
+		It("rejects degenerate loops", func() {
+			v := &hallucinator.RepetitionValidator{MinUniqueRatio: hallucinator.DefaultMinUniqueRatio}
+			Expect(v.Validate(ctx, strings.Repeat("the news of the day ", 20), "")).
+				To(MatchError(ContainSubstring("a repetitive hallucination")))
+			Expect(v.Validate(ctx, article, "")).To(Succeed())
+		})
+	})
+
+	Context("DenylistValidator", func() {
+		It("rejects denied phrases case-insensitively", func() {
+			v := hallucinator.NewDenylistValidator([]string{"Tram Line"})
+			Expect(v.Validate(ctx, article, "")).To(MatchError(ContainSubstring(`"tram line"`)))
+			Expect(v.Validate(ctx, "A story without it.", "")).To(Succeed())
+		})
+	})
+
+	Context("LanguageValidator", func() {
+		var v *hallucinator.LanguageValidator
+
+		BeforeEach(func() {
+			v = &hallucinator.LanguageValidator{MinScriptRatio: hallucinator.DefaultMinScriptRatio}
+		})
+
+		It("rejects text in another script", func() {
+			Expect(v.Validate(ctx, article, "Thai")).To(MatchError(ContainSubstring("not written in Thai")))
+			Expect(v.Validate(ctx, "ข่าววันนี้ที่กรุงเทพมหานคร", "Thai")).To(Succeed())
+		})
+
+		It("tells languages of the same script apart by their stopwords", func() {
+			Expect(v.Validate(ctx, article, "German")).To(MatchError(ContainSubstring("looks like English")))
+			Expect(v.Validate(ctx, article, "English")).To(Succeed())
+		})
+
+		It("does not check unknown languages", func() {
+			Expect(v.Validate(ctx, article, "Klingon")).To(Succeed())
+		})
+	})
+
+	Context("in the hallucinator", func() {
+		It("counts the rejections per reason", func() {
+			logger, _ := command.SetLogger("off", "")
+			st := statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+			h := hallucinator.NewHallucinator(ctx, logger, 5, 10, 10, 10, 0, 10, 10, 10, 10, 10,
+				url.URL{Scheme: "http", Host: "localhost:8080"},
+				&fakeBackend{text: "I cannot proceed as this question pertains to sensitive topics."}, 1, 10, 10, st)
+			counter := statistics.HallucinationsRejectedTotal.WithLabelValues("fake", "refusal")
+			before := &dto.Metric{}
+			Expect(counter.Write(before)).To(Succeed())
+			_, err := h.GenerateHallucination(ctx)
+			Expect(err).To(MatchError(HavePrefix("fake returned a refusal")))
+			after := &dto.Metric{}
+			Expect(counter.Write(after)).To(Succeed())
+			Expect(after.GetCounter().GetValue()).To(Equal(before.GetCounter().GetValue() + 1))
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package hallucinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"codeberg.org/konterfai/konterfai/pkg/helpers/functions"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+)
+
+// runGenerationWorker generates hallucinations as long as the cache has empty slots.
+// Every worker has its own exponential backoff with jitter, which is reset after a successful generation.
+// While the circuit breaker is open, the workers wait for it instead of calling the backend.
+func (h *Hallucinator) runGenerationWorker(ctx context.Context, id int) {
+	// No need to trace this function as it is an endless loop.
+	failures := 0
+	for ctx.Err() == nil {
+		if !h.reserveGenerationSlot(ctx) {
+			functions.SleepWithContext(ctx, h.Logger, h.Interval)
+
+			continue
+		}
+		h.Logger.InfoContext(ctx, fmt.Sprintf("worker %d: hallucinations cache has empty slots, generating more... [%d/%d]",
+			id, h.GetHallucinationCount(ctx)+1, h.hallucinationCacheSize))
+		hal, err := h.GenerateHallucination(ctx)
+		if errors.Is(err, ErrCircuitOpen) {
+			h.releaseGenerationSlot(ctx)
+			h.Logger.DebugContext(ctx, fmt.Sprintf("worker %d: %v", id, err))
+			functions.SleepWithContext(ctx, h.Logger, max(h.CircuitBreaker.RetryIn(), h.Interval))
+
+			continue
+		}
+		if err != nil {
+			h.releaseGenerationSlot(ctx)
+			backoff := exponentialBackoff(h.Interval, h.CircuitBreaker.MaxBackoff, failures)
+			failures++
+			h.Logger.ErrorContext(ctx, fmt.Sprintf("worker %d: could not generate hallucination, retrying in %s (%v)",
+				id, backoff.Round(time.Millisecond), err))
+			functions.SleepWithContext(ctx, h.Logger, backoff)
+
+			continue
+		}
+		failures = 0
+		// The hallucination is appended before the slot is released, so the cache never exceeds its size.
+		if err := h.AppendHallucination(ctx, hal); err != nil {
+			h.releaseGenerationSlot(ctx)
+			h.Logger.ErrorContext(ctx, fmt.Sprintf("worker %d: could not cache hallucination (%v)", id, err))
+
+			continue
+		}
+		h.releaseGenerationSlot(ctx)
+		h.promptsNeedUpdate.Store(true)
+
+		// Update Prometheus metrics
+		statistics.PromptsGeneratedTotal.Inc()
+	}
+}
+
+// reserveGenerationSlot reserves a slot in the cache for a generation, it returns false if the cache is full.
+func (h *Hallucinator) reserveGenerationSlot(ctx context.Context) bool {
+	ctx, span := tracer.Start(ctx, "Hallucinator.reserveGenerationSlot")
+	defer span.End()
+
+	h.pendingGenerationsLock.Lock()
+	defer h.pendingGenerationsLock.Unlock()
+	if h.GetHallucinationCount(ctx)+h.pendingGenerations >= h.hallucinationCacheSize {
+		return false
+	}
+	h.pendingGenerations++
+
+	return true
+}
+
+// releaseGenerationSlot releases a slot that has been reserved by reserveGenerationSlot.
+func (h *Hallucinator) releaseGenerationSlot(ctx context.Context) {
+	_, span := tracer.Start(ctx, "Hallucinator.releaseGenerationSlot")
+	defer span.End()
+
+	h.pendingGenerationsLock.Lock()
+	defer h.pendingGenerationsLock.Unlock()
+	h.pendingGenerations--
+}
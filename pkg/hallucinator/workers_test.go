@@ -0,0 +1,91 @@
+package hallucinator_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"codeberg.org/konterfai/konterfai/pkg/command"
+	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// countingBackend is a Backend that counts its generations and fails a given number of times before succeeding.
+type countingBackend struct {
+	calls    atomic.Int32
+	failures int32
+	delay    time.Duration
+}
+
+// Name returns the name of the counting backend.
+func (b *countingBackend) Name() string {
+	return "counting"
+}
+
+// Generate returns a valid text after the configured number of failures.
+func (b *countingBackend) Generate(_ context.Context, _ hallucinator.HTTPClient, _ hallucinator.GenerationRequest) (string, error) {
+	time.Sleep(b.delay)
+	if b.calls.Add(1) <= b.failures {
+		return "", errors.New("generation failed")
+	}
+
+	sentences := []string{}
+	for i := range 10 {
+		sentences = append(sentences, fmt.Sprintf("This is sentence %d of a valid hallucination.", i))
+	}
+
+	return strings.Join(sentences, " "), nil
+}
+
+var _ = Describe("Generation workers", func() {
+	var (
+		ctx     context.Context
+		cancel  context.CancelFunc
+		h       *hallucinator.Hallucinator
+		backend *countingBackend
+		done    chan error
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		logger, _ := command.SetLogger("off", "")
+		st := statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+		backend = &countingBackend{delay: 10 * time.Millisecond}
+		h = hallucinator.NewHallucinator(ctx, logger, time.Millisecond, 5, 10, 10, 100, 10, 10, 10, 10, 10,
+			url.URL{Scheme: "http", Host: "localhost:8080"}, backend, 1, 10, 10, st)
+		h.GenerationWorkers = 4
+		h.CircuitBreaker = hallucinator.NewCircuitBreaker(5, time.Millisecond, 10*time.Millisecond)
+		done = make(chan error)
+	})
+
+	AfterEach(func() {
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("fills the cache without exceeding its size", func() {
+		go func() { done <- h.Start(ctx) }()
+		Eventually(func() int {
+			return h.GetHallucinationCount(ctx)
+		}).Should(Equal(5))
+		Consistently(func() int {
+			return h.GetHallucinationCount(ctx)
+		}, 100*time.Millisecond).Should(Equal(5))
+		Expect(backend.calls.Load()).To(Equal(int32(5)))
+	})
+
+	It("keeps generating after failed generations", func() {
+		backend.failures = 6
+		go func() { done <- h.Start(ctx) }()
+		Eventually(func() int {
+			return h.GetHallucinationCount(ctx)
+		}).Should(Equal(5))
+		Expect(backend.calls.Load()).To(Equal(int32(11)))
+	})
+})
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"math/rand"
+	"net/http"
 	"strconv"
 	"time"
 
@@ -14,6 +15,9 @@ import (
 
 var tracer = otel.Tracer("codeberg.org/konterfai/konterfai/pkg/helpers/functions")
 
+// ShutdownTimeout is the time open connections get to finish when a server shuts down, before they are closed.
+const ShutdownTimeout = 10 * time.Second
+
 // PickRandomDate picks a random date.
 func PickRandomDate(ctx context.Context) string {
 	_, span := tracer.Start(ctx, "PickRandomDate")
@@ -112,3 +116,21 @@ func SleepWithContext(ctx context.Context, logger *slog.Logger, duration time.Du
 	case <-t.C:
 	}
 }
+
+// ShutdownServer shuts the server down gracefully and closes the connections that are still open after the timeout,
+// like tarpitted or streamed responses, which do not notice the shutdown.
+func ShutdownServer(ctx context.Context, logger *slog.Logger, server *http.Server, timeout time.Duration) {
+	ctx, span := tracer.Start(ctx, "ShutdownServer")
+	defer span.End()
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	err := server.Shutdown(shutdownCtx)
+	if err == nil {
+		return
+	}
+	logger.WarnContext(ctx, fmt.Sprintf("could not shut down server gracefully, closing it (%v)", err))
+	if err := server.Close(); err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("could not close server (%v)", err))
+	}
+}
@@ -3,6 +3,8 @@ package functions_test
 import (
 	"context"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -159,4 +161,29 @@ var _ = Describe("Functions", func() {
 			Expect(time.Since(start)).To(BeNumerically("<", duration))
 		})
 	})
+
+	Context("ShutdownServer", func() {
+		It("closes the connections that are still open after the timeout", func() {
+			logger, _ := command.SetLogger("off", "")
+			started := make(chan struct{})
+			// The handler does not notice a graceful shutdown, only the closed connection.
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.(http.Flusher).Flush()
+				close(started)
+				<-r.Context().Done()
+			}))
+			defer server.Close()
+			go func() {
+				defer GinkgoRecover()
+				res, err := http.Get(server.URL) //nolint: noctx
+				Expect(err).NotTo(HaveOccurred())
+				res.Body.Close() //nolint: errcheck,gosec
+			}()
+			<-started
+			start := time.Now()
+			functions.ShutdownServer(ctx, logger, server.Config, 100*time.Millisecond)
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		})
+	})
 })
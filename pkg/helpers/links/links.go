@@ -20,7 +20,17 @@ func RandomLink(ctx context.Context, baseURL url.URL, subdirectories,
 	ctx, span := tracer.Start(ctx, "RandomLink")
 	defer span.End()
 
-	subDirectoryPath := generateSubDirectories(ctx, subdirectories)
+	return RandomLocalizedLink(ctx, "", baseURL, subdirectories, variablesCount, linkHasVariablesProbability)
+}
+
+// RandomLocalizedLink generates a random link like RandomLink, the subdirectories use the words of the language.
+func RandomLocalizedLink(ctx context.Context, language string, baseURL url.URL, subdirectories,
+	variablesCount int, linkHasVariablesProbability float64,
+) string {
+	ctx, span := tracer.Start(ctx, "RandomLocalizedLink")
+	defer span.End()
+
+	subDirectoryPath := generateSubDirectories(ctx, dictionaries.VocabularyFor(language), subdirectories)
 
 	variables := generateVariables(ctx, variablesCount, linkHasVariablesProbability)
 
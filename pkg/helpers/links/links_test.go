@@ -60,6 +60,16 @@ var _ = Describe("Links", func() {
 		})
 	})
 
+	Context("RandomLocalizedLink", func() {
+		It("should join compound words in the path by dashes", func() {
+			for i := 0; i < totalTests; i++ {
+				link := links.RandomLocalizedLink(ctx, "Vietnamese", url, 3, 0, 0)
+				Expect(link).To(HavePrefix("https://example.com/"))
+				Expect(link).NotTo(ContainSubstring(" "))
+			}
+		})
+	})
+
 	Context("RandomSimpleLink", func() {
 		It("should return a random simple link", func() {
 			for i := 0; i < totalTests; i++ {
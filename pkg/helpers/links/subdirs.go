@@ -12,7 +12,7 @@ import (
 )
 
 // generateSubDirectories generates a random number of subdirectories.
-func generateSubDirectories(ctx context.Context, subdirectories int) string {
+func generateSubDirectories(ctx context.Context, vocabulary dictionaries.Vocabulary, subdirectories int) string {
 	ctx, span := tracer.Start(ctx, "generateSubDirectories")
 	defer span.End()
 
@@ -23,14 +23,14 @@ func generateSubDirectories(ctx context.Context, subdirectories int) string {
 	sd := []string{}
 	subcount := rand.Intn(subdirectories) + 1 //nolint:gosec
 	for range subcount {
-		sd = append(sd, getSubDirectoryString(ctx))
+		sd = append(sd, getSubDirectoryString(ctx, vocabulary))
 	}
 
 	return strings.Join(sd, "/")
 }
 
 // getSubDirectoryString returns a random subdirectory string.
-func getSubDirectoryString(ctx context.Context) string { //nolint:cyclop
+func getSubDirectoryString(ctx context.Context, vocabulary dictionaries.Vocabulary) string { //nolint:cyclop
 	ctx, span := tracer.Start(ctx, "getSubDirectoryString")
 	defer span.End()
 
@@ -39,35 +39,35 @@ func getSubDirectoryString(ctx context.Context) string { //nolint:cyclop
 	case types.UUIDPath:
 		return uuid.NewString()
 	case types.NounPath:
-		return functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns)
+		return pickPathWord(ctx, &vocabulary.Nouns)
 	case types.TwoNounPath:
 		return strings.Join([]string{
-			functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
-			functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
+			pickPathWord(ctx, &vocabulary.Nouns),
+			pickPathWord(ctx, &vocabulary.Nouns),
 		}, "")
 	case types.ThreeNounPath:
 		return strings.Join([]string{
-			functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
-			functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
-			functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
+			pickPathWord(ctx, &vocabulary.Nouns),
+			pickPathWord(ctx, &vocabulary.Nouns),
+			pickPathWord(ctx, &vocabulary.Nouns),
 		}, "")
 	case types.TwoNounDashedPath:
 		return strings.Join([]string{
-			functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
-			functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
+			pickPathWord(ctx, &vocabulary.Nouns),
+			pickPathWord(ctx, &vocabulary.Nouns),
 		}, "-")
 	case types.ThreeNounDashedPath:
 		return strings.Join([]string{
-			functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
-			functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
-			functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
+			pickPathWord(ctx, &vocabulary.Nouns),
+			pickPathWord(ctx, &vocabulary.Nouns),
+			pickPathWord(ctx, &vocabulary.Nouns),
 		}, "-")
 	case types.VerbPath:
-		return functions.PickRandomStringFromSlice(ctx, &dictionaries.Verbs)
+		return pickPathWord(ctx, &vocabulary.Verbs)
 	case types.VerbNounPath:
 		return strings.Join([]string{
-			functions.PickRandomStringFromSlice(ctx, &dictionaries.Verbs),
-			functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
+			pickPathWord(ctx, &vocabulary.Verbs),
+			pickPathWord(ctx, &vocabulary.Nouns),
 		}, "")
 	case types.DatePath:
 		return functions.PickRandomDate(ctx)
@@ -81,3 +81,8 @@ func getSubDirectoryString(ctx context.Context) string { //nolint:cyclop
 		return fallbackDefaultWord
 	}
 }
+
+// pickPathWord returns a random word of the list for a path, the parts of compound words are joined by dashes.
+func pickPathWord(ctx context.Context, words *[]string) string {
+	return strings.Join(strings.Fields(functions.PickRandomStringFromSlice(ctx, words)), "-")
+}
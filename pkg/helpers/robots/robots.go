@@ -65,3 +65,9 @@ func RobotsTxt(r *http.Request) []byte { //nolint:revive
 
 	return slices.Concat(robotsTxt...)
 }
+
+// IsDisallowed returns true if the robots.txt of RobotsTxt disallows the path for the user agent that requested it.
+// Every user agent in it is disallowed from everything, the robots.txt itself aside.
+func IsDisallowed(path string) bool {
+	return path != "/robots.txt"
+}
@@ -38,5 +38,18 @@ var _ = Describe("Robots", func() {
 		It("should not return the same robots.txt file", func() {
 			Expect(robots.RobotsTxt(r)).NotTo(Equal(robots.RobotsTxt(r)))
 		})
+
+		It("should disallow the requesting user agent from everything", func() {
+			r.Header = http.Header{"User-Agent": {"Mozilla/5.0"}}
+			Expect(string(robots.RobotsTxt(r))).To(ContainSubstring("User-Agent: Mozilla/5.0\nDisallow: /\n"))
+		})
+	})
+
+	Context("IsDisallowed", func() {
+		It("should disallow every path but the robots.txt", func() {
+			Expect(robots.IsDisallowed("/")).To(BeTrue())
+			Expect(robots.IsDisallowed("/news/2024/harbour.html")).To(BeTrue())
+			Expect(robots.IsDisallowed("/robots.txt")).To(BeFalse())
+		})
 	})
 })
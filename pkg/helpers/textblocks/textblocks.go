@@ -17,11 +17,36 @@ func RandomHeadline(ctx context.Context) string {
 	ctx, span := tracer.Start(ctx, "textblocks.RandomHeadline")
 	defer span.End()
 
+	return RandomLocalizedHeadline(ctx, "")
+}
+
+// RandomLocalizedHeadline returns a random headline in the given language.
+func RandomLocalizedHeadline(ctx context.Context, language string) string {
+	ctx, span := tracer.Start(ctx, "textblocks.RandomLocalizedHeadline")
+	defer span.End()
+
+	vocabulary := dictionaries.VocabularyFor(language)
+
 	return fmt.Sprintf("%s: %s %s %s",
-		functions.PickRandomStringFromSlice(ctx, &dictionaries.HeadlineStarters),
-		functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
-		functions.PickRandomStringFromSlice(ctx, &dictionaries.Verbs),
-		functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
+		functions.PickRandomStringFromSlice(ctx, &vocabulary.HeadlineStarters),
+		functions.PickRandomStringFromSlice(ctx, &vocabulary.Nouns),
+		functions.PickRandomStringFromSlice(ctx, &vocabulary.Verbs),
+		functions.PickRandomStringFromSlice(ctx, &vocabulary.Nouns),
+	)
+}
+
+// LocalizedHeadlineAbout returns a random headline in the given language about the subject and the object.
+func LocalizedHeadlineAbout(ctx context.Context, language, subject, object string) string {
+	ctx, span := tracer.Start(ctx, "textblocks.LocalizedHeadlineAbout")
+	defer span.End()
+
+	vocabulary := dictionaries.VocabularyFor(language)
+
+	return fmt.Sprintf("%s: %s %s %s",
+		functions.PickRandomStringFromSlice(ctx, &vocabulary.HeadlineStarters),
+		subject,
+		functions.PickRandomStringFromSlice(ctx, &vocabulary.Verbs),
+		object,
 	)
 }
 
@@ -55,9 +80,19 @@ func RandomTopic(ctx context.Context) string {
 	ctx, span := tracer.Start(ctx, "textblocks.RandomTopic")
 	defer span.End()
 
+	return RandomLocalizedTopic(ctx, "")
+}
+
+// RandomLocalizedTopic returns a random topic in the given language.
+func RandomLocalizedTopic(ctx context.Context, language string) string {
+	ctx, span := tracer.Start(ctx, "textblocks.RandomLocalizedTopic")
+	defer span.End()
+
+	vocabulary := dictionaries.VocabularyFor(language)
+
 	return fmt.Sprintf("%s %s %s",
-		functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
-		functions.PickRandomStringFromSlice(ctx, &dictionaries.Verbs),
-		functions.PickRandomStringFromSlice(ctx, &dictionaries.Nouns),
+		functions.PickRandomStringFromSlice(ctx, &vocabulary.Nouns),
+		functions.PickRandomStringFromSlice(ctx, &vocabulary.Verbs),
+		functions.PickRandomStringFromSlice(ctx, &vocabulary.Nouns),
 	)
 }
@@ -2,8 +2,10 @@ package textblocks_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
+	"codeberg.org/konterfai/konterfai/pkg/dictionaries"
 	"codeberg.org/konterfai/konterfai/pkg/helpers/textblocks"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -30,6 +32,21 @@ var _ = Describe("Textblocks", func() {
 		})
 	})
 
+	Context("RandomLocalizedHeadline", func() {
+		It("should use the vocabulary of the language", func() {
+			vocabulary := dictionaries.VocabularyFor("German")
+			starter, _, found := strings.Cut(textblocks.RandomLocalizedHeadline(ctx, "German"), ":")
+			Expect(found).To(BeTrue())
+			Expect(vocabulary.HeadlineStarters).To(ContainElement(starter))
+		})
+
+		It("should fall back to the default vocabulary for unknown languages", func() {
+			starter, _, found := strings.Cut(textblocks.RandomLocalizedHeadline(ctx, "Klingon"), ":")
+			Expect(found).To(BeTrue())
+			Expect(dictionaries.HeadlineStarters).To(ContainElement(starter))
+		})
+	})
+
 	Context("RandomKeywords", func() {
 		It("should return n random keywords", func() {
 			Expect(textblocks.RandomKeywords(ctx, 3)).NotTo(BeEmpty())
@@ -0,0 +1,17 @@
+package mutator
+
+// Capitals are the capital cities that are swapped with each other.
+var Capitals = []string{
+	"Amsterdam", "Athens", "Bangkok", "Beijing", "Berlin", "Bern", "Brussels", "Budapest", "Buenos Aires", "Cairo",
+	"Canberra", "Copenhagen", "Dublin", "Hanoi", "Helsinki", "Jakarta", "Kyiv", "Lima", "Lisbon", "London",
+	"Madrid", "Manila", "Mexico City", "Moscow", "Nairobi", "New Delhi", "Oslo", "Ottawa", "Paris", "Prague",
+	"Rome", "Santiago", "Seoul", "Stockholm", "Tokyo", "Vienna", "Warsaw", "Washington", "Wellington",
+}
+
+// Companies are the company names that are swapped with each other.
+var Companies = []string{
+	"Adidas", "Airbus", "Alibaba", "BASF", "Boeing", "Bosch", "Coca-Cola", "Ericsson", "Facebook", "Ferrari",
+	"General Electric", "Google", "Heineken", "Honda", "IBM", "IKEA", "Intel", "Microsoft", "Nestlé", "Netflix",
+	"Nike", "Nintendo", "Nokia", "Oracle", "PepsiCo", "Philips", "Samsung", "Siemens", "Sony", "Tesla", "Toyota",
+	"Unilever", "Volkswagen", "Walmart",
+}
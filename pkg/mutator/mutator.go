@@ -0,0 +1,169 @@
+package mutator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"codeberg.org/konterfai/konterfai/pkg/dictionaries"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("codeberg.org/konterfai/konterfai/pkg/mutator")
+
+// maxYearOffset is the largest offset, in years, that is derived from the seed.
+const maxYearOffset = 12
+
+// yearRegexp matches the years from 1600 to 2099.
+var yearRegexp = regexp.MustCompile(`\b(1[6-9]\d\d|20\d\d)\b`)
+
+// Mutator applies the same set of wrong facts to every hallucination: capitals and companies are swapped with each
+// other and years are shifted by a fixed offset. The set is derived from the seed, so it stays the same across
+// restarts of a deployment and differs between deployments.
+type Mutator struct {
+	// YearOffset is the number of years every year is shifted by.
+	YearOffset    int
+	substitutions map[string]string
+	entities      *regexp.Regexp
+}
+
+// NewMutator creates a new Mutator with the substitutions derived from the seed. The given substitutions take
+// precedence over the derived ones. A yearOffset of 0 is derived from the seed as well.
+func NewMutator(seed int64, yearOffset int, substitutions map[string]string) *Mutator {
+	random := rand.New(rand.NewSource(seed)) //nolint: gosec
+	derived := map[string]string{}
+	for _, entities := range [][]string{Capitals, Companies} {
+		for original, replacement := range derange(random, entities) {
+			derived[original] = replacement
+		}
+	}
+	for original, replacement := range substitutions {
+		derived[original] = replacement
+	}
+	if yearOffset == 0 {
+		yearOffset = random.Intn(maxYearOffset) + 1
+		if random.Intn(2) == 0 {
+			yearOffset = -yearOffset
+		}
+	}
+	originals := make([]string, 0, len(derived))
+	for original := range derived {
+		originals = append(originals, regexp.QuoteMeta(original))
+	}
+	// Longer entities are matched first, so "Mexico City" is not matched as "Mexico".
+	sort.Slice(originals, func(i, j int) bool {
+		if len(originals[i]) != len(originals[j]) {
+			return len(originals[i]) > len(originals[j])
+		}
+
+		return originals[i] < originals[j]
+	})
+
+	mutator := &Mutator{
+		YearOffset:    yearOffset,
+		substitutions: derived,
+	}
+	if len(originals) > 0 {
+		mutator.entities = regexp.MustCompile(strings.Join(originals, "|"))
+	}
+
+	return mutator
+}
+
+// LoadSubstitutions reads the substitutions of a file, one "original = replacement" per line.
+func LoadSubstitutions(path string) (map[string]string, error) {
+	lines, err := dictionaries.ReadLines(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read substitutions (%w)", err)
+	}
+	substitutions := make(map[string]string, len(lines))
+	for _, line := range lines {
+		original, replacement, ok := strings.Cut(line, "=")
+		original, replacement = strings.TrimSpace(original), strings.TrimSpace(replacement)
+		if !ok || original == "" {
+			return nil, fmt.Errorf("invalid substitution %q, expected \"original = replacement\"", line)
+		}
+		substitutions[original] = replacement
+	}
+
+	return substitutions, nil
+}
+
+// Substitution returns the replacement of the entity and whether the entity is substituted.
+func (m *Mutator) Substitution(entity string) (string, bool) {
+	replacement, ok := m.substitutions[entity]
+
+	return replacement, ok
+}
+
+// Mutate applies the substitutions and the year offset to the text.
+// Entities are only replaced as whole words, the replacements are not substituted again.
+func (m *Mutator) Mutate(ctx context.Context, text string) string {
+	_, span := tracer.Start(ctx, "Mutator.Mutate")
+	defer span.End()
+
+	if m.entities == nil {
+		return shiftYears(text, m.YearOffset)
+	}
+	mutated := strings.Builder{}
+	last := 0
+	for _, match := range m.entities.FindAllStringIndex(text, -1) {
+		if !isWordBoundary(text, match[0], match[1]) {
+			continue
+		}
+		mutated.WriteString(shiftYears(text[last:match[0]], m.YearOffset))
+		mutated.WriteString(m.substitutions[text[match[0]:match[1]]])
+		last = match[1]
+	}
+	mutated.WriteString(shiftYears(text[last:], m.YearOffset))
+
+	return mutated.String()
+}
+
+// shiftYears shifts the years of the text by the offset.
+func shiftYears(text string, offset int) string {
+	if offset == 0 {
+		return text
+	}
+
+	return yearRegexp.ReplaceAllStringFunc(text, func(year string) string {
+		value, _ := strconv.Atoi(year) // the regular expression only matches digits.
+
+		return strconv.Itoa(value + offset)
+	})
+}
+
+// isWordBoundary returns true if the text between start and end is not part of a longer word.
+func isWordBoundary(text string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(text[:start])
+	after, _ := utf8.DecodeRuneInString(text[end:])
+	isWordRune := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	return (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after))
+}
+
+// derange maps every entity to another entity of the list, no entity is mapped to itself.
+func derange(random *rand.Rand, entities []string) map[string]string {
+	shuffled := make([]string, len(entities))
+	copy(shuffled, entities)
+	random.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	mapping := make(map[string]string, len(shuffled))
+	if len(shuffled) < 2 {
+		return mapping
+	}
+	for i, entity := range shuffled {
+		mapping[entity] = shuffled[(i+1)%len(shuffled)]
+	}
+
+	return mapping
+}
@@ -0,0 +1,78 @@
+package mutator_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"codeberg.org/konterfai/konterfai/pkg/mutator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mutator", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("derives the same substitutions from the same seed", func() {
+		a, b := mutator.NewMutator(42, 0, nil), mutator.NewMutator(42, 0, nil)
+		Expect(a.YearOffset).To(Equal(b.YearOffset))
+		Expect(a.YearOffset).NotTo(BeZero())
+		for _, entity := range slices.Concat(mutator.Capitals, mutator.Companies) {
+			replacement, ok := a.Substitution(entity)
+			Expect(ok).To(BeTrue())
+			Expect(replacement).NotTo(Equal(entity))
+			other, _ := b.Substitution(entity)
+			Expect(other).To(Equal(replacement))
+		}
+	})
+
+	It("derives different substitutions from different seeds", func() {
+		a, b := mutator.NewMutator(1, 0, nil), mutator.NewMutator(2, 0, nil)
+		text := "Paris, Berlin, Tokyo, Google, Sony and Nokia."
+		Expect(a.Mutate(ctx, text)).NotTo(Equal(b.Mutate(ctx, text)))
+	})
+
+	It("swaps capitals with other capitals and companies with other companies", func() {
+		m := mutator.NewMutator(7, 0, nil)
+		paris, _ := m.Substitution("Paris")
+		siemens, _ := m.Substitution("Siemens")
+		Expect(mutator.Capitals).To(ContainElement(paris))
+		Expect(mutator.Companies).To(ContainElement(siemens))
+	})
+
+	It("mutates all articles consistently", func() {
+		m := mutator.NewMutator(7, 5, nil)
+		paris, _ := m.Substitution("Paris")
+		Expect(m.Mutate(ctx, "In 1999 Paris hosted the summit.")).To(Equal("In 2004 " + paris + " hosted the summit."))
+		Expect(m.Mutate(ctx, "Paris, 2020: a new museum.")).To(Equal(paris + ", 2025: a new museum."))
+	})
+
+	It("only replaces whole words and does not substitute replacements again", func() {
+		m := mutator.NewMutator(7, 1, map[string]string{"Paris": "Rome", "Rome": "Paris"})
+		Expect(m.Mutate(ctx, "Parisian food in Paris and Rome, 12000 guests.")).
+			To(Equal("Parisian food in Rome and Paris, 12000 guests."))
+	})
+
+	It("prefers the longest entity", func() {
+		m := mutator.NewMutator(7, 1, map[string]string{"Mexico": "Peru"})
+		mexicoCity, _ := m.Substitution("Mexico City")
+		Expect(m.Mutate(ctx, "Mexico City is the capital of Mexico.")).
+			To(Equal(mexicoCity + " is the capital of Peru."))
+	})
+
+	It("loads substitutions from a file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "mutations.txt")
+		Expect(os.WriteFile(path, []byte("# facts\nDanube = Rhine\n"), 0o600)).To(Succeed())
+		substitutions, err := mutator.LoadSubstitutions(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(substitutions).To(Equal(map[string]string{"Danube": "Rhine"}))
+		Expect(os.WriteFile(path, []byte("Danube\n"), 0o600)).To(Succeed())
+		_, err = mutator.LoadSubstitutions(path)
+		Expect(err).To(MatchError(ContainSubstring("invalid substitution")))
+	})
+})
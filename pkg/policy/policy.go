@@ -0,0 +1,351 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"codeberg.org/konterfai/konterfai/pkg/classifier"
+	"codeberg.org/konterfai/konterfai/pkg/helpers/functions"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/yaml.v3"
+)
+
+var tracer = otel.Tracer("codeberg.org/konterfai/konterfai/pkg/policy")
+
+// The actions of a rule.
+const (
+	// ActionPoison serves the request like any other, with a random status code or a hallucination, but never
+	// drip-fed by the tarpit.
+	ActionPoison = "poison"
+	// ActionTarpit serves a hallucination that is drip-fed by the tarpit.
+	ActionTarpit = "tarpit"
+	// ActionBlock answers with the status of the rule, 403 Forbidden or 410 Gone.
+	ActionBlock = "block"
+	// ActionRedirect redirects to the location of the rule.
+	ActionRedirect = "redirect"
+	// ActionPass lets the request through to the upstream application.
+	ActionPass = "pass"
+)
+
+// DefaultReloadInterval is the interval in which the rules file is checked for changes.
+const DefaultReloadInterval = 5 * time.Second
+
+// Match are the conditions of a rule, a request matches if it meets all conditions that are set.
+type Match struct {
+	// UserAgent is a regular expression that is matched case-insensitively anywhere in the user agent.
+	UserAgent string `json:"userAgent,omitempty" yaml:"userAgent,omitempty"`
+	// Networks are networks in CIDR notation or single IP addresses, one of them must contain the client.
+	Networks []string `json:"networks,omitempty" yaml:"networks,omitempty"`
+	// Paths are glob patterns of path.Match, one of them must match the path.
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+	// MinScore is the lowest score of the classifier that matches.
+	MinScore *float64 `json:"minScore,omitempty" yaml:"minScore,omitempty"`
+	// MaxScore is the highest score of the classifier that matches.
+	MaxScore *float64 `json:"maxScore,omitempty" yaml:"maxScore,omitempty"`
+	// MinRequests is the lowest number of requests of the client in the statistics that matches.
+	MinRequests int `json:"minRequests,omitempty" yaml:"minRequests,omitempty"`
+}
+
+// Rule maps the requests that match to an action.
+type Rule struct {
+	Name   string `json:"name"   yaml:"name"`
+	Match  Match  `json:"match"  yaml:"match"`
+	Action string `json:"action" yaml:"action"`
+	// Status is the status code of the block and redirect actions.
+	Status int `json:"status,omitempty" yaml:"status,omitempty"`
+	// Location is the target of the redirect action.
+	Location string `json:"location,omitempty" yaml:"location,omitempty"`
+
+	userAgent *regexp.Regexp
+	networks  []netip.Prefix
+}
+
+// rulesFile is the structure of a rules file.
+type rulesFile struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Request is a request to evaluate the rules for.
+type Request struct {
+	classifier.Request
+	// Score is the score of the request by the classifier.
+	Score float64
+}
+
+// Policy evaluates the rules of a rules file in order, the first rule that matches decides the action.
+// The rules are reloaded by Watch whenever the file changes.
+type Policy struct {
+	Path       string
+	Statistics *statistics.Statistics
+	Logger     *slog.Logger
+	rules      []Rule
+	modTime    time.Time
+	lock       sync.RWMutex
+}
+
+// NewPolicy creates a new Policy with the rules of the file.
+func NewPolicy(ctx context.Context, logger *slog.Logger, rulesPath string, st *statistics.Statistics) (*Policy, error) {
+	_, span := tracer.Start(ctx, "NewPolicy")
+	defer span.End()
+
+	info, err := os.Stat(rulesPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read policy rules (%w)", err)
+	}
+	rules, err := LoadRules(rulesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Policy{Path: rulesPath, Statistics: st, Logger: logger, rules: rules, modTime: info.ModTime()}, nil
+}
+
+// LoadRules reads and validates the rules of a YAML (.yaml, .yml) or JSON (.json) file.
+func LoadRules(rulesPath string) ([]Rule, error) {
+	content, err := os.ReadFile(rulesPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read policy rules (%w)", err)
+	}
+	file := rulesFile{}
+	switch strings.ToLower(filepath.Ext(rulesPath)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&file)
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&file)
+	default:
+		return nil, fmt.Errorf("unknown policy rules format %q", filepath.Ext(rulesPath))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse policy rules (%w)", err)
+	}
+	names := map[string]struct{}{}
+	for i := range file.Rules {
+		if err := file.Rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("invalid policy rule %d %q (%w)", i+1, file.Rules[i].Name, err)
+		}
+		if _, ok := names[file.Rules[i].Name]; ok {
+			return nil, fmt.Errorf("invalid policy rule %d %q (%w)", i+1, file.Rules[i].Name,
+				errors.New("the name is not unique"))
+		}
+		names[file.Rules[i].Name] = struct{}{}
+	}
+
+	return file.Rules, nil
+}
+
+// compile validates the rule, fills in the default status and compiles its conditions.
+func (r *Rule) compile() error { //nolint: cyclop
+	if r.Name == "" {
+		return errors.New("the name is missing")
+	}
+	switch r.Action {
+	case ActionPoison, ActionTarpit, ActionPass:
+	case ActionBlock:
+		if r.Status == 0 {
+			r.Status = http.StatusForbidden
+		}
+		if r.Status != http.StatusForbidden && r.Status != http.StatusGone {
+			return fmt.Errorf("invalid block status %d, only 403 and 410 are allowed", r.Status)
+		}
+	case ActionRedirect:
+		if r.Location == "" {
+			return errors.New("the location of the redirect is missing")
+		}
+		if r.Status == 0 {
+			r.Status = http.StatusFound
+		}
+		if !slices.Contains([]int{http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+			http.StatusTemporaryRedirect, http.StatusPermanentRedirect}, r.Status) {
+			return fmt.Errorf("invalid redirect status %d", r.Status)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+	if r.Match.UserAgent != "" {
+		userAgent, err := regexp.Compile("(?i)" + r.Match.UserAgent)
+		if err != nil {
+			return fmt.Errorf("invalid user agent pattern (%w)", err)
+		}
+		r.userAgent = userAgent
+	}
+	for _, network := range r.Match.Networks {
+		prefix, err := classifier.ParseNetwork(network)
+		if err != nil {
+			return err
+		}
+		r.networks = append(r.networks, prefix)
+	}
+	for _, pattern := range r.Match.Paths {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid path %q (%w)", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// Rules returns the rules that are in effect.
+func (p *Policy) Rules() []Rule {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.rules
+}
+
+// HasAction returns true if one of the rules that are in effect has the action. A nil Policy has no rules.
+func (p *Policy) HasAction(action string) bool {
+	if p == nil {
+		return false
+	}
+
+	return slices.ContainsFunc(p.Rules(), func(rule Rule) bool {
+		return rule.Action == action
+	})
+}
+
+// Evaluate returns the first rule that matches the request, or nil if no rule matches.
+// A nil Policy matches no request.
+func (p *Policy) Evaluate(ctx context.Context, r Request) *Rule {
+	ctx, span := tracer.Start(ctx, "Policy.Evaluate")
+	defer span.End()
+
+	if p == nil {
+		return nil
+	}
+	rules := p.Rules()
+	requestCount := -1
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Match.MinRequests > 0 && requestCount < 0 {
+			requestCount = 0
+			if p.Statistics != nil {
+				requestCount = p.Statistics.GetTotalRequestsByIPAddress(ctx, r.Client)
+			}
+		}
+		if !rule.matches(r, requestCount) {
+			continue
+		}
+		span.SetAttributes(attribute.String("konterfai.rule", rule.Name))
+		statistics.PolicyMatchesTotal.WithLabelValues(rule.Name, rule.Action).Inc()
+
+		return rule
+	}
+
+	return nil
+}
+
+// matches returns true if the request meets all conditions of the rule.
+func (r *Rule) matches(request Request, requestCount int) bool {
+	if r.userAgent != nil && !r.userAgent.MatchString(request.Header.Get("User-Agent")) {
+		return false
+	}
+	if len(r.networks) > 0 {
+		addr, err := netip.ParseAddr(request.Client)
+		if err != nil || !slices.ContainsFunc(r.networks, func(network netip.Prefix) bool {
+			return network.Contains(addr.Unmap())
+		}) {
+			return false
+		}
+	}
+	if len(r.Match.Paths) > 0 && !slices.ContainsFunc(r.Match.Paths, func(pattern string) bool {
+		matched, _ := path.Match(pattern, request.Path)
+
+		return matched
+	}) {
+		return false
+	}
+	if r.Match.MinScore != nil && request.Score < *r.Match.MinScore {
+		return false
+	}
+	if r.Match.MaxScore != nil && request.Score > *r.Match.MaxScore {
+		return false
+	}
+
+	return r.Match.MinRequests <= 0 || requestCount >= r.Match.MinRequests
+}
+
+// Watch reloads the rules every interval if the file has changed, until the context is done.
+// Invalid rules are logged and the previous rules stay in effect.
+func (p *Policy) Watch(ctx context.Context, interval time.Duration) {
+	// No need to trace this function as it is an endless loop, every reload is traced.
+	for ctx.Err() == nil {
+		functions.SleepWithContext(ctx, p.Logger, max(interval, time.Second))
+		p.reload(ctx)
+	}
+}
+
+// reload reloads the rules if the file has changed since the last load.
+func (p *Policy) reload(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "Policy.reload")
+	defer span.End()
+
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		p.Logger.ErrorContext(ctx, fmt.Sprintf("could not read policy rules (%v)", err))
+
+		return
+	}
+	p.lock.RLock()
+	unchanged := info.ModTime().Equal(p.modTime)
+	p.lock.RUnlock()
+	if unchanged {
+		return
+	}
+	rules, err := LoadRules(p.Path)
+	p.lock.Lock()
+	p.modTime = info.ModTime()
+	if err == nil {
+		p.rules = rules
+	}
+	p.lock.Unlock()
+	if err != nil {
+		p.Logger.ErrorContext(ctx, fmt.Sprintf("could not reload policy rules, keeping the previous ones (%v)", err))
+
+		return
+	}
+	p.Logger.InfoContext(ctx, fmt.Sprintf("reloaded %d policy rules from %s", len(rules), p.Path))
+}
+
+// contextKey is the key of the matched Rule in a context.
+type contextKey struct{}
+
+// NewContext returns a copy of the context that carries the matched rule, which may be nil.
+func NewContext(ctx context.Context, rule *Rule) context.Context {
+	return context.WithValue(ctx, contextKey{}, rule)
+}
+
+// FromContext returns the matched rule of the context, or nil if no rule matched.
+func FromContext(ctx context.Context) *Rule {
+	rule, _ := ctx.Value(contextKey{}).(*Rule)
+
+	return rule
+}
+
+// RuleName returns the name of the matched rule of the context, or an empty string if no rule matched.
+func RuleName(ctx context.Context) string {
+	if rule := FromContext(ctx); rule != nil {
+		return rule.Name
+	}
+
+	return ""
+}
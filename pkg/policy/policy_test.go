@@ -0,0 +1,175 @@
+package policy_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"codeberg.org/konterfai/konterfai/pkg/classifier"
+	"codeberg.org/konterfai/konterfai/pkg/command"
+	"codeberg.org/konterfai/konterfai/pkg/policy"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Policy", func() {
+	const rules = `rules:
+  - name: block-bytespider
+    match:
+      userAgent: bytespider
+    action: block
+    status: 410
+  - name: tarpit-datacenter
+    match:
+      networks: ["20.15.240.64/28", "2001:db8::1"]
+    action: tarpit
+  - name: redirect-admin
+    match:
+      paths: ["/wp-admin/*"]
+    action: redirect
+    location: https://example.com/
+  - name: poison-frequent-suspects
+    match:
+      minScore: 0.5
+      minRequests: 2
+    action: poison
+  - name: pass-humans
+    match:
+      maxScore: 0.25
+    action: pass
+`
+
+	var (
+		ctx       context.Context
+		rulesPath string
+		st        *statistics.Statistics
+		p         *policy.Policy
+	)
+
+	request := func(client, path, userAgent string, score float64) policy.Request {
+		return policy.Request{
+			Request: classifier.Request{Client: client, Path: path, Header: http.Header{"User-Agent": {userAgent}}},
+			Score:   score,
+		}
+	}
+
+	ruleName := func(rule *policy.Rule) string {
+		if rule == nil {
+			return ""
+		}
+
+		return rule.Name
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		logger, _ := command.SetLogger("off", "")
+		rulesPath = filepath.Join(GinkgoT().TempDir(), "rules.yaml")
+		Expect(os.WriteFile(rulesPath, []byte(rules), 0o600)).To(Succeed())
+		st = &statistics.Statistics{}
+		var err error
+		p, err = policy.NewPolicy(ctx, logger, rulesPath, st)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("loads the rules with their defaults", func() {
+		Expect(p.Rules()).To(HaveLen(5))
+		Expect(p.Rules()[0].Status).To(Equal(http.StatusGone))
+		Expect(p.Rules()[2].Status).To(Equal(http.StatusFound))
+	})
+
+	It("evaluates the rules in order", func() {
+		Expect(ruleName(p.Evaluate(ctx, request("20.15.240.70", "/", "Bytespider", 1)))).
+			To(Equal("block-bytespider"))
+		Expect(ruleName(p.Evaluate(ctx, request("20.15.240.70", "/", "Mozilla/5.0", 1)))).
+			To(Equal("tarpit-datacenter"))
+		Expect(ruleName(p.Evaluate(ctx, request("2001:db8::1", "/", "Mozilla/5.0", 0)))).
+			To(Equal("tarpit-datacenter"))
+		Expect(ruleName(p.Evaluate(ctx, request("198.51.100.1", "/wp-admin/setup.php", "Mozilla/5.0", 0)))).
+			To(Equal("redirect-admin"))
+		Expect(ruleName(p.Evaluate(ctx, request("198.51.100.1", "/", "Mozilla/5.0", 0)))).
+			To(Equal("pass-humans"))
+		Expect(p.Evaluate(ctx, request("198.51.100.1", "/", "Mozilla/5.0", 0.5))).To(BeNil())
+	})
+
+	It("matches the request count of the client", func() {
+		st.AppendRequest(ctx, statistics.Request{IPAddress: "198.51.100.1"})
+		Expect(p.Evaluate(ctx, request("198.51.100.1", "/", "Mozilla/5.0", 0.5))).To(BeNil())
+		st.AppendRequest(ctx, statistics.Request{IPAddress: "198.51.100.1"})
+		Expect(ruleName(p.Evaluate(ctx, request("198.51.100.1", "/", "Mozilla/5.0", 0.5)))).
+			To(Equal("poison-frequent-suspects"))
+	})
+
+	It("matches the request count of IPv6 clients", func() {
+		st.AppendRequest(ctx, statistics.Request{IPAddress: "[2001:db8::2]:4711"})
+		st.AppendRequest(ctx, statistics.Request{IPAddress: "[2001:db8::3]:4711"})
+		Expect(p.Evaluate(ctx, request("2001:db8::2", "/", "Mozilla/5.0", 0.5))).To(BeNil())
+		st.AppendRequest(ctx, statistics.Request{IPAddress: "[2001:db8::2]:4712"})
+		Expect(ruleName(p.Evaluate(ctx, request("2001:db8::2", "/", "Mozilla/5.0", 0.5)))).
+			To(Equal("poison-frequent-suspects"))
+	})
+
+	It("matches no request without a policy", func() {
+		var none *policy.Policy
+		Expect(none.Evaluate(ctx, request("198.51.100.1", "/", "Bytespider", 1))).To(BeNil())
+	})
+
+	It("knows the actions of its rules", func() {
+		Expect(p.HasAction(policy.ActionTarpit)).To(BeTrue())
+		Expect(p.HasAction("unknown")).To(BeFalse())
+		var none *policy.Policy
+		Expect(none.HasAction(policy.ActionTarpit)).To(BeFalse())
+	})
+
+	It("reloads the rules when the file changes and keeps them if the file is invalid", func() {
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go p.Watch(watchCtx, time.Second)
+		changed := time.Now().Add(time.Minute)
+		Expect(os.WriteFile(rulesPath, []byte("rules:\n  - name: pass-all\n    action: pass\n"), 0o600)).
+			To(Succeed())
+		Expect(os.Chtimes(rulesPath, changed, changed)).To(Succeed())
+		Eventually(func() int { return len(p.Rules()) }, 3*time.Second).Should(Equal(1))
+		Expect(os.WriteFile(rulesPath, []byte("rules:\n  - name: broken\n    action: explode\n"), 0o600)).
+			To(Succeed())
+		Expect(os.Chtimes(rulesPath, changed.Add(time.Minute), changed.Add(time.Minute))).To(Succeed())
+		Consistently(func() string { return p.Rules()[0].Name }, 2*time.Second).Should(Equal("pass-all"))
+	})
+
+	DescribeTable("rejects invalid rules",
+		func(content, message string) {
+			Expect(os.WriteFile(rulesPath, []byte(content), 0o600)).To(Succeed())
+			_, err := policy.LoadRules(rulesPath)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("without name", "rules:\n  - action: pass\n", "the name is missing"),
+		Entry("with a duplicate name", "rules:\n  - name: a\n    action: pass\n  - name: a\n    action: pass\n",
+			"the name is not unique"),
+		Entry("with an unknown action", "rules:\n  - name: a\n    action: explode\n", "unknown action"),
+		Entry("with an unknown field", "rules:\n  - name: a\n    action: pass\n    speed: 1\n", "field speed not found"),
+		Entry("with an invalid block status", "rules:\n  - name: a\n    action: block\n    status: 404\n",
+			"invalid block status"),
+		Entry("without redirect location", "rules:\n  - name: a\n    action: redirect\n", "location"),
+		Entry("with an invalid user agent", "rules:\n  - name: a\n    action: pass\n    match:\n      userAgent: \"(\"\n",
+			"invalid user agent pattern"),
+		Entry("with an invalid network", "rules:\n  - name: a\n    action: pass\n    match:\n      networks: [\"x\"]\n",
+			"invalid network"),
+	)
+
+	It("loads JSON rules", func() {
+		jsonPath := filepath.Join(GinkgoT().TempDir(), "rules.json")
+		Expect(os.WriteFile(jsonPath, []byte(`{"rules": [{"name": "a", "action": "block"}]}`), 0o600)).To(Succeed())
+		loaded, err := policy.LoadRules(jsonPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded[0].Status).To(Equal(http.StatusForbidden))
+	})
+
+	It("carries the matched rule in the context", func() {
+		rule := &p.Rules()[0]
+		Expect(policy.RuleName(policy.NewContext(ctx, rule))).To(Equal("block-bytespider"))
+		Expect(policy.RuleName(ctx)).To(BeEmpty())
+	})
+})
@@ -23,6 +23,9 @@ var assets embed.FS
 
 var tracer = otel.Tracer("codeberg.org/konterfai/konterfai/pkg/renderer")
 
+// contentMarker is rendered as content to find the position of the content in the rendered template.
+const contentMarker template.HTML = "<!-- konterfai:content -->"
+
 // Renderer is the structure for the Renderer.
 type Renderer struct {
 	htmlTemplates     []string
@@ -115,6 +118,25 @@ func (r *Renderer) RenderInRandomTemplate(ctx context.Context, rd RenderData) (s
 	return buffer.String(), nil
 }
 
+// RenderInRandomTemplateAroundContent renders a random template without content and returns the parts before
+// and after the content. This allows writing the content between both parts while it is still being generated.
+func (r *Renderer) RenderInRandomTemplateAroundContent(ctx context.Context, rd RenderData) (string, string, error) {
+	ctx, span := tracer.Start(ctx, "Renderer.RenderInRandomTemplateAroundContent")
+	defer span.End()
+
+	rd.Content = contentMarker
+	rendered, err := r.RenderInRandomTemplate(ctx, rd)
+	if err != nil {
+		return "", "", err
+	}
+	head, tail, found := strings.Cut(rendered, string(contentMarker))
+	if !found {
+		return "", "", errors.New("template does not contain the content")
+	}
+
+	return head, tail, nil
+}
+
 // SetTemplates sets the templates, at the moment only used for testing.
 func (r *Renderer) SetTemplates(templates []string) {
 	_, span := tracer.Start(context.Background(), "Renderer.SetTemplates")
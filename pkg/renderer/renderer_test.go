@@ -64,6 +64,19 @@ var _ = Describe("Renderer", func() {
 			Expect(renderedTemplate).NotTo(BeNil())
 		})
 
+		It("should render the language code", func() {
+			renderedTemplate, err := r.RenderInRandomTemplate(ctx, rd)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(renderedTemplate).To(ContainSubstring(`<html lang="languageCode">`))
+		})
+
+		It("should omit the lang attribute without language code", func() {
+			rd.LanguageCode = ""
+			renderedTemplate, err := r.RenderInRandomTemplate(ctx, rd)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(renderedTemplate).To(ContainSubstring("<html>"))
+		})
+
 		It("should not render if headlineLinks is empty", func() {
 			rd.HeadlineLinks = []string{}
 			renderedTemplate, err := r.RenderInRandomTemplate(ctx, rd)
@@ -92,4 +105,22 @@ var _ = Describe("Renderer", func() {
 			Expect(renderedTemplate).To(Equal(""))
 		})
 	})
+
+	Context("RenderInRandomTemplateAroundContent", func() {
+		It("should render every template around the content", func() {
+			for range 50 {
+				head, tail, err := r.RenderInRandomTemplateAroundContent(ctx, rd)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(head).To(ContainSubstring("headline"))
+				Expect(tail).To(ContainSubstring("followUpLink"))
+				Expect(head + tail).NotTo(ContainSubstring("konterfai:content"))
+			}
+		})
+
+		It("should throw an error if the template does not contain the content", func() {
+			r.SetTemplates([]string{"{{.Headline}}"})
+			_, _, err := r.RenderInRandomTemplateAroundContent(ctx, rd)
+			Expect(err).To(MatchError("template does not contain the content"))
+		})
+	})
 })
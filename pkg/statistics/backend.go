@@ -0,0 +1,86 @@
+package statistics
+
+import (
+	"context"
+	"time"
+)
+
+// SetBackendState sets the reachability and the model presence of the generation backend.
+func (s *Statistics) SetBackendState(ctx context.Context, name string, reachable, modelPresent bool) {
+	_, span := tracer.Start(ctx, "Statistics.SetBackendState")
+	defer span.End()
+
+	s.BackendLock.Lock()
+	defer s.BackendLock.Unlock()
+	s.BackendStatus.Name = name
+	s.BackendStatus.Reachable = reachable
+	s.BackendStatus.ModelPresent = modelPresent
+	s.BackendStatus.UpdatedAt = time.Now()
+
+	// Update Prometheus metrics
+	BackendUp.WithLabelValues(name).Set(boolToFloat64(reachable))
+	BackendModelPresent.WithLabelValues(name).Set(boolToFloat64(modelPresent))
+}
+
+// SetBackendCircuitState sets the state of the circuit breaker of the generation backend.
+func (s *Statistics) SetBackendCircuitState(ctx context.Context, name, state string) {
+	_, span := tracer.Start(ctx, "Statistics.SetBackendCircuitState")
+	defer span.End()
+
+	s.BackendLock.Lock()
+	defer s.BackendLock.Unlock()
+	s.BackendStatus.Name = name
+	s.BackendStatus.CircuitState = state
+}
+
+// SetBackendSuccess marks the generation backend as healthy after a successful generation.
+func (s *Statistics) SetBackendSuccess(ctx context.Context, name string) {
+	ctx, span := tracer.Start(ctx, "Statistics.SetBackendSuccess")
+	defer span.End()
+
+	s.SetBackendState(ctx, name, true, true)
+	s.BackendLock.Lock()
+	defer s.BackendLock.Unlock()
+	s.BackendStatus.LastError = ""
+}
+
+// SetBackendError records the last error of the generation backend and marks it as unreachable.
+// It returns true if the error differs from the previous one, so callers can avoid repeating the same log message.
+func (s *Statistics) SetBackendError(ctx context.Context, name string, err error) bool {
+	_, span := tracer.Start(ctx, "Statistics.SetBackendError")
+	defer span.End()
+
+	s.BackendLock.Lock()
+	defer s.BackendLock.Unlock()
+	changed := s.BackendStatus.LastError != err.Error()
+	s.BackendStatus.Name = name
+	s.BackendStatus.Reachable = false
+	s.BackendStatus.LastError = err.Error()
+	s.BackendStatus.LastErrorAt = time.Now()
+	s.BackendStatus.UpdatedAt = s.BackendStatus.LastErrorAt
+
+	// Update Prometheus metrics
+	BackendUp.WithLabelValues(name).Set(0)
+
+	return changed
+}
+
+// GetBackendStatus returns the state of the generation backend.
+func (s *Statistics) GetBackendStatus(ctx context.Context) BackendStatus {
+	_, span := tracer.Start(ctx, "Statistics.GetBackendStatus")
+	defer span.End()
+
+	s.BackendLock.Lock()
+	defer s.BackendLock.Unlock()
+
+	return s.BackendStatus
+}
+
+// boolToFloat64 converts a bool to a float64 for gauges.
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,54 @@
+package statistics_test
+
+import (
+	"context"
+	"errors"
+
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var _ = Describe("Backend", func() {
+	var ctx context.Context
+	var s *statistics.Statistics
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s = &statistics.Statistics{}
+	})
+
+	It("should record the backend state", func() {
+		s.SetBackendState(ctx, "ollama", true, false)
+		status := s.GetBackendStatus(ctx)
+		Expect(status.Name).To(Equal("ollama"))
+		Expect(status.Reachable).To(BeTrue())
+		Expect(status.ModelPresent).To(BeFalse())
+	})
+
+	It("should report only changed errors", func() {
+		Expect(s.SetBackendError(ctx, "ollama", errors.New("ollama did not return 200 OK"))).To(BeTrue())
+		Expect(s.SetBackendError(ctx, "ollama", errors.New("ollama did not return 200 OK"))).To(BeFalse())
+		Expect(s.GetBackendStatus(ctx).LastError).To(Equal("ollama did not return 200 OK"))
+		Expect(s.SetBackendError(ctx, "ollama", errors.New("connection refused"))).To(BeTrue())
+	})
+
+	It("should mark the backend as down after an error", func() {
+		s.SetBackendState(ctx, "ollama", true, true)
+		s.SetBackendError(ctx, "ollama", errors.New("connection refused"))
+		Expect(s.GetBackendStatus(ctx).Reachable).To(BeFalse())
+		up := &dto.Metric{}
+		Expect(statistics.BackendUp.WithLabelValues("ollama").Write(up)).To(Succeed())
+		Expect(up.GetGauge().GetValue()).To(BeZero())
+	})
+
+	It("should clear the last error after a success", func() {
+		s.SetBackendError(ctx, "ollama", errors.New("connection refused"))
+		s.SetBackendSuccess(ctx, "ollama")
+		status := s.GetBackendStatus(ctx)
+		Expect(status.LastError).To(BeEmpty())
+		Expect(status.Reachable).To(BeTrue())
+		Expect(status.ModelPresent).To(BeTrue())
+	})
+})
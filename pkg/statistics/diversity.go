@@ -0,0 +1,53 @@
+package statistics
+
+import (
+	"context"
+	"time"
+)
+
+// Diversity is the structure for the diversity of the hallucinations cache.
+type Diversity struct {
+	Score      float64
+	Duplicates int
+	UpdatedAt  time.Time
+}
+
+// ScorePercent returns the diversity score in percent.
+func (d Diversity) ScorePercent() float64 {
+	return d.Score * 100
+}
+
+// SetDiversity sets the diversity score of the hallucinations cache, between 0 (identical) and 1 (distinct).
+func (s *Statistics) SetDiversity(ctx context.Context, score float64) {
+	_, span := tracer.Start(ctx, "Statistics.SetDiversity")
+	defer span.End()
+
+	s.DiversityLock.Lock()
+	defer s.DiversityLock.Unlock()
+	s.Diversity.Score = score
+	s.Diversity.UpdatedAt = time.Now()
+
+	// Update Prometheus metrics
+	HallucinationDiversity.Set(score)
+}
+
+// RecordDuplicate counts a hallucination that has been rejected as near-duplicate.
+func (s *Statistics) RecordDuplicate(ctx context.Context) {
+	_, span := tracer.Start(ctx, "Statistics.RecordDuplicate")
+	defer span.End()
+
+	s.DiversityLock.Lock()
+	defer s.DiversityLock.Unlock()
+	s.Diversity.Duplicates++
+}
+
+// GetDiversity returns the diversity of the hallucinations cache.
+func (s *Statistics) GetDiversity(ctx context.Context) Diversity {
+	_, span := tracer.Start(ctx, "Statistics.GetDiversity")
+	defer span.End()
+
+	s.DiversityLock.Lock()
+	defer s.DiversityLock.Unlock()
+
+	return s.Diversity
+}
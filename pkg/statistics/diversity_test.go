@@ -0,0 +1,33 @@
+package statistics_test
+
+import (
+	"context"
+
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diversity", func() {
+	var ctx context.Context
+	var s *statistics.Statistics
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s = &statistics.Statistics{}
+	})
+
+	It("should not be set before the first hallucination", func() {
+		Expect(s.GetDiversity(ctx).UpdatedAt.IsZero()).To(BeTrue())
+	})
+
+	It("should record the score and the rejected duplicates", func() {
+		s.SetDiversity(ctx, 0.625)
+		s.RecordDuplicate(ctx)
+		s.RecordDuplicate(ctx)
+		diversity := s.GetDiversity(ctx)
+		Expect(diversity.ScorePercent()).To(Equal(62.5))
+		Expect(diversity.Duplicates).To(Equal(2))
+		Expect(diversity.UpdatedAt.IsZero()).To(BeFalse())
+	})
+})
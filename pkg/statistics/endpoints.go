@@ -0,0 +1,18 @@
+package statistics
+
+import "time"
+
+// SetEndpointHealth records the health of an endpoint of the generation backend.
+func SetEndpointHealth(backend, address string, healthy bool) {
+	EndpointUp.WithLabelValues(backend, address).Set(boolToFloat64(healthy))
+}
+
+// SetEndpointOutstandingRequests records the number of requests in flight to an endpoint of the generation backend.
+func SetEndpointOutstandingRequests(backend, address string, outstanding int) {
+	EndpointOutstandingRequests.WithLabelValues(backend, address).Set(float64(outstanding))
+}
+
+// RecordEndpointGeneration records the latency of a successful generation of an endpoint of the generation backend.
+func RecordEndpointGeneration(backend, address string, latency time.Duration) {
+	EndpointGenerationDuration.WithLabelValues(backend, address).Observe(latency.Seconds())
+}
@@ -2,7 +2,8 @@ package statistics
 
 import (
 	"context"
-	"strings"
+	"net"
+	"net/netip"
 	"time"
 )
 
@@ -13,14 +14,39 @@ func (s *Statistics) AppendRequest(ctx context.Context, r Request) {
 
 	s.StatisticsLock.Lock()
 	defer s.StatisticsLock.Unlock()
-	r.IPAddress = strings.Split(r.IPAddress, ":")[0]
+	r.IPAddress = clientAddress(r.IPAddress)
 	s.Requests = append(s.Requests, r)
+	if s.requestCounts == nil {
+		s.requestCounts = map[string]int{}
+	}
+	s.requestCounts[r.IPAddress]++
+	if r.IsRobotsTxt {
+		if s.robotsTxtClients == nil {
+			s.robotsTxtClients = map[robotsTxtClient]struct{}{}
+		}
+		s.robotsTxtClients[robotsTxtClient{ipAddress: r.IPAddress, userAgent: r.UserAgent}] = struct{}{}
+	}
 
 	// Update Prometheus metrics
 	RequestTotal.Inc()
 	DataFedTotal.Add(float64(r.Size))
 }
 
+// clientAddress returns the IP address of a remote address without port, IPv4-mapped IPv6 addresses as IPv4 address.
+// Addresses that can not be parsed are returned without port.
+func clientAddress(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+
+	return addr.Unmap().String()
+}
+
 // GetAgents returns the agents.
 func (s *Statistics) GetAgents(ctx context.Context) []string {
 	_, span := tracer.Start(ctx, "Statistics.GetAgents")
@@ -87,6 +113,18 @@ func (s *Statistics) GetRequestsByIPAddress(ctx context.Context, ipAddress strin
 	return requests
 }
 
+// HasRequestedRobotsTxt returns true if the IP address has requested the robots.txt with the user agent.
+func (s *Statistics) HasRequestedRobotsTxt(ctx context.Context, ipAddress, userAgent string) bool {
+	_, span := tracer.Start(ctx, "Statistics.HasRequestedRobotsTxt")
+	defer span.End()
+
+	s.StatisticsLock.Lock()
+	defer s.StatisticsLock.Unlock()
+	_, ok := s.robotsTxtClients[robotsTxtClient{ipAddress: clientAddress(ipAddress), userAgent: userAgent}]
+
+	return ok
+}
+
 // GetRequestsByTimeRange returns the requests by time range.
 func (s *Statistics) GetRequestsByTimeRange(ctx context.Context, start, end time.Time) []Request {
 	_, span := tracer.Start(ctx, "Statistics.GetRequestsByTimeRange")
@@ -221,20 +259,15 @@ func (s *Statistics) GetTotalRequestsByAgent(ctx context.Context, agent string)
 }
 
 // GetTotalRequestsByIPAddress returns the total requests by IP address.
+// The requests are counted by AppendRequest, the policy looks them up for every request.
 func (s *Statistics) GetTotalRequestsByIPAddress(ctx context.Context, ipAddress string) int {
 	_, span := tracer.Start(ctx, "Statistics.GetTotalRequestsByIPAddress")
 	defer span.End()
 
 	s.StatisticsLock.Lock()
 	defer s.StatisticsLock.Unlock()
-	var count int
-	for _, r := range s.Requests {
-		if r.IPAddress == ipAddress {
-			count++
-		}
-	}
 
-	return count
+	return s.requestCounts[clientAddress(ipAddress)]
 }
 
 // GetTotalDataSizeServedByTimeRange returns the data size served by time range.
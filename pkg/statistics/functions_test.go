@@ -127,6 +127,35 @@ var _ = Describe("Functions", func() {
 		})
 	})
 
+	Context("HasRequestedRobotsTxt", func() {
+		It("should only be true after a robots.txt request", func() {
+			s.AppendRequest(ctx, r)
+			Expect(s.HasRequestedRobotsTxt(ctx, r.IPAddress, r.UserAgent)).To(BeFalse())
+			r.IsRobotsTxt = true
+			s.AppendRequest(ctx, r)
+			Expect(s.HasRequestedRobotsTxt(ctx, r.IPAddress, r.UserAgent)).To(BeTrue())
+			Expect(s.HasRequestedRobotsTxt(ctx, "10.0.0.1", r.UserAgent)).To(BeFalse())
+			Expect(s.HasRequestedRobotsTxt(ctx, r.IPAddress, "curl/8.5.0")).To(BeFalse())
+		})
+
+		It("should identify IPv6 clients by their address", func() {
+			r.IPAddress = "[2001:db8::1]:4711"
+			r.IsRobotsTxt = true
+			s.AppendRequest(ctx, r)
+			Expect(s.HasRequestedRobotsTxt(ctx, "2001:db8::1", r.UserAgent)).To(BeTrue())
+			Expect(s.HasRequestedRobotsTxt(ctx, "[2001:db8::1]:4712", r.UserAgent)).To(BeTrue())
+			Expect(s.HasRequestedRobotsTxt(ctx, "2001:db8::2", r.UserAgent)).To(BeFalse())
+			Expect(s.GetIPAddresses(ctx)).To(Equal([]string{"2001:db8::1"}))
+		})
+
+		It("should identify IPv4-mapped clients by their IPv4 address", func() {
+			r.IPAddress = "[::ffff:198.51.100.1]:4711"
+			r.IsRobotsTxt = true
+			s.AppendRequest(ctx, r)
+			Expect(s.HasRequestedRobotsTxt(ctx, "198.51.100.1", r.UserAgent)).To(BeTrue())
+		})
+	})
+
 	Context("GetRequestsByTimeRange", func() {
 		It("should return one request", func() {
 			s.AppendRequest(ctx, r)
@@ -333,6 +362,17 @@ var _ = Describe("Functions", func() {
 			r.IPAddress = "127.0.0.1"
 			Expect(s.GetTotalRequestsByIPAddress(ctx, r.IPAddress)).To(Equal(3))
 		})
+
+		It("should count the requests of IPv6 clients by their address", func() {
+			r.IPAddress = "[2001:db8::1]:4711"
+			s.AppendRequest(ctx, r)
+			r.IPAddress = "[2001:db8::2]:4711"
+			s.AppendRequest(ctx, r)
+			r.IPAddress = "[2001:db8::1]:4712"
+			s.AppendRequest(ctx, r)
+			Expect(s.GetTotalRequestsByIPAddress(ctx, "2001:db8::1")).To(Equal(2))
+			Expect(s.GetTotalRequestsByIPAddress(ctx, "[2001:db8::2]:4711")).To(Equal(1))
+		})
 	})
 
 	Context("GetTotalRequests", func() {
@@ -0,0 +1,82 @@
+package statistics
+
+import (
+	"context"
+	"time"
+)
+
+// ModelStatistics is the structure for the generation statistics of a single model.
+type ModelStatistics struct {
+	Backend      string
+	Generations  int
+	Failures     int
+	TotalLatency time.Duration
+}
+
+// AverageLatency returns the average latency of all generations of the model.
+func (m ModelStatistics) AverageLatency() time.Duration {
+	if m.Generations+m.Failures == 0 {
+		return 0
+	}
+
+	return (m.TotalLatency / time.Duration(m.Generations+m.Failures)).Round(time.Millisecond)
+}
+
+// SuccessRate returns the percentage of successful generations of the model.
+func (m ModelStatistics) SuccessRate() float64 {
+	if m.Generations+m.Failures == 0 {
+		return 0
+	}
+
+	return float64(m.Generations) / float64(m.Generations+m.Failures) * 100
+}
+
+// RecordModelGeneration records a generation of the given model. An empty model is recorded as the backend name,
+// for backends that do not have models.
+func (s *Statistics) RecordModelGeneration(ctx context.Context, backend, model string, latency time.Duration,
+	success bool,
+) {
+	_, span := tracer.Start(ctx, "Statistics.RecordModelGeneration")
+	defer span.End()
+
+	if model == "" {
+		model = backend
+	}
+	s.ModelsLock.Lock()
+	defer s.ModelsLock.Unlock()
+	if s.Models == nil {
+		s.Models = map[string]*ModelStatistics{}
+	}
+	stats, ok := s.Models[model]
+	if !ok {
+		stats = &ModelStatistics{Backend: backend}
+		s.Models[model] = stats
+	}
+	stats.TotalLatency += latency
+	result := "success"
+	if success {
+		stats.Generations++
+	} else {
+		stats.Failures++
+		result = "failure"
+	}
+
+	// Update Prometheus metrics
+	ModelGenerationsTotal.WithLabelValues(backend, model, result).Inc()
+	ModelGenerationDuration.WithLabelValues(backend, model).Observe(latency.Seconds())
+}
+
+// GetModelStatistics returns a copy of the generation statistics of all models.
+func (s *Statistics) GetModelStatistics(ctx context.Context) map[string]ModelStatistics {
+	_, span := tracer.Start(ctx, "Statistics.GetModelStatistics")
+	defer span.End()
+
+	s.ModelsLock.Lock()
+	defer s.ModelsLock.Unlock()
+	models := make(map[string]ModelStatistics, len(s.Models))
+	for name, stats := range s.Models {
+		models[name] = *stats
+	}
+
+	return models
+}
@@ -0,0 +1,42 @@
+package statistics_test
+
+import (
+	"context"
+	"time"
+
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Models", func() {
+	var ctx context.Context
+	var s *statistics.Statistics
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s = &statistics.Statistics{}
+	})
+
+	It("should record generations per model", func() {
+		s.RecordModelGeneration(ctx, "ollama", "qwen2:0.5b", time.Second, true)
+		s.RecordModelGeneration(ctx, "ollama", "qwen2:0.5b", 3*time.Second, false)
+		s.RecordModelGeneration(ctx, "ollama", "tinyllama", time.Second, true)
+		models := s.GetModelStatistics(ctx)
+		Expect(models).To(HaveLen(2))
+		Expect(models["qwen2:0.5b"].Generations).To(Equal(1))
+		Expect(models["qwen2:0.5b"].Failures).To(Equal(1))
+		Expect(models["qwen2:0.5b"].AverageLatency()).To(Equal(2 * time.Second))
+		Expect(models["qwen2:0.5b"].SuccessRate()).To(Equal(50.0))
+	})
+
+	It("should record backends without models under the backend name", func() {
+		s.RecordModelGeneration(ctx, "markov", "", time.Millisecond, true)
+		Expect(s.GetModelStatistics(ctx)).To(HaveKey("markov"))
+	})
+
+	It("should not fail without generations", func() {
+		Expect(s.GetModelStatistics(ctx)).To(BeEmpty())
+		Expect(statistics.ModelStatistics{}.AverageLatency()).To(BeZero())
+	})
+})
@@ -22,6 +22,110 @@ var (
 		Help: "The total number of prompts generated.",
 	})
 
+	// BackendTokensTotal is the total number of tokens reported by the generation backend.
+	BackendTokensTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konterfai_backend_tokens_total",
+		Help: "The total number of tokens reported by the generation backend.",
+	}, []string{"backend", "type"})
+
+	// BackendUp is 1 if the generation backend is reachable.
+	BackendUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "konterfai_backend_up",
+		Help: "Whether the generation backend is reachable (1) or not (0).",
+	}, []string{"backend"})
+
+	// BackendModelPresent is 1 if the model is present in the generation backend.
+	BackendModelPresent = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "konterfai_backend_model_present",
+		Help: "Whether the model is present in the generation backend (1) or not (0).",
+	}, []string{"backend"})
+
+	// BackendCircuitState is the state of the circuit breaker of the generation backend.
+	BackendCircuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "konterfai_backend_circuit_state",
+		Help: "The state of the circuit breaker of the generation backend (0 = closed, 1 = half-open, 2 = open).",
+	}, []string{"backend"})
+
+	// HallucinationsRejectedTotal is the total number of generated hallucinations rejected by the validators.
+	HallucinationsRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konterfai_hallucinations_rejected_total",
+		Help: "The total number of generated hallucinations rejected by the validators per reason.",
+	}, []string{"backend", "reason"})
+
+	// HallucinationDiversity is the diversity score of the hallucinations cache.
+	HallucinationDiversity = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "konterfai_hallucination_diversity",
+		Help: "The diversity of the hallucinations cache, 1 minus the average similarity of all pairs (0 to 1).",
+	})
+
+	// HallucinationsRemixedTotal is the total number of pages served with a remixed hallucination.
+	HallucinationsRemixedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "konterfai_hallucinations_remixed_total",
+		Help: "The total number of pages served with a remixed hallucination instead of the cached text.",
+	})
+
+	// TarpitConnections is the number of connections that are drip-fed by the tarpit.
+	TarpitConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "konterfai_tarpit_connections",
+		Help: "The number of connections that are currently drip-fed by the tarpit.",
+	})
+
+	// ProxiedRequestsTotal is the total number of requests proxied to the upstream application.
+	ProxiedRequestsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "konterfai_proxied_requests_total",
+		Help: "The total number of requests that are not from AI crawlers and proxied to the upstream application.",
+	})
+
+	// ForwardAuthDecisionsTotal is the total number of decisions of the forward-auth endpoint per decision.
+	ForwardAuthDecisionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konterfai_forward_auth_decisions_total",
+		Help: "The total number of decisions of the forward-auth endpoint per decision (pass or poison).",
+	}, []string{"decision"})
+
+	// ClassifierSignalsTotal is the total number of signals of AI crawlers found by the classifier per signal.
+	ClassifierSignalsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konterfai_classifier_signals_total",
+		Help: "The total number of signals of AI crawlers found in the requests by the classifier per signal.",
+	}, []string{"signal"})
+
+	// PolicyMatchesTotal is the total number of requests that matched a rule of the policy per rule and action.
+	PolicyMatchesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konterfai_policy_matches_total",
+		Help: "The total number of requests that matched a rule of the policy per rule and action.",
+	}, []string{"rule", "action"})
+
+	// ModelGenerationsTotal is the total number of generations per model and result.
+	ModelGenerationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konterfai_model_generations_total",
+		Help: "The total number of generations per model and result (success or failure).",
+	}, []string{"backend", "model", "result"})
+
+	// ModelGenerationDuration is the latency of the generations per model.
+	ModelGenerationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "konterfai_model_generation_duration_seconds",
+		Help:    "The latency of the generations per model in seconds.",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+	}, []string{"backend", "model"})
+
+	// EndpointUp is 1 if the endpoint of the generation backend is healthy.
+	EndpointUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "konterfai_endpoint_up",
+		Help: "Whether the endpoint of the generation backend is healthy (1) or ejected (0).",
+	}, []string{"backend", "endpoint"})
+
+	// EndpointOutstandingRequests is the number of requests in flight per endpoint.
+	EndpointOutstandingRequests = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "konterfai_endpoint_outstanding_requests",
+		Help: "The number of requests in flight per endpoint of the generation backend.",
+	}, []string{"backend", "endpoint"})
+
+	// EndpointGenerationDuration is the latency of the successful generations per endpoint.
+	EndpointGenerationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "konterfai_endpoint_generation_duration_seconds",
+		Help:    "The latency of the successful generations per endpoint of the generation backend in seconds.",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+	}, []string{"backend", "endpoint"})
+
 	// DataFedTotal is the total amount of data fed.
 	DataFedTotal = promauto.NewCounter(prometheus.CounterOpts{
 		Name: "konterfai_data_fed_bytes_total",
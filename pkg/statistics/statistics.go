@@ -12,14 +12,39 @@ import (
 // Statistics is the structure for the Statistics.
 type Statistics struct {
 	Requests          []Request
+	robotsTxtClients  map[robotsTxtClient]struct{}
+	requestCounts     map[string]int
 	StatisticsLock    sync.Mutex
 	ConfigurationInfo string
 	Prompts           map[string]int
 	PromptsLock       sync.Mutex
 	PromptsCount      int
+	BackendStatus     BackendStatus
+	BackendLock       sync.Mutex
+	Models            map[string]*ModelStatistics
+	ModelsLock        sync.Mutex
+	Diversity         Diversity
+	DiversityLock     sync.Mutex
 	Logger            *slog.Logger
 }
 
+// robotsTxtClient identifies a client that requested the robots.txt, which is generated for its user agent.
+type robotsTxtClient struct {
+	ipAddress string
+	userAgent string
+}
+
+// BackendStatus is the structure for the state of the generation backend.
+type BackendStatus struct {
+	Name         string
+	Reachable    bool
+	ModelPresent bool
+	CircuitState string
+	LastError    string
+	LastErrorAt  time.Time
+	UpdatedAt    time.Time
+}
+
 // Request is the structure for the Request.
 type Request struct {
 	UserAgent   string    `yaml:"userAgent"`
@@ -27,6 +52,8 @@ type Request struct {
 	Timestamp   time.Time `yaml:"timestamp"`
 	IsRobotsTxt bool      `yaml:"isRobotsTxt"`
 	Size        int       `yaml:"size"`
+	Score       float64   `yaml:"score"`
+	Rule        string    `yaml:"rule"`
 }
 
 var tracer = otel.Tracer("codeberg.org/konterfai/konterfai/pkg/statistics")
@@ -91,6 +91,11 @@ func (ss *StatisticsServer) handleRoot(w http.ResponseWriter, r *http.Request) {
 
 	totalRequests := len(ss.Statistics.Requests)
 
+	backendStatus := ss.Statistics.GetBackendStatus(ctx)
+
+	models := ss.Statistics.GetModelStatistics(ctx)
+	diversity := ss.Statistics.GetDiversity(ctx)
+
 	ss.Statistics.PromptsLock.Lock()
 	defer ss.Statistics.PromptsLock.Unlock()
 
@@ -102,6 +107,9 @@ func (ss *StatisticsServer) handleRoot(w http.ResponseWriter, r *http.Request) {
 		TotalDataSize     string
 		TotalRequests     int
 		TotalPrompts      int
+		Backend           statistics.BackendStatus
+		Models            map[string]statistics.ModelStatistics
+		Diversity         statistics.Diversity
 	}{
 		ConfigurationInfo: ss.Statistics.ConfigurationInfo,
 		Prompts:           ss.Statistics.Prompts,
@@ -110,6 +118,9 @@ func (ss *StatisticsServer) handleRoot(w http.ResponseWriter, r *http.Request) {
 		TotalDataSize:     totalDataSize,
 		TotalRequests:     totalRequests,
 		TotalPrompts:      ss.Statistics.PromptsCount,
+		Backend:           backendStatus,
+		Models:            models,
+		Diversity:         diversity,
 	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
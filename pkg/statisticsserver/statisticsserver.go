@@ -3,6 +3,7 @@ package statisticsserver
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"strconv"
 	"time"
 
+	"codeberg.org/konterfai/konterfai/pkg/helpers/functions"
 	"codeberg.org/konterfai/konterfai/pkg/statistics"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/otel"
@@ -78,7 +80,11 @@ func (ss *StatisticsServer) Serve(ctx context.Context) error {
 		Handler:           serverMux,
 		ReadHeaderTimeout: 5 * time.Second,
 	}
-	if err := server.ListenAndServe(); err != nil {
+	go func() {
+		<-ctx.Done()
+		functions.ShutdownServer(ctx, ss.Logger, server, functions.ShutdownTimeout)
+	}()
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
 
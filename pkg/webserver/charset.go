@@ -0,0 +1,113 @@
+package webserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// defaultCharset is the charset of pages that declare no charset or a charset that can not be encoded.
+const defaultCharset = "utf-8"
+
+// metaCharsetRegexp matches the charset a page declares in its meta tag.
+var metaCharsetRegexp = regexp.MustCompile(`<meta charset="([^"]+)"`)
+
+// declaredCharset returns the charset the page declares, the default charset if it declares none.
+func declaredCharset(ctx context.Context, page string) string {
+	_, span := tracer.Start(ctx, "WebServer.declaredCharset")
+	defer span.End()
+
+	match := metaCharsetRegexp.FindStringSubmatch(page)
+	if match == nil {
+		return defaultCharset
+	}
+
+	return match[1]
+}
+
+// charsetEncoder returns the encoder of the charset and the charset it encodes, characters the charset can not
+// represent are written as html character references. The encoder is nil for UTF-8 and for unknown charsets,
+// which are sent as UTF-8.
+func charsetEncoder(ctx context.Context, charset string) (*encoding.Encoder, string) {
+	_, span := tracer.Start(ctx, "WebServer.charsetEncoder")
+	defer span.End()
+
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, defaultCharset
+	}
+	if enc == unicode.UTF8 {
+		return nil, charset
+	}
+
+	return encoding.HTMLEscapeUnsupported(enc.NewEncoder()), charset
+}
+
+// contentType returns the Content-Type header of a html page in the charset.
+func contentType(charset string) string {
+	return "text/html; charset=" + charset
+}
+
+// encodePage encodes the page in the charset it declares, it returns the encoded page and its charset.
+func (ws *WebServer) encodePage(ctx context.Context, page string) ([]byte, string) {
+	ctx, span := tracer.Start(ctx, "WebServer.encodePage")
+	defer span.End()
+
+	encoder, charset := charsetEncoder(ctx, declaredCharset(ctx, page))
+	if encoder == nil {
+		return []byte(page), charset
+	}
+	encoded, err := encoder.String(page)
+	if err != nil {
+		ws.Logger.ErrorContext(ctx, fmt.Sprintf("could not encode page in %s (%v)", charset, err))
+
+		return []byte(page), defaultCharset
+	}
+
+	return []byte(encoded), charset
+}
+
+// encodingWriter encodes everything written to it in a charset, it must be closed to flush stateful charsets.
+type encodingWriter struct {
+	io.Writer
+	close func() error
+}
+
+// Close flushes the remaining encoded output.
+func (w *encodingWriter) Close() error {
+	return w.close()
+}
+
+// newEncodingWriter returns a writer that encodes in the charset before writing to w, and the charset it encodes.
+func newEncodingWriter(ctx context.Context, w io.Writer, charset string) (*encodingWriter, string) {
+	ctx, span := tracer.Start(ctx, "WebServer.newEncodingWriter")
+	defer span.End()
+
+	encoder, charset := charsetEncoder(ctx, charset)
+	if encoder == nil {
+		return &encodingWriter{Writer: w, close: func() error { return nil }}, charset
+	}
+	writer := transform.NewWriter(w, encoder)
+
+	return &encodingWriter{Writer: writer, close: writer.Close}, charset
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	io.Writer
+	n int
+}
+
+// Write writes p to the underlying writer and counts the written bytes.
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.n += n
+
+	return n, err
+}
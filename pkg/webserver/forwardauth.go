@@ -0,0 +1,146 @@
+package webserver
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"codeberg.org/konterfai/konterfai/pkg/classifier"
+	"codeberg.org/konterfai/konterfai/pkg/policy"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// ForwardAuthDecisionHeader is the header in which the forward-auth decision endpoint names its decision.
+	ForwardAuthDecisionHeader = "X-Konterfai-Decision"
+
+	decisionPass   = "pass"
+	decisionPoison = "poison"
+)
+
+// isForwardAuthRequest returns true if the request is for the forward-auth decision endpoint.
+func (ws *WebServer) isForwardAuthRequest(r *http.Request) bool {
+	return ws.ForwardAuthPath != "" && r.URL.Path == ws.ForwardAuthPath
+}
+
+// handleForwardAuth decides for a reverse proxy whether the original request should be poisoned.
+// The original request is described by its headers, X-Forwarded-For and X-Forwarded-Uri, as sent by traefik
+// ForwardAuth and nginx auth_request, and is classified and evaluated by the policy like any other request. A matched
+// rule overrides the classifier, only pass rules let the request pass. Requests that pass are answered with 200 OK,
+// poisoned requests with 403 Forbidden and a hallucination, which traefik delivers to the client while nginx can
+// route to konterfAI.
+func (ws *WebServer) handleForwardAuth(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "WebServer.handleForwardAuth")
+	defer span.End()
+
+	client := forwardedClient(r)
+	uri := r.Header.Get("X-Forwarded-Uri")
+	requestPath, _, _ := strings.Cut(uri, "?")
+	request := classifier.Request{Client: client, Path: requestPath, Header: r.Header}
+	classification := ws.Classifier.Classify(ctx, request)
+	rule := ws.Policy.Evaluate(ctx, policy.Request{Request: request, Score: classification.Score})
+	ctx = policy.NewContext(classifier.NewContext(ctx, classification), rule)
+	crawler := classification.Crawler
+	if rule != nil {
+		crawler = rule.Action != policy.ActionPass
+	}
+	decision := decisionPass
+	if crawler {
+		decision = decisionPoison
+	}
+	span.SetAttributes(
+		attribute.String("http.user-agent", r.UserAgent()),
+		attribute.String("http.forwarded-for", client),
+		attribute.String("http.forwarded-uri", uri),
+		attribute.Float64("konterfai.score", classification.Score),
+		attribute.String("konterfai.decision", decision),
+	)
+	statistics.ForwardAuthDecisionsTotal.WithLabelValues(decision).Inc()
+	w.Header().Set(ForwardAuthDecisionHeader, decision)
+	if decision == decisionPass {
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+	ws.Logger.DebugContext(ctx, fmt.Sprintf("poisoning %s for %s with a score of %.2f (%s)",
+		uri, client, classification.Score, strings.Join(classification.Signals, ", ")))
+	poisoned := r.Clone(ctx)
+	poisoned.RemoteAddr = client
+	ws.handleHallucination(&forcedStatusWriter{ResponseWriter: w, status: http.StatusForbidden}, poisoned)
+}
+
+// forwardedClient returns the original client of the request, the first address of X-Forwarded-For or X-Real-IP.
+// Without these headers, it returns the remote address of the request.
+func forwardedClient(r *http.Request) string {
+	forwardedFor, _, _ := strings.Cut(r.Header.Get("X-Forwarded-For"), ",")
+	if client := strings.TrimSpace(forwardedFor); client != "" {
+		return client
+	}
+	if client := strings.TrimSpace(r.Header.Get("X-Real-IP")); client != "" {
+		return client
+	}
+
+	return r.RemoteAddr
+}
+
+// withTrustedClient returns the request with the original client as remote address if the request is sent by one of
+// the trusted proxies, e.g. the hallucinations nginx routes to konterfAI after the forward-auth decision.
+// The headers of all other requests are not trusted, clients could set them to any address.
+func (ws *WebServer) withTrustedClient(r *http.Request) *http.Request {
+	if len(ws.TrustedProxies) == 0 {
+		return r
+	}
+	host, port, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return r
+	}
+	addr = addr.Unmap()
+	if !slices.ContainsFunc(ws.TrustedProxies, func(network netip.Prefix) bool {
+		return network.Contains(addr)
+	}) {
+		return r
+	}
+	client, err := netip.ParseAddr(forwardedClient(r))
+	if err != nil {
+		return r
+	}
+	trusted := r.Clone(r.Context())
+	trusted.RemoteAddr = client.Unmap().String()
+	if port != "" {
+		trusted.RemoteAddr = net.JoinHostPort(trusted.RemoteAddr, port)
+	}
+
+	return trusted
+}
+
+// forcedStatusWriter is a http.ResponseWriter that replies with a fixed status code, whatever the handler writes.
+// It does not implement http.Flusher, so the page is written at once instead of being streamed or tarpitted.
+type forcedStatusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader writes the fixed status code once.
+func (w *forcedStatusWriter) WriteHeader(_ int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// Write writes the fixed status code before the first data.
+func (w *forcedStatusWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(w.status)
+
+	return w.ResponseWriter.Write(p)
+}
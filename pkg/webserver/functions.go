@@ -3,11 +3,14 @@ package webserver
 import (
 	"context"
 	"fmt"
+	"io"
 	"math/rand"
 	"net/http"
 	"net/url"
 	"time"
 
+	"codeberg.org/konterfai/konterfai/pkg/classifier"
+	"codeberg.org/konterfai/konterfai/pkg/policy"
 	"codeberg.org/konterfai/konterfai/pkg/statistics"
 	"go.opentelemetry.io/otel/attribute"
 )
@@ -26,6 +29,8 @@ func getRandomHTTPResonseCode(ctx context.Context, okProbability float64) int {
 }
 
 // handleHallucination handles the hallucination request.
+// With a tarpit, the page is drip-fed to the client if the limits of the tarpit allow it, unless the request matched a
+// rule of the policy with another action than tarpit.
 func (ws *WebServer) handleHallucination(w http.ResponseWriter, r *http.Request) {
 	ctx, span := tracer.Start(r.Context(), "WebServer.handleHallucination")
 	defer span.End()
@@ -37,7 +42,15 @@ func (ws *WebServer) handleHallucination(w http.ResponseWriter, r *http.Request)
 	)
 	r = r.WithContext(ctx)
 
-	hallucination := ws.Hallucinator.PopRandomHallucination(ctx)
+	if ws.StreamOnEmptyCache && ws.Hallucinator.GetHallucinationCount(ctx) < 1 {
+		if flusher, ok := w.(http.Flusher); ok && ws.acquireStream(ctx) {
+			defer ws.releaseStream(ctx)
+			ws.streamHallucination(w, r, flusher)
+
+			return
+		}
+	}
+	hallucination, charset := ws.encodePage(ctx, ws.Hallucinator.PopRandomHallucination(ctx))
 	go func() {
 		ws.Statistics.AppendRequest(ctx, statistics.Request{
 			IPAddress:   r.RemoteAddr,
@@ -45,14 +58,92 @@ func (ws *WebServer) handleHallucination(w http.ResponseWriter, r *http.Request)
 			UserAgent:   r.Header.Get("User-Agent"),
 			IsRobotsTxt: false,
 			Size:        len(hallucination),
+			Score:       classifier.FromContext(ctx).Score,
+			Rule:        policy.RuleName(ctx),
 		})
 	}()
-	_, err := w.Write([]byte(hallucination))
+	w.Header().Set("Content-Type", contentType(charset))
+	if flusher, ok := w.(http.Flusher); ok && ws.Tarpit != nil && isTarpitted(ctx) {
+		if client := clientAddress(r.RemoteAddr); ws.Tarpit.acquire(ctx, client) {
+			defer ws.Tarpit.release(ctx, client)
+			if err := ws.Tarpit.drip(ctx, w, flusher, hallucination); err != nil {
+				ws.Logger.DebugContext(ctx, fmt.Sprintf("tarpitted hallucination was not completed (%v)", err.Error()))
+			}
+
+			return
+		}
+	}
+	_, err := w.Write(hallucination)
 	if err != nil {
 		ws.Logger.ErrorContext(ctx, fmt.Sprintf("error writing hallucination (%v)", err.Error()))
 	}
 }
 
+// streamHallucination streams a freshly generated hallucination to the client.
+// The first chunk is the head of the page, the stream is encoded in the charset it declares.
+func (ws *WebServer) streamHallucination(w http.ResponseWriter, r *http.Request, flusher http.Flusher) {
+	ctx, span := tracer.Start(r.Context(), "WebServer.streamHallucination")
+	defer span.End()
+
+	counter := &countingWriter{Writer: w}
+	var encoder *encodingWriter
+	err := ws.Hallucinator.StreamHallucination(ctx, func(chunk string) error {
+		if encoder == nil {
+			var charset string
+			encoder, charset = newEncodingWriter(ctx, counter, declaredCharset(ctx, chunk))
+			w.Header().Set("Content-Type", contentType(charset))
+		}
+		if _, err := io.WriteString(encoder, chunk); err != nil {
+			return err
+		}
+		flusher.Flush()
+
+		return nil
+	})
+	if encoder != nil {
+		if closeErr := encoder.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		ws.Logger.ErrorContext(ctx, fmt.Sprintf("error streaming hallucination (%v)", err.Error()))
+	}
+	ws.Statistics.AppendRequest(ctx, statistics.Request{
+		IPAddress:   r.RemoteAddr,
+		Timestamp:   time.Now(),
+		UserAgent:   r.Header.Get("User-Agent"),
+		IsRobotsTxt: false,
+		Size:        counter.n,
+		Score:       classifier.FromContext(ctx).Score,
+		Rule:        policy.RuleName(ctx),
+	})
+}
+
+// acquireStream reserves one of the concurrent streams, it returns false if all streams are in use.
+func (ws *WebServer) acquireStream(ctx context.Context) bool {
+	_, span := tracer.Start(ctx, "WebServer.acquireStream")
+	defer span.End()
+
+	ws.activeStreamsLock.Lock()
+	defer ws.activeStreamsLock.Unlock()
+	if ws.activeStreams >= ws.StreamMaxConcurrent {
+		return false
+	}
+	ws.activeStreams++
+
+	return true
+}
+
+// releaseStream releases a stream reserved by acquireStream.
+func (ws *WebServer) releaseStream(ctx context.Context) {
+	_, span := tracer.Start(ctx, "WebServer.releaseStream")
+	defer span.End()
+
+	ws.activeStreamsLock.Lock()
+	defer ws.activeStreamsLock.Unlock()
+	ws.activeStreams--
+}
+
 // getErrorFromCache returns the error code from the cache.
 func (ws *WebServer) getErrorFromCache(ctx context.Context, requestURL *url.URL) int {
 	_, span := tracer.Start(ctx, "WebServer.getErrorFromCache")
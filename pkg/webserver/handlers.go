@@ -5,9 +5,11 @@ import (
 	"net/http"
 	"time"
 
+	"codeberg.org/konterfai/konterfai/pkg/classifier"
 	"codeberg.org/konterfai/konterfai/pkg/helpers/functions"
 	"codeberg.org/konterfai/konterfai/pkg/helpers/links"
 	"codeberg.org/konterfai/konterfai/pkg/helpers/robots"
+	"codeberg.org/konterfai/konterfai/pkg/policy"
 	"codeberg.org/konterfai/konterfai/pkg/statistics"
 	"go.opentelemetry.io/otel/attribute"
 )
@@ -33,6 +35,8 @@ func (ws *WebServer) handleRobotsTxt(w http.ResponseWriter, r *http.Request) {
 			UserAgent:   r.Header.Get("User-Agent"),
 			IsRobotsTxt: true,
 			Size:        len(responseData),
+			Score:       classifier.FromContext(ctx).Score,
+			Rule:        policy.RuleName(ctx),
 		})
 	}()
 	_, err := w.Write(responseData)
@@ -49,6 +53,11 @@ func (ws *WebServer) handleRoot(w http.ResponseWriter, r *http.Request) { //noli
 		attribute.String("http.user-agent", r.UserAgent()), attribute.String("http.remote-addr", r.RemoteAddr))
 	r = r.WithContext(ctx)
 
+	if rule := policy.FromContext(ctx); rule != nil && rule.Action != policy.ActionPoison {
+		ws.handleRule(w, r, rule)
+
+		return
+	}
 	httpCode := ws.getErrorFromCache(ctx, r.URL)
 	if httpCode < 1 {
 		if r.URL.Path == "/" || r.URL.Path == ws.HTTPBaseURL.Path || r.URL.Path == "" {
@@ -0,0 +1,64 @@
+package webserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"codeberg.org/konterfai/konterfai/pkg/classifier"
+	"codeberg.org/konterfai/konterfai/pkg/policy"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// handleRule handles a request that matched a rule of the policy with an action other than poison.
+func (ws *WebServer) handleRule(w http.ResponseWriter, r *http.Request, rule *policy.Rule) {
+	ctx, span := tracer.Start(r.Context(), "WebServer.handleRule")
+	defer span.End()
+	span.SetAttributes(attribute.String("konterfai.rule", rule.Name), attribute.String("konterfai.action", rule.Action))
+	r = r.WithContext(ctx)
+
+	switch rule.Action {
+	case policy.ActionTarpit:
+		ws.handleHallucination(w, r)
+	case policy.ActionRedirect:
+		http.Redirect(w, r, rule.Location, rule.Status)
+		ws.appendRuleRequest(ctx, r, 0)
+	case policy.ActionBlock:
+		body := []byte(http.StatusText(rule.Status))
+		w.WriteHeader(rule.Status)
+		if _, err := w.Write(body); err != nil {
+			ws.Logger.ErrorContext(ctx, fmt.Sprintf("error writing blocked response (%v)", err.Error()))
+		}
+		ws.appendRuleRequest(ctx, r, len(body))
+	default:
+		// Without an upstream, there is nothing to pass the request to.
+		http.NotFound(w, r)
+	}
+}
+
+// appendRuleRequest records a request that was answered by a rule in the statistics, so it shows up with the name of
+// the rule and counts towards the minRequests of the rules.
+func (ws *WebServer) appendRuleRequest(ctx context.Context, r *http.Request, size int) {
+	ctx, span := tracer.Start(ctx, "WebServer.appendRuleRequest")
+	defer span.End()
+
+	ws.Statistics.AppendRequest(ctx, statistics.Request{
+		IPAddress:   r.RemoteAddr,
+		Timestamp:   time.Now(),
+		UserAgent:   r.Header.Get("User-Agent"),
+		IsRobotsTxt: false,
+		Size:        size,
+		Score:       classifier.FromContext(ctx).Score,
+		Rule:        policy.RuleName(ctx),
+	})
+}
+
+// isTarpitted returns true if the hallucination of the request may be drip-fed by the tarpit, which is the case for
+// requests that matched no rule of the policy or a tarpit rule.
+func isTarpitted(ctx context.Context) bool {
+	rule := policy.FromContext(ctx)
+
+	return rule == nil || rule.Action == policy.ActionTarpit
+}
@@ -0,0 +1,75 @@
+package webserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"codeberg.org/konterfai/konterfai/pkg/classifier"
+	"codeberg.org/konterfai/konterfai/pkg/policy"
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Proxy forwards the requests that are not classified as AI crawlers to the upstream application.
+// The requests are classified by the classifier of the web server.
+type Proxy struct {
+	Upstream     *url.URL
+	reverseProxy *httputil.ReverseProxy
+}
+
+// NewProxy creates a new Proxy to the upstream url.
+func NewProxy(upstream string) (*Proxy, error) {
+	upstreamURL, err := url.Parse(upstream)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy upstream %q (%w)", upstream, err)
+	}
+	if upstreamURL.Scheme == "" || upstreamURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy upstream %q (%w)", upstream, errors.New("scheme and host are required"))
+	}
+
+	return &Proxy{
+		Upstream: upstreamURL,
+		reverseProxy: &httputil.ReverseProxy{
+			Rewrite: func(pr *httputil.ProxyRequest) {
+				pr.SetURL(upstreamURL)
+				pr.SetXForwarded()
+			},
+		},
+	}, nil
+}
+
+// handleProxyError logs the errors of the upstream application and replies with a bad gateway.
+func (ws *WebServer) handleProxyError(w http.ResponseWriter, r *http.Request, err error) {
+	ctx, span := tracer.Start(r.Context(), "WebServer.handleProxyError")
+	defer span.End()
+
+	ws.Logger.ErrorContext(ctx, fmt.Sprintf("could not proxy request to %s (%v)", ws.Proxy.Upstream.Redacted(), err))
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// handleProxy serves the requests classified as AI crawlers with the handler of konterfAI and proxies all other
+// requests to the upstream application. A matched rule of the policy overrides the classifier, only requests that
+// match a pass rule are proxied. The forward-auth decision endpoint is never proxied.
+func (ws *WebServer) handleProxy(handler http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), "WebServer.handleProxy")
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		isBot := classifier.FromContext(ctx).Crawler
+		if rule := policy.FromContext(ctx); rule != nil {
+			isBot = rule.Action != policy.ActionPass
+		}
+		span.SetAttributes(attribute.Bool("konterfai.is-bot", isBot))
+		if isBot || ws.isForwardAuthRequest(r) {
+			handler.ServeHTTP(w, r)
+
+			return
+		}
+		statistics.ProxiedRequestsTotal.Inc()
+		ws.Proxy.reverseProxy.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,124 @@
+package webserver
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"codeberg.org/konterfai/konterfai/pkg/statistics"
+)
+
+// Tarpit drip-feeds pages in small chunks to tie up the workers of crawlers for a long time.
+// The number of tarpitted connections is limited globally and per client, further requests get the page at once.
+type Tarpit struct {
+	// BytesPerSecond is the average rate the pages are written with.
+	BytesPerSecond int
+	// ChunkSize is the number of bytes written and flushed at once.
+	ChunkSize int
+	// Jitter is the share (0 to 1) by which the delay between two chunks varies randomly.
+	Jitter float64
+	// MaxConnections is the maximum number of tarpitted connections.
+	MaxConnections int
+	// MaxConnectionsPerClient is the maximum number of tarpitted connections of one client ip address.
+	MaxConnectionsPerClient int
+	active                  int
+	activePerClient         map[string]int
+	lock                    sync.Mutex
+}
+
+// NewTarpit creates a new Tarpit.
+func NewTarpit(bytesPerSecond, chunkSize int, jitter float64, maxConnections, maxConnectionsPerClient int) *Tarpit {
+	return &Tarpit{
+		BytesPerSecond:          bytesPerSecond,
+		ChunkSize:               max(chunkSize, 1),
+		Jitter:                  min(max(jitter, 0), 1),
+		MaxConnections:          maxConnections,
+		MaxConnectionsPerClient: maxConnectionsPerClient,
+		activePerClient:         map[string]int{},
+	}
+}
+
+// acquire reserves a tarpitted connection for the client, it returns false if a limit is reached.
+func (t *Tarpit) acquire(ctx context.Context, client string) bool {
+	_, span := tracer.Start(ctx, "Tarpit.acquire")
+	defer span.End()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.active >= t.MaxConnections || t.activePerClient[client] >= t.MaxConnectionsPerClient {
+		return false
+	}
+	t.active++
+	t.activePerClient[client]++
+	statistics.TarpitConnections.Set(float64(t.active))
+
+	return true
+}
+
+// release releases a connection reserved by acquire.
+func (t *Tarpit) release(ctx context.Context, client string) {
+	_, span := tracer.Start(ctx, "Tarpit.release")
+	defer span.End()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.active--
+	if t.activePerClient[client]--; t.activePerClient[client] <= 0 {
+		delete(t.activePerClient, client)
+	}
+	statistics.TarpitConnections.Set(float64(t.active))
+}
+
+// drip writes the page in chunks and flushes every chunk, it waits between the chunks to match the rate.
+// It stops with the error of the context if the client disconnects.
+func (t *Tarpit) drip(ctx context.Context, w io.Writer, flusher http.Flusher, page []byte) error {
+	ctx, span := tracer.Start(ctx, "Tarpit.drip")
+	defer span.End()
+
+	for len(page) > 0 {
+		chunk := page[:min(t.ChunkSize, len(page))]
+		page = page[len(chunk):]
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+		flusher.Flush()
+		if len(page) == 0 {
+			break
+		}
+		timer := time.NewTimer(t.delay(len(chunk)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return nil
+}
+
+// delay returns the time to wait after a chunk of n bytes, varied randomly by the jitter.
+func (t *Tarpit) delay(n int) time.Duration {
+	if t.BytesPerSecond < 1 {
+		return 0
+	}
+	delay := float64(n) / float64(t.BytesPerSecond) * float64(time.Second)
+	delay *= 1 + t.Jitter*(2*rand.Float64()-1) //nolint: gosec
+
+	return time.Duration(delay)
+}
+
+// clientAddress returns the ip address of the remote address of a request, without the port.
+func clientAddress(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+
+	return host
+}
@@ -2,14 +2,19 @@ package webserver
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"strconv"
 	"sync"
 	"time"
 
+	"codeberg.org/konterfai/konterfai/pkg/classifier"
 	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/helpers/functions"
+	"codeberg.org/konterfai/konterfai/pkg/policy"
 	"codeberg.org/konterfai/konterfai/pkg/statistics"
 	"go.opentelemetry.io/otel"
 )
@@ -27,6 +32,16 @@ type WebServer struct {
 	HTTPResponseCacheLock sync.Mutex
 	HTTPBaseURL           url.URL
 	ServeMux              *http.ServeMux
+	StreamOnEmptyCache    bool
+	StreamMaxConcurrent   int
+	activeStreams         int
+	activeStreamsLock     sync.Mutex
+	Tarpit                *Tarpit
+	Proxy                 *Proxy
+	Classifier            *classifier.Classifier
+	Policy                *policy.Policy
+	ForwardAuthPath       string
+	TrustedProxies        []netip.Prefix
 	Logger                *slog.Logger
 }
 
@@ -65,17 +80,63 @@ func (ws *WebServer) Serve(ctx context.Context) error {
 	_, span := tracer.Start(ctx, "WebServer.Serve")
 	defer span.End()
 
-	serverMux := http.NewServeMux()
-	serverMux.HandleFunc("/robots.txt", ws.handleRobotsTxt)
-	serverMux.HandleFunc("/", ws.handleRoot)
 	server := &http.Server{
 		Addr:              ws.Host + ":" + strconv.Itoa(ws.Port),
-		Handler:           serverMux,
+		Handler:           ws.Handler(),
 		ReadHeaderTimeout: 5 * time.Second,
 	}
-	if err := server.ListenAndServe(); err != nil {
+	go func() {
+		<-ctx.Done()
+		functions.ShutdownServer(ctx, ws.Logger, server, functions.ShutdownTimeout)
+	}()
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
 
 	return nil
 }
+
+// Handler returns the http.Handler of the web server.
+// With a proxy, only the requests of AI crawlers are served, all other requests are proxied to the upstream.
+// With a forward-auth path, the decision endpoint for reverse proxies is served on it.
+// Every other request is classified and evaluated by the policy before it is handled.
+func (ws *WebServer) Handler() http.Handler {
+	if ws.ServeMux == nil {
+		ws.ServeMux = http.NewServeMux()
+		ws.ServeMux.HandleFunc("/robots.txt", ws.handleRobotsTxt)
+		if ws.ForwardAuthPath != "" {
+			ws.ServeMux.HandleFunc(ws.ForwardAuthPath, ws.handleForwardAuth)
+		}
+		ws.ServeMux.HandleFunc("/", ws.handleRoot)
+	}
+	var handler http.Handler = ws.ServeMux
+	if ws.Proxy != nil {
+		ws.Proxy.reverseProxy.ErrorHandler = ws.handleProxyError
+		handler = ws.handleProxy(handler)
+	}
+
+	return ws.handleClassification(handler)
+}
+
+// handleClassification classifies the request and evaluates the policy for it, the classification and the matched
+// rule are passed in its context to the handler. Requests for the forward-auth decision endpoint classify the request
+// they describe instead. The policy is not evaluated for the robots.txt.
+// Requests of trusted proxies are handled as requests of the client they are forwarded for.
+func (ws *WebServer) handleClassification(handler http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), "WebServer.handleClassification")
+		defer span.End()
+
+		r = ws.withTrustedClient(r)
+		if !ws.isForwardAuthRequest(r) {
+			request := classifier.NewRequest(r)
+			classification := ws.Classifier.Classify(ctx, request)
+			ctx = classifier.NewContext(ctx, classification)
+			if r.URL.Path != "/robots.txt" {
+				ctx = policy.NewContext(ctx, ws.Policy.Evaluate(ctx,
+					policy.Request{Request: request, Score: classification.Score}))
+			}
+		}
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
@@ -4,18 +4,28 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"mime"
 	"net/http"
+	"net/http/httptest"
+	"net/netip"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"codeberg.org/konterfai/konterfai/pkg/classifier"
 	"codeberg.org/konterfai/konterfai/pkg/command"
 	"codeberg.org/konterfai/konterfai/pkg/hallucinator"
+	"codeberg.org/konterfai/konterfai/pkg/mutator"
+	"codeberg.org/konterfai/konterfai/pkg/policy"
 	"codeberg.org/konterfai/konterfai/pkg/statistics"
 	"codeberg.org/konterfai/konterfai/pkg/webserver"
 	"github.com/oklog/run"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"golang.org/x/text/encoding/htmlindex"
 )
 
 func TestWebserver(t *testing.T) {
@@ -23,6 +33,32 @@ func TestWebserver(t *testing.T) {
 	RunSpecs(t, "Webserver Suite")
 }
 
+// streamingBackend is a StreamingBackend that delivers a fixed list of tokens.
+type streamingBackend struct {
+	tokens []string
+}
+
+// Name returns the name of the streaming backend.
+func (b *streamingBackend) Name() string {
+	return "streaming"
+}
+
+// Generate returns all tokens at once.
+func (b *streamingBackend) Generate(_ context.Context, _ hallucinator.HTTPClient, _ hallucinator.GenerationRequest) (string, error) {
+	return strings.Join(b.tokens, ""), nil
+}
+
+// GenerateStream delivers the tokens one by one.
+func (b *streamingBackend) GenerateStream(_ context.Context, _ hallucinator.HTTPClient, _ hallucinator.GenerationRequest,
+	onToken func(token string),
+) (string, error) {
+	for _, token := range b.tokens {
+		onToken(token)
+	}
+
+	return strings.Join(b.tokens, ""), nil
+}
+
 var _ = Describe("Webserver", func() {
 	var (
 		ctx                            context.Context
@@ -56,8 +92,7 @@ var _ = Describe("Webserver", func() {
 				Scheme: "http",
 				Host:   "localhost:8080",
 			},
-			"http://localhost:11434",
-			"dummy",
+			hallucinator.NewOllamaBackend("http://localhost:11434", "dummy"),
 			10,
 			10,
 			10,
@@ -181,4 +216,449 @@ var _ = Describe("Webserver", func() {
 			ctx.Done()
 		})
 	})
+
+	Context("Streaming", func() {
+		var (
+			ws     *webserver.WebServer
+			server *httptest.Server
+		)
+		BeforeEach(func() {
+			logger, _ = command.SetLogger("off", "")
+			st = statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+			hal = hallucinator.NewHallucinator(ctx, logger, 5, 1, 10, 10, 10, 10, 0, 10, 10, 10, baseUrl,
+				&streamingBackend{tokens: []string{"This is ", "a <streamed> ", "hallucination."}}, 10, 10, 10, st)
+			ws = webserver.NewWebServer(ctx, logger, host, port, hal, st, baseUrl, 1, 0, errorCacheSize)
+			ws.StreamOnEmptyCache = true
+			ws.StreamMaxConcurrent = 1
+			server = httptest.NewServer(ws.Handler())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should stream a hallucination if the cache is empty and append it to the cache", func() {
+			resp, err := http.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			bodyData, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(bodyData)).To(ContainSubstring("This is a &lt;streamed&gt; hallucination."))
+			Expect(string(bodyData)).To(ContainSubstring(hallucinator.ContinueString))
+			Expect(hal.GetHallucinationCount(ctx)).To(Equal(1))
+		})
+
+		It("should mutate the streamed sentences", func() {
+			hal = hallucinator.NewHallucinator(ctx, logger, 5, 1, 10, 10, 10, 10, 0, 10, 10, 10, baseUrl,
+				&streamingBackend{tokens: []string{"In 19", "99 Par", "is was ", "founded. It ", "grew."}},
+				10, 10, 10, st)
+			hal.Mutator = mutator.NewMutator(1, 10, map[string]string{"Paris": "Berlin"})
+			ws = webserver.NewWebServer(ctx, logger, host, port, hal, st, baseUrl, 1, 0, errorCacheSize)
+			ws.StreamOnEmptyCache = true
+			ws.StreamMaxConcurrent = 1
+			server.Config.Handler = ws.Handler()
+			resp, err := http.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			bodyData, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(bodyData)).To(ContainSubstring("In 2009 Berlin was founded. It grew."))
+			Expect(string(bodyData)).NotTo(ContainSubstring("Paris"))
+		})
+
+		It("should render a streamed hallucination the same way when it is served from the cache", func() {
+			pages := []string{}
+			for range 2 {
+				resp, err := http.Get(server.URL)
+				Expect(err).NotTo(HaveOccurred())
+				bodyData, err := io.ReadAll(resp.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Body.Close()).To(Succeed())
+				pages = append(pages, string(bodyData))
+			}
+			Expect(hal.GetHallucinationCount(ctx)).To(Equal(1))
+			Expect(pages).To(HaveEach(ContainSubstring("This is a &lt;streamed&gt; hallucination.")))
+			Expect(pages).To(HaveEach(Not(ContainSubstring("<streamed>"))))
+		})
+
+		It("should serve the cache if it is not empty", func() {
+			hal.AppendHallucination(ctx, hallucinator.Hallucination{Text: "cached hallucination", RequestCount: 1})
+			resp, err := http.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			bodyData, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(bodyData)).To(ContainSubstring("cached hallucination"))
+			Expect(string(bodyData)).NotTo(ContainSubstring("streamed"))
+		})
+	})
+
+	Context("Tarpit", func() {
+		var (
+			ws     *webserver.WebServer
+			server *httptest.Server
+		)
+		BeforeEach(func() {
+			logger, _ = command.SetLogger("off", "")
+			st = statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+			hal = hallucinator.NewHallucinator(ctx, logger, 5, 1, 10, 10, 10, 10, 0, 10, 10, 10, baseUrl,
+				&streamingBackend{}, 10, 10, 10, st)
+			hal.AppendHallucination(ctx, hallucinator.Hallucination{Text: "tarpitted hallucination", RequestCount: 100})
+			ws = webserver.NewWebServer(ctx, logger, host, port, hal, st, baseUrl, 1, 0, errorCacheSize)
+			server = httptest.NewServer(ws.Handler())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should drip-feed the page with the configured rate", func() {
+			ws.Tarpit = webserver.NewTarpit(20000, 512, 0, 10, 10)
+			start := time.Now()
+			resp, err := http.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			bodyData, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(bodyData)).To(ContainSubstring("tarpitted hallucination"))
+			expected := time.Duration(float64(len(bodyData)-512) / 20000 * float64(time.Second))
+			Expect(time.Since(start)).To(BeNumerically(">=", expected*9/10))
+		})
+
+		It("should serve the page at once if the limit of the client is reached", func() {
+			ws.Tarpit = webserver.NewTarpit(10, 16, 0, 10, 1)
+			tarpitted, err := http.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			defer tarpitted.Body.Close()
+			start := time.Now()
+			resp, err := http.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			bodyData, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(bodyData)).To(ContainSubstring("tarpitted hallucination"))
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		})
+	})
+
+	Context("Proxy", func() {
+		var (
+			ws       *webserver.WebServer
+			server   *httptest.Server
+			upstream *httptest.Server
+		)
+		BeforeEach(func() {
+			logger, _ = command.SetLogger("off", "")
+			st = statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+			hal = hallucinator.NewHallucinator(ctx, logger, 5, 1, 10, 10, 10, 10, 0, 10, 10, 10, baseUrl,
+				&streamingBackend{}, 10, 10, 10, st)
+			hal.AppendHallucination(ctx, hallucinator.Hallucination{Text: "hallucination for bots", RequestCount: 100})
+			upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.WriteString(w, "upstream "+r.URL.RequestURI()+" "+r.Header.Get("X-Forwarded-For"))
+			}))
+			bots, err := classifier.NewBotMatcher(classifier.DefaultBotUserAgents)
+			Expect(err).NotTo(HaveOccurred())
+			ws = webserver.NewWebServer(ctx, logger, host, port, hal, st, baseUrl, 1, 0, errorCacheSize)
+			ws.Classifier = classifier.NewClassifier(bots, nil, nil, classifier.DefaultThreshold, 0, st)
+			ws.Proxy, err = webserver.NewProxy(upstream.URL)
+			Expect(err).NotTo(HaveOccurred())
+			ws.ForwardAuthPath = "/_konterfai/decision"
+			server = httptest.NewServer(ws.Handler())
+		})
+
+		AfterEach(func() {
+			server.Close()
+			upstream.Close()
+		})
+
+		get := func(path, userAgent string) string {
+			req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("User-Agent", userAgent)
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			bodyData, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			return string(bodyData)
+		}
+
+		It("should proxy requests of humans to the upstream", func() {
+			Expect(get("/shop?item=1", "Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0")).
+				To(Equal("upstream /shop?item=1 127.0.0.1"))
+			Expect(get("/robots.txt", "Mozilla/5.0")).To(HavePrefix("upstream /robots.txt"))
+		})
+
+		It("should serve requests of AI crawlers", func() {
+			Expect(get("/", "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; gptbot/1.1)")).
+				To(ContainSubstring("hallucination for bots"))
+			Expect(get("/robots.txt", "CCBot/2.0")).To(ContainSubstring("User-Agent: GPTBot"))
+		})
+
+		It("should proxy requests of humans that exceed the rate limit", func() {
+			bots, err := classifier.NewBotMatcher(classifier.DefaultBotUserAgents)
+			Expect(err).NotTo(HaveOccurred())
+			ws.Classifier = classifier.NewClassifier(bots, nil, nil, classifier.DefaultThreshold, 5, st)
+			for range 20 {
+				Expect(get("/assets/app.js", "Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0")).
+					To(HavePrefix("upstream /assets/app.js"))
+			}
+		})
+
+		It("should not proxy the forward-auth decision endpoint", func() {
+			Expect(get("/_konterfai/decision", "Mozilla/5.0")).NotTo(HavePrefix("upstream"))
+		})
+
+		It("should reply with a bad gateway if the upstream is unreachable", func() {
+			upstream.Close()
+			resp, err := http.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadGateway))
+		})
+
+		It("should reject invalid upstreams", func() {
+			_, err := webserver.NewProxy("localhost:3000")
+			Expect(err).To(MatchError(ContainSubstring("invalid proxy upstream")))
+		})
+	})
+
+	Context("ForwardAuth", func() {
+		var server *httptest.Server
+		BeforeEach(func() {
+			logger, _ = command.SetLogger("off", "")
+			st = statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+			hal = hallucinator.NewHallucinator(ctx, logger, 5, 1, 10, 10, 10, 10, 0, 10, 10, 10, baseUrl,
+				&streamingBackend{}, 10, 10, 10, st)
+			hal.AppendHallucination(ctx, hallucinator.Hallucination{Text: "hallucination for bots", RequestCount: 100})
+			ws := webserver.NewWebServer(ctx, logger, host, port, hal, st, baseUrl, 1, 0, errorCacheSize)
+			bots, err := classifier.NewBotMatcher(classifier.DefaultBotUserAgents)
+			Expect(err).NotTo(HaveOccurred())
+			ws.Classifier = classifier.NewClassifier(bots, nil, []string{"/wp-admin/*"}, classifier.DefaultThreshold,
+				0, st)
+			ws.ForwardAuthPath = "/_konterfai/decision"
+			server = httptest.NewServer(ws.Handler())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		decide := func(userAgent string, uri ...string) (*http.Response, string) {
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/_konterfai/decision", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("User-Agent", userAgent)
+			req.Header.Set("Accept-Language", "en-GB,en;q=0.5")
+			req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+			req.Header.Set("X-Forwarded-Uri", append(uri, "/blog/article?id=1")[0])
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			bodyData, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			return resp, string(bodyData)
+		}
+
+		It("should let requests of humans pass", func() {
+			resp, body := decide("Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get(webserver.ForwardAuthDecisionHeader)).To(Equal("pass"))
+			Expect(body).To(BeEmpty())
+		})
+
+		It("should poison requests of AI crawlers with a hallucination", func() {
+			resp, body := decide("Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; ClaudeBot/1.0)")
+			Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+			Expect(resp.Header.Get(webserver.ForwardAuthDecisionHeader)).To(Equal("poison"))
+			Expect(body).To(ContainSubstring("hallucination for bots"))
+			Eventually(func() []statistics.Request {
+				return st.GetRequests(ctx)
+			}).Should(ContainElement(And(HaveField("IPAddress", "203.0.113.7"), HaveField("Score", 1.0))))
+		})
+
+		It("should poison clients with a browser user agent that requested a honeypot path", func() {
+			firefox := "Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0"
+			resp, _ := decide(firefox, "/wp-admin/setup.php")
+			Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+			resp, _ = decide(firefox)
+			Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		})
+
+		It("should not be served without a forward-auth path", func() {
+			ws := webserver.NewWebServer(ctx, logger, host, port, hal, st, baseUrl, 1, 0, errorCacheSize)
+			resp := httptest.NewRecorder()
+			ws.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/_konterfai/decision", nil))
+			Expect(resp.Header().Get(webserver.ForwardAuthDecisionHeader)).To(BeEmpty())
+		})
+	})
+
+	Context("TrustedProxies", func() {
+		var ws *webserver.WebServer
+		BeforeEach(func() {
+			logger, _ = command.SetLogger("off", "")
+			st = statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+			hal = hallucinator.NewHallucinator(ctx, logger, 5, 1, 10, 10, 10, 10, 0, 10, 10, 10, baseUrl,
+				&streamingBackend{}, 10, 10, 10, st)
+			hal.AppendHallucination(ctx, hallucinator.Hallucination{Text: "hallucination for bots", RequestCount: 100})
+			ws = webserver.NewWebServer(ctx, logger, host, port, hal, st, baseUrl, 1, 0, errorCacheSize)
+		})
+
+		serve := func() {
+			// httptest.NewRequest is sent from 192.0.2.1:1234.
+			req := httptest.NewRequest(http.MethodGet, "/blog/article", nil)
+			req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+			req.Header.Set("X-Real-IP", "203.0.113.9")
+			ws.Handler().ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		It("should handle the requests of trusted proxies as requests of the forwarded client", func() {
+			ws.TrustedProxies = []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}
+			serve()
+			Eventually(func() []statistics.Request {
+				return st.GetRequests(ctx)
+			}).Should(ContainElement(HaveField("IPAddress", "203.0.113.7")))
+		})
+
+		It("should ignore the forwarded client of other requests", func() {
+			ws.TrustedProxies = []netip.Prefix{netip.MustParsePrefix("198.51.100.0/24")}
+			serve()
+			Eventually(func() []statistics.Request {
+				return st.GetRequests(ctx)
+			}).Should(ContainElement(HaveField("IPAddress", "192.0.2.1")))
+		})
+	})
+
+	Context("Policy", func() {
+		const rules = `rules:
+  - name: block-bytespider
+    match:
+      userAgent: bytespider
+    action: block
+    status: 410
+  - name: redirect-admin
+    match:
+      paths: ["/wp-admin/*"]
+    action: redirect
+    location: https://example.com/
+  - name: pass-feed
+    match:
+      paths: ["/feed"]
+    action: pass
+  - name: poison-crawlers
+    match:
+      minScore: 1
+    action: poison
+`
+		var (
+			ws     *webserver.WebServer
+			server *httptest.Server
+		)
+		BeforeEach(func() {
+			logger, _ = command.SetLogger("off", "")
+			st = statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+			hal = hallucinator.NewHallucinator(ctx, logger, 5, 1, 10, 10, 10, 10, 0, 10, 10, 10, baseUrl,
+				&streamingBackend{}, 10, 10, 10, st)
+			hal.AppendHallucination(ctx, hallucinator.Hallucination{Text: "hallucination for bots", RequestCount: 100})
+			rulesPath := filepath.Join(GinkgoT().TempDir(), "rules.yaml")
+			Expect(os.WriteFile(rulesPath, []byte(rules), 0o600)).To(Succeed())
+			bots, err := classifier.NewBotMatcher(classifier.DefaultBotUserAgents)
+			Expect(err).NotTo(HaveOccurred())
+			ws = webserver.NewWebServer(ctx, logger, host, port, hal, st, baseUrl, 1, 0, errorCacheSize)
+			ws.Classifier = classifier.NewClassifier(bots, nil, nil, classifier.DefaultThreshold, 0, st)
+			ws.Policy, err = policy.NewPolicy(ctx, logger, rulesPath, st)
+			Expect(err).NotTo(HaveOccurred())
+			server = httptest.NewServer(ws.Handler())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		get := func(path, userAgent string) *http.Response {
+			req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("User-Agent", userAgent)
+			resp, err := http.DefaultTransport.RoundTrip(req)
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(resp.Body.Close)
+
+			return resp
+		}
+
+		It("should block, redirect and pass the requests that match", func() {
+			Expect(get("/", "Bytespider").StatusCode).To(Equal(http.StatusGone))
+			resp := get("/wp-admin/setup.php", "Mozilla/5.0")
+			Expect(resp.StatusCode).To(Equal(http.StatusFound))
+			Expect(resp.Header.Get("Location")).To(Equal("https://example.com/"))
+			Expect(get("/feed", "Mozilla/5.0").StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		It("should record the matched rule in the statistics", func() {
+			Expect(get("/", "GPTBot/1.1").StatusCode).To(Equal(http.StatusOK))
+			Eventually(func() []statistics.Request {
+				return st.GetRequests(ctx)
+			}).Should(ContainElement(HaveField("Rule", "poison-crawlers")))
+		})
+
+		It("should record the blocked and redirected requests in the statistics", func() {
+			Expect(get("/", "Bytespider").StatusCode).To(Equal(http.StatusGone))
+			Expect(get("/wp-admin/setup.php", "Mozilla/5.0").StatusCode).To(Equal(http.StatusFound))
+			Expect(st.GetRequests(ctx)).To(ConsistOf(
+				And(HaveField("Rule", "block-bytespider"), HaveField("UserAgent", "Bytespider")),
+				And(HaveField("Rule", "redirect-admin"), HaveField("IPAddress", "127.0.0.1")),
+			))
+		})
+
+		It("should proxy only the requests that match a pass rule", func() {
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.WriteString(w, "upstream")
+			}))
+			defer upstream.Close()
+			var err error
+			ws.ServeMux = nil
+			ws.Proxy, err = webserver.NewProxy(upstream.URL)
+			Expect(err).NotTo(HaveOccurred())
+			server.Config.Handler = ws.Handler()
+			resp := get("/feed", "GPTBot/1.1")
+			bodyData, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(bodyData)).To(Equal("upstream"))
+			Expect(get("/", "Bytespider").StatusCode).To(Equal(http.StatusGone))
+		})
+	})
+
+	Context("Charsets", func() {
+		var server *httptest.Server
+		BeforeEach(func() {
+			logger, _ = command.SetLogger("off", "")
+			st = statistics.NewStatistics(ctx, logger, "this is just a dummy string")
+			hal = hallucinator.NewHallucinator(ctx, logger, 5, 100, 10, 10, 10, 10, 0, 10, 10, 10, baseUrl,
+				&streamingBackend{}, 10, 10, 10, st)
+			for range 20 {
+				hal.AppendHallucination(ctx, hallucinator.Hallucination{
+					Text: "日本語のニュース über", Language: "Japanese", RequestCount: 1,
+				})
+			}
+			server = httptest.NewServer(webserver.NewWebServer(ctx, logger, host, port, hal, st, baseUrl, 1, 0,
+				errorCacheSize).Handler())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should encode the page in the charset it declares", func() {
+			for range 20 {
+				resp, err := http.Get(server.URL)
+				Expect(err).NotTo(HaveOccurred())
+				_, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(params["charset"]).To(BeElementOf("UTF-8", "Shift_JIS", "ISO-2022-JP"))
+				encoding, err := htmlindex.Get(params["charset"])
+				Expect(err).NotTo(HaveOccurred())
+				bodyData, err := io.ReadAll(encoding.NewDecoder().Reader(resp.Body))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Body.Close()).To(Succeed())
+				Expect(string(bodyData)).To(ContainSubstring(`<meta charset="` + params["charset"] + `">`))
+				Expect(string(bodyData)).To(ContainSubstring("日本語のニュース"))
+				Expect(string(bodyData)).To(MatchRegexp(`(über|&#252;ber)`))
+			}
+		})
+	})
 })
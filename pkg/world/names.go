@@ -0,0 +1,56 @@
+package world
+
+// The name parts the fictional entities are built from. They are invented on purpose, so the world does not
+// collide with real people, organisations and places.
+var (
+	firstNames = []string{
+		"Adela", "Bertil", "Casimir", "Dagny", "Elodie", "Fenna", "Gideon", "Halvard", "Ilsa", "Jorin", "Kasimira",
+		"Leander", "Maren", "Nilo", "Odile", "Pim", "Quirin", "Rosalind", "Sven", "Tamsin", "Ulrike", "Vesna",
+		"Wendel", "Xenia", "Ysolde", "Zoran",
+	}
+	lastNames = []string{
+		"Ambrecht", "Brannigan", "Caldwick", "Dorsell", "Efferding", "Falkenrath", "Gorwitz", "Hallbeck",
+		"Iversholt", "Jellicoe", "Kettering", "Lindqvist", "Marchetti", "Northcote", "Oberlin", "Pembrake",
+		"Quenneville", "Rastorf", "Silvermoor", "Trelawney", "Ulvaeus", "Valcourt", "Wexley", "Yarrowby",
+		"Zelinski",
+	}
+	placePrefixes = []string{
+		"Ash", "Bram", "Cold", "Dun", "Elm", "Fair", "Glen", "Hart", "Iron", "Kil", "Lark", "Mar", "Nor", "Oak",
+		"Pell", "Quen", "Raven", "Stor", "Thorn", "Wil",
+	}
+	placeSuffixes = []string{
+		"brook", "by", "dale", "field", "ford", "gate", "haven", "holm", "mouth", "stead", "vik", "wick",
+	}
+	placeTypes = []string{
+		"port town", "mountain village", "industrial city", "university town", "spa town", "river city",
+		"market town", "island municipality",
+	}
+	organisationSuffixes = []string{
+		"Holdings", "Institute", "Cooperative", "Foundation", "Works", "Bank", "Observatory", "Football Club",
+		"Shipping Line", "Research Council",
+	}
+	organisationTypes = map[string]string{
+		"Holdings":         "logistics company",
+		"Institute":        "research institute",
+		"Cooperative":      "farmers' cooperative",
+		"Foundation":       "charitable foundation",
+		"Works":            "steel manufacturer",
+		"Bank":             "regional bank",
+		"Observatory":      "observatory",
+		"Football Club":    "football club",
+		"Shipping Line":    "ferry operator",
+		"Research Council": "public research council",
+	}
+	organisationRoles = []string{
+		"chief executive", "chairwoman", "chairman", "spokesperson", "chief scientist", "head of finance",
+	}
+	placeRoles = []string{"mayor", "harbour master", "chief of police", "city council speaker"}
+	eventNames = []string{
+		"Summit", "Festival", "Trade Fair", "Marathon", "Flood", "Strike", "Conference", "Election", "Regatta",
+		"Bridge Collapse",
+	}
+	months = []string{
+		"January", "February", "March", "April", "May", "June", "July", "August", "September", "October",
+		"November", "December",
+	}
+)
@@ -0,0 +1,329 @@
+package world
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("codeberg.org/konterfai/konterfai/pkg/world")
+
+// These are the kinds of the entities of a world.
+const (
+	KindPerson       = "person"
+	KindOrganisation = "organisation"
+	KindPlace        = "place"
+	KindEvent        = "event"
+)
+
+// DefaultSize is the default number of entities per kind.
+const DefaultSize = 25
+
+// Entity is a fictional person, organisation, place or event.
+// The description states its attributes, it never changes once the world is generated.
+type Entity struct {
+	ID          int    `json:"id"`
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Related are the ids of the entities the description refers to.
+	Related []int `json:"related,omitempty"`
+}
+
+// World is a fictional reality shared by all hallucinations, so articles keep referring to the same entities.
+type World struct {
+	Seed     int64    `json:"seed"`
+	Entities []Entity `json:"entities"`
+}
+
+// Generate generates a world with size entities of every kind, the same seed generates the same world.
+func Generate(seed int64, size int) *World {
+	g := &generator{
+		random: rand.New(rand.NewSource(seed)), //nolint: gosec
+		world:  &World{Seed: seed, Entities: []Entity{}},
+		names:  map[string]bool{},
+	}
+	places := g.generatePlaces(size)
+	organisations := g.generateOrganisations(size, places)
+	people := g.generatePeople(size, places, organisations)
+	g.generateEvents(size, places, organisations, people)
+
+	return g.world
+}
+
+// Load loads a world from a JSON file.
+func Load(path string) (*World, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &World{}
+	if err := json.Unmarshal(content, w); err != nil {
+		return nil, fmt.Errorf("could not parse world %s (%w)", path, err)
+	}
+	for idx, entity := range w.Entities {
+		if entity.ID != idx {
+			return nil, fmt.Errorf("invalid world %s (entity %d has the id %d)", path, idx, entity.ID)
+		}
+	}
+
+	return w, nil
+}
+
+// LoadOrGenerate loads the world from the file. If the file does not exist, a world is generated and saved to it.
+// It returns true if the world has been generated.
+func LoadOrGenerate(ctx context.Context, path string, seed int64, size int) (*World, bool, error) {
+	ctx, span := tracer.Start(ctx, "world.LoadOrGenerate")
+	defer span.End()
+
+	w, err := Load(path)
+	if err == nil {
+		return w, false, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, false, err
+	}
+	w = Generate(seed, size)
+	if err := w.Save(ctx, path); err != nil {
+		return nil, false, err
+	}
+
+	return w, true, nil
+}
+
+// Save writes the world to a JSON file. It is written to a temporary file first and renamed afterwards.
+func (w *World) Save(ctx context.Context, path string) error {
+	_, span := tracer.Start(ctx, "World.Save")
+	defer span.End()
+
+	content, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// Entity returns the entity with the id.
+func (w *World) Entity(id int) (Entity, bool) {
+	if id < 0 || id >= len(w.Entities) {
+		return Entity{}, false
+	}
+
+	return w.Entities[id], true
+}
+
+// Story picks a random event together with the entities it refers to, the event is the first entity.
+// It returns nil if the world has no events.
+func (w *World) Story(ctx context.Context) []Entity {
+	_, span := tracer.Start(ctx, "World.Story")
+	defer span.End()
+
+	events := w.ofKind(KindEvent)
+	if len(events) == 0 {
+		return nil
+	}
+	event := events[rand.Intn(len(events))] //nolint: gosec
+	story := []Entity{event}
+	for _, id := range event.Related {
+		if entity, ok := w.Entity(id); ok {
+			story = append(story, entity)
+		}
+	}
+
+	return story
+}
+
+// Facts returns the descriptions of the entities as one text.
+func Facts(entities []Entity) string {
+	descriptions := make([]string, 0, len(entities))
+	for _, entity := range entities {
+		descriptions = append(descriptions, entity.Description)
+	}
+
+	return strings.Join(descriptions, " ")
+}
+
+// Link returns the path of the page of the entity, it is the same for every article.
+func (e Entity) Link() string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r == ' ' || r == '-':
+			return '-'
+		default:
+			return -1
+		}
+	}, strings.ToLower(e.Name))
+
+	return fmt.Sprintf("/%s/%s", e.Kind, slug)
+}
+
+// Random returns n random entities, entities may repeat.
+func (w *World) Random(ctx context.Context, n int) []Entity {
+	_, span := tracer.Start(ctx, "World.Random")
+	defer span.End()
+
+	entities := make([]Entity, 0, n)
+	for range n {
+		if len(w.Entities) == 0 {
+			break
+		}
+		entities = append(entities, w.Entities[rand.Intn(len(w.Entities))]) //nolint: gosec
+	}
+
+	return entities
+}
+
+// ofKind returns the entities of the kind.
+func (w *World) ofKind(kind string) []Entity {
+	entities := []Entity{}
+	for _, entity := range w.Entities {
+		if entity.Kind == kind {
+			entities = append(entities, entity)
+		}
+	}
+
+	return entities
+}
+
+// generator generates the entities of a world.
+type generator struct {
+	random *rand.Rand
+	world  *World
+	names  map[string]bool
+}
+
+// add adds an entity to the world and returns it with its id.
+func (g *generator) add(entity Entity) Entity {
+	entity.ID = len(g.world.Entities)
+	g.world.Entities = append(g.world.Entities, entity)
+	g.names[entity.Name] = true
+
+	return entity
+}
+
+// uniqueName returns a name built by build that is not used yet, a number is appended if no unique name is found.
+func (g *generator) uniqueName(build func() string) string {
+	name := build()
+	for try := 0; g.names[name]; try++ {
+		name = build()
+		if try > 10 {
+			name = fmt.Sprintf("%s %d", name, try)
+		}
+	}
+
+	return name
+}
+
+// pick returns a random element of the list.
+func pick[T any](g *generator, list []T) T {
+	return list[g.random.Intn(len(list))]
+}
+
+// generatePlaces generates the places of the world.
+func (g *generator) generatePlaces(size int) []Entity {
+	places := make([]Entity, 0, size)
+	for range size {
+		name := g.uniqueName(func() string {
+			return pick(g, placePrefixes) + pick(g, placeSuffixes)
+		})
+		places = append(places, g.add(Entity{
+			Kind: KindPlace,
+			Name: name,
+			Description: fmt.Sprintf("%s is a %s with %d inhabitants.",
+				name, pick(g, placeTypes), 1000+g.random.Intn(400)*500),
+		}))
+	}
+
+	return places
+}
+
+// generateOrganisations generates the organisations of the world, every organisation is based in a place.
+func (g *generator) generateOrganisations(size int, places []Entity) []Entity {
+	organisations := make([]Entity, 0, size)
+	for range size {
+		place := pick(g, places)
+		suffix := pick(g, organisationSuffixes)
+		name := g.uniqueName(func() string {
+			if g.random.Intn(2) == 0 {
+				return place.Name + " " + suffix
+			}
+
+			return pick(g, lastNames) + " " + suffix
+		})
+		organisations = append(organisations, g.add(Entity{
+			Kind: KindOrganisation,
+			Name: name,
+			Description: fmt.Sprintf("%s is a %s based in %s, founded in %d.",
+				name, organisationTypes[suffix], place.Name, 1850+g.random.Intn(170)),
+			Related: []int{place.ID},
+		}))
+	}
+
+	return organisations
+}
+
+// generatePeople generates the people of the world, every person has a role in an organisation or a place.
+func (g *generator) generatePeople(size int, places, organisations []Entity) []Entity {
+	people := make([]Entity, 0, size)
+	for range size {
+		name := g.uniqueName(func() string {
+			return pick(g, firstNames) + " " + pick(g, lastNames)
+		})
+		age := 28 + g.random.Intn(45)
+		var description string
+		var related Entity
+		if g.random.Intn(3) == 0 {
+			related = pick(g, places)
+			description = fmt.Sprintf("%s (%d) is the %s of %s.", name, age, pick(g, placeRoles), related.Name)
+		} else {
+			related = pick(g, organisations)
+			description = fmt.Sprintf("%s (%d) is the %s of %s.", name, age, pick(g, organisationRoles), related.Name)
+		}
+		people = append(people, g.add(Entity{
+			Kind:        KindPerson,
+			Name:        name,
+			Description: description,
+			Related:     []int{related.ID},
+		}))
+	}
+
+	return people
+}
+
+// generateEvents generates the events of the world, every event takes place in a place, is organised by an
+// organisation and involves a person.
+func (g *generator) generateEvents(size int, places, organisations, people []Entity) {
+	for range size {
+		place := pick(g, places)
+		organisation := pick(g, organisations)
+		person := pick(g, people)
+		name := g.uniqueName(func() string {
+			return fmt.Sprintf("%s %s", place.Name, pick(g, eventNames))
+		})
+		g.add(Entity{
+			Kind: KindEvent,
+			Name: name,
+			Description: fmt.Sprintf("The %s took place in %s in %s %d. It was organised by %s, %s spoke at it.",
+				name, place.Name, pick(g, months), 2010+g.random.Intn(15), organisation.Name, person.Name),
+			Related: []int{place.ID, organisation.ID, person.ID},
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package world_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"codeberg.org/konterfai/konterfai/pkg/world"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("World", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("generates the same world from the same seed", func() {
+		Expect(world.Generate(42, 10)).To(Equal(world.Generate(42, 10)))
+		Expect(world.Generate(42, 10)).NotTo(Equal(world.Generate(43, 10)))
+	})
+
+	It("generates size entities of every kind with unique names", func() {
+		w := world.Generate(1, 10)
+		Expect(w.Entities).To(HaveLen(40))
+		names := map[string]bool{}
+		kinds := map[string]int{}
+		for idx, entity := range w.Entities {
+			Expect(entity.ID).To(Equal(idx))
+			Expect(names).NotTo(HaveKey(entity.Name))
+			names[entity.Name] = true
+			kinds[entity.Kind]++
+		}
+		Expect(kinds).To(Equal(map[string]int{
+			world.KindPerson: 10, world.KindOrganisation: 10, world.KindPlace: 10, world.KindEvent: 10,
+		}))
+	})
+
+	It("picks stories of an event and the entities it refers to", func() {
+		w := world.Generate(1, 10)
+		story := w.Story(ctx)
+		Expect(story).To(HaveLen(4))
+		Expect(story[0].Kind).To(Equal(world.KindEvent))
+		for _, entity := range story[1:] {
+			Expect(story[0].Description).To(ContainSubstring(entity.Name))
+		}
+		Expect(world.Facts(story)).To(HavePrefix(story[0].Description + " "))
+	})
+
+	It("returns no story without events", func() {
+		Expect((&world.World{}).Story(ctx)).To(BeNil())
+	})
+
+	It("creates stable links", func() {
+		entity := world.Entity{Kind: world.KindPlace, Name: "Nor-vik Harbour Festival!"}
+		Expect(entity.Link()).To(Equal("/place/nor-vik-harbour-festival"))
+	})
+
+	It("generates the world once and loads it afterwards", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "world", "world.json")
+		generated, ok, err := world.LoadOrGenerate(ctx, path, 1, 5)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		loaded, ok, err := world.LoadOrGenerate(ctx, path, 2, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(loaded).To(Equal(generated))
+	})
+
+	It("rejects worlds with ids that do not match the positions", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "world.json")
+		Expect(os.WriteFile(path, []byte(`{"seed": 1, "entities": [{"id": 3, "name": "Norvik"}]}`), 0o600)).
+			To(Succeed())
+		_, _, err := world.LoadOrGenerate(ctx, path, 1, 5)
+		Expect(err).To(MatchError(ContainSubstring("entity 0 has the id 3")))
+	})
+})